@@ -27,15 +27,21 @@ import (
 	informers "github.com/justinsb/scaler/pkg/client/informers/externalversions"
 	"github.com/justinsb/scaler/pkg/control"
 	"github.com/justinsb/scaler/pkg/control/target"
+	k8sfactors "github.com/justinsb/scaler/pkg/factors/kubernetes"
 	"github.com/justinsb/scaler/pkg/http"
 	"github.com/justinsb/scaler/pkg/signals"
 	"github.com/justinsb/scaler/pkg/version"
+	"github.com/justinsb/scaler/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/dynamic"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 )
 
 func main() {
@@ -83,6 +89,9 @@ func run(config *options.AutoScalerConfig) error {
 		return fmt.Errorf("error building scaling client: %v", err)
 	}
 
+	// Targets are read & patched as JSON with the dynamic client, so that we don't need code for each kind
+	clientPool := dynamic.NewDynamicClientPool(cfg)
+
 	cfg.ContentType = "application/vnd.kubernetes.protobuf"
 	kubeClient, err := kubernetes.NewForConfig(cfg)
 	if err != nil {
@@ -94,21 +103,32 @@ func run(config *options.AutoScalerConfig) error {
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
 	scalerInformerFactory := informers.NewSharedInformerFactory(scalingClient, time.Second*30)
 
-	t, err := target.NewKubernetesTarget(kubeClient)
+	t, err := target.NewKubernetesTarget(kubeClient, clientPool)
 	if err != nil {
 		return err
 	}
 
-	state, err := control.NewState(&clock.RealClock{}, t, config)
+	// The cluster-proportional inputs are computed from the node informer, rather than listing nodes on every poll
+	factors := k8sfactors.NewInformerKubernetesFactors(&clock.RealClock{}, kubeInformerFactory, stopCh)
+
+	state, err := control.NewState(&clock.RealClock{}, t, factors, config)
 	if err != nil {
 		return fmt.Errorf("error initializing: %v", err)
 	}
+	prometheus.MustRegister(state.MetricsCollector())
 
 	controller, err := control.NewController(state, kubeClient, scalingClient, kubeInformerFactory, scalerInformerFactory)
 	if err != nil {
 		return fmt.Errorf("error building controller: %v", err)
 	}
 
+	// Pods in a deployment have unique hostnames, so we use the hostname as our identity in the election
+	identity, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("error getting hostname: %v", err)
+	}
+	state.SetIdentity(identity)
+
 	go kubeInformerFactory.Start(stopCh)
 	go scalerInformerFactory.Start(stopCh)
 
@@ -125,6 +145,61 @@ func run(config *options.AutoScalerConfig) error {
 		}()
 	}
 
+	if config.ListenWebhook != "" {
+		server := webhook.NewServer(config, webhook.NewValidator(t), webhook.NewConverter())
+		go func() {
+			err := server.Start(stopCh)
+			if err != nil {
+				glog.Fatalf("error starting webhook: %v", err)
+			}
+		}()
+	}
+
+	// Every replica observes the cluster, but only the leader applies policies
+	if config.LeaderElect {
+		lock, err := resourcelock.New(resourcelock.ConfigMapsResourceLock,
+			config.LockNamespace,
+			config.LockName,
+			kubeClient.CoreV1(),
+			resourcelock.ResourceLockConfig{
+				Identity:      identity,
+				EventRecorder: controller.Recorder(),
+			})
+		if err != nil {
+			return fmt.Errorf("error building leader election lock: %v", err)
+		}
+
+		electionConfig := leaderelection.LeaderElectionConfig{
+			Lock:          lock,
+			LeaseDuration: config.LeaseDuration,
+			RenewDeadline: config.RenewDeadline,
+			RetryPeriod:   config.RetryPeriod,
+			Callbacks: leaderelection.LeaderCallbacks{
+				OnStartedLeading: controller.RunLeader,
+				OnStoppedLeading: func() {
+					// The stop channel of RunLeader is closed first; we wait for any in-flight patch to complete
+					glog.Warningf("lost leader election lock; no longer applying policies")
+					state.StopLeading()
+				},
+				OnNewLeader: state.OnNewLeader,
+			},
+		}
+		go func() {
+			// When we lose the election we go back to only observing the cluster, and stand for election again
+			for {
+				leaderelection.RunOrDie(electionConfig)
+
+				select {
+				case <-stopCh:
+					return
+				default:
+				}
+			}
+		}()
+	} else {
+		go controller.RunLeader(stopCh)
+	}
+
 	if err = controller.Run(2, stopCh); err != nil {
 		return err
 	}
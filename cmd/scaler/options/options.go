@@ -24,6 +24,7 @@ import (
 
 	"github.com/golang/glog"
 	"github.com/spf13/pflag"
+	"k8s.io/client-go/tools/leaderelection"
 )
 
 // AutoScalerConfig configures and runs an autoscaler server
@@ -38,6 +39,36 @@ type AutoScalerConfig struct {
 	PrintVersion bool
 	DryRun       bool
 	ListenAPI    string
+
+	// MaxPatchesPerHour limits the number of patches to each target in any hour; 0 is unlimited
+	MaxPatchesPerHour int
+	// MaxConcurrentRollouts limits the number of targets we are rolling out at the same time; 0 is unlimited
+	MaxConcurrentRollouts int
+	// MinPatchInterval is the minimum time between patches to the same target
+	MinPatchInterval time.Duration
+	// RolloutTimeout is how long we consider a rollout to be in progress after we patch a target
+	RolloutTimeout time.Duration
+
+	// CheckpointPeriod is how often we persist the evaluator state, so it survives a restart; 0 disables checkpoints
+	CheckpointPeriod time.Duration
+
+	// ListenWebhook is the endpoint on which we serve the validating admission & conversion webhooks (over TLS); empty disables it
+	ListenWebhook string
+	// WebhookCertFile and WebhookKeyFile are the TLS certificate & key for the webhook
+	WebhookCertFile string
+	WebhookKeyFile  string
+
+	// LeaderElect enables leader election, so that only one replica applies policies
+	LeaderElect bool
+	// LeaseDuration is how long followers wait before trying to take over an unrenewed lock
+	LeaseDuration time.Duration
+	// RenewDeadline is how long the leader keeps retrying to renew the lock before it gives up leadership
+	RenewDeadline time.Duration
+	// RetryPeriod is how long we wait between attempts to acquire or renew the lock
+	RetryPeriod time.Duration
+	// LockNamespace and LockName identify the ConfigMap which is used as the lock
+	LockNamespace string
+	LockName      string
 }
 
 // NewAutoScalerConfig returns a Autoscaler config
@@ -49,6 +80,17 @@ func NewAutoScalerConfig() *AutoScalerConfig {
 		UpdatePeriod: time.Second * 10,
 		PrintVersion: false,
 		DryRun:       false,
+
+		RolloutTimeout: time.Minute * 10,
+
+		CheckpointPeriod: time.Minute,
+
+		LeaderElect:   true,
+		LeaseDuration: time.Second * 15,
+		RenewDeadline: time.Second * 10,
+		RetryPeriod:   time.Second * 2,
+		LockNamespace: "kube-system",
+		LockName:      "scaler",
 	}
 }
 
@@ -64,6 +106,20 @@ func (c *AutoScalerConfig) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&c.PrintVersion, "version", c.PrintVersion, "Print the version and exit.")
 	fs.BoolVar(&c.DryRun, "dry-run", c.DryRun, "Calculate updates for a target but does not apply the update.")
 	fs.StringVar(&c.ListenAPI, "listen-api", c.ListenAPI, "endpoint to listen on for informational interface")
+	fs.IntVar(&c.MaxPatchesPerHour, "max-patches-per-hour", c.MaxPatchesPerHour, "The maximum number of patches to each target in any hour (0 is unlimited).")
+	fs.IntVar(&c.MaxConcurrentRollouts, "max-concurrent-rollouts", c.MaxConcurrentRollouts, "The maximum number of targets we roll out at the same time (0 is unlimited).")
+	fs.DurationVar(&c.MinPatchInterval, "min-patch-interval", c.MinPatchInterval, "The minimum time between patches to the same target.")
+	fs.DurationVar(&c.RolloutTimeout, "rollout-timeout", c.RolloutTimeout, "How long we consider a rollout to be in progress after we patch a target.")
+	fs.DurationVar(&c.CheckpointPeriod, "checkpoint-period", c.CheckpointPeriod, "How often to persist the observed values to a ConfigMap for each policy, so that delays survive a restart (0 disables).")
+	fs.StringVar(&c.ListenWebhook, "listen-webhook", c.ListenWebhook, "endpoint to listen on for the validating admission and conversion webhooks (served over TLS)")
+	fs.StringVar(&c.WebhookCertFile, "webhook-tls-cert-file", c.WebhookCertFile, "Path to the TLS certificate for the webhook.")
+	fs.StringVar(&c.WebhookKeyFile, "webhook-tls-key-file", c.WebhookKeyFile, "Path to the TLS private key for the webhook.")
+	fs.BoolVar(&c.LeaderElect, "leader-elect", c.LeaderElect, "Elect a leader, so that only one replica applies policies.  Enable when running more than one replica.")
+	fs.DurationVar(&c.LeaseDuration, "leader-elect-lease-duration", c.LeaseDuration, "How long followers wait after the last renewal before they try to take over leadership.")
+	fs.DurationVar(&c.RenewDeadline, "leader-elect-renew-deadline", c.RenewDeadline, "How long the leader retries renewing the lock before it stops leading.  Must be less than the lease duration.")
+	fs.DurationVar(&c.RetryPeriod, "leader-elect-retry-period", c.RetryPeriod, "How long to wait between attempts to acquire or renew the lock.")
+	fs.StringVar(&c.LockNamespace, "leader-elect-lock-namespace", c.LockNamespace, "The namespace of the ConfigMap used as the leader election lock.")
+	fs.StringVar(&c.LockName, "leader-elect-lock-name", c.LockName, "The name of the ConfigMap used as the leader election lock.")
 }
 
 //// InitFlags no// WordSepNormalizeFunc changes all flags that contain "_" separators
@@ -107,6 +163,48 @@ func (c *AutoScalerConfig) ValidateFlags() error {
 		errorsFound = true
 		glog.Errorf("--update-period cannot be less than 1")
 	}
+	if c.MaxPatchesPerHour < 0 {
+		errorsFound = true
+		glog.Errorf("--max-patches-per-hour cannot be negative")
+	}
+	if c.MaxConcurrentRollouts < 0 {
+		errorsFound = true
+		glog.Errorf("--max-concurrent-rollouts cannot be negative")
+	}
+	if c.MinPatchInterval < 0 {
+		errorsFound = true
+		glog.Errorf("--min-patch-interval cannot be negative")
+	}
+	if c.RolloutTimeout < 0 {
+		errorsFound = true
+		glog.Errorf("--rollout-timeout cannot be negative")
+	}
+	if c.CheckpointPeriod < 0 {
+		errorsFound = true
+		glog.Errorf("--checkpoint-period cannot be negative")
+	}
+	if c.ListenWebhook != "" && (c.WebhookCertFile == "" || c.WebhookKeyFile == "") {
+		errorsFound = true
+		glog.Errorf("--webhook-tls-cert-file and --webhook-tls-key-file are required with --listen-webhook")
+	}
+
+	if c.LeaderElect {
+		if c.LeaseDuration <= c.RenewDeadline {
+			errorsFound = true
+			glog.Errorf("--leader-elect-lease-duration must be greater than --leader-elect-renew-deadline")
+		}
+		if c.RetryPeriod <= 0 {
+			errorsFound = true
+			glog.Errorf("--leader-elect-retry-period must be positive")
+		} else if float64(c.RenewDeadline) <= leaderelection.JitterFactor*float64(c.RetryPeriod) {
+			errorsFound = true
+			glog.Errorf("--leader-elect-renew-deadline must be greater than %v times --leader-elect-retry-period", leaderelection.JitterFactor)
+		}
+		if c.LockNamespace == "" || c.LockName == "" {
+			errorsFound = true
+			glog.Errorf("--leader-elect-lock-namespace and --leader-elect-lock-name are required with --leader-elect")
+		}
+	}
 
 	// Log all sanity check errors before returning a single error string
 	if errorsFound {
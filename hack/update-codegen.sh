@@ -19,7 +19,14 @@ set -o nounset
 set -o pipefail
 
 SCRIPT_ROOT=$(dirname ${BASH_SOURCE})/..
-CODEGEN_PKG=${CODEGEN_PKG:-$(cd ${SCRIPT_ROOT}; ls -d -1 ./vendor/k8s.io/code-generator 2>/dev/null || echo ../code-generator)}
+# dep prunes the vendored code-generator to client-gen, so we fall back to a checkout of the revision in Gopkg.lock
+if [[ -z "${CODEGEN_PKG:-}" ]]; then
+  if [[ -f "${SCRIPT_ROOT}/vendor/k8s.io/code-generator/generate-groups.sh" ]]; then
+    CODEGEN_PKG=./vendor/k8s.io/code-generator
+  else
+    CODEGEN_PKG=../code-generator
+  fi
+fi
 
 # generate the code with:
 # --output-base    because this script should also be able to run inside the vendor dir of
@@ -28,3 +35,16 @@ CODEGEN_PKG=${CODEGEN_PKG:-$(cd ${SCRIPT_ROOT}; ls -d -1 ./vendor/k8s.io/code-ge
 ${CODEGEN_PKG}/generate-groups.sh "deepcopy,client,informer,lister" \
   github.com/justinsb/scaler/pkg/client github.com/justinsb/scaler/pkg/apis \
   scalingpolicy:v1alpha1
+
+# We only use the v1alpha1 clients, so v1beta1 only needs deepcopy
+${CODEGEN_PKG}/generate-groups.sh "deepcopy" \
+  github.com/justinsb/scaler/pkg/client github.com/justinsb/scaler/pkg/apis \
+  scalingpolicy:v1beta1
+
+# v1beta1 is converted to & from v1alpha1 (the storage version) rather than an internal version,
+# so we run conversion-gen & defaulter-gen directly.
+(cd ${CODEGEN_PKG} && go install ./cmd/conversion-gen ./cmd/defaulter-gen)
+${GOPATH}/bin/conversion-gen --input-dirs github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1beta1 \
+  -O zz_generated.conversion --go-header-file ${SCRIPT_ROOT}/hack/boilerplate/boilerplate.go.txt
+${GOPATH}/bin/defaulter-gen --input-dirs github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1beta1 \
+  -O zz_generated.defaults --go-header-file ${SCRIPT_ROOT}/hack/boilerplate/boilerplate.go.txt
@@ -0,0 +1,33 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/api/core/v1"
+)
+
+// ContainerTypes lists the container types, in the order in which the containers appear in a PodSpec
+var ContainerTypes = []ContainerType{ContainerTypeInitContainers, ContainerTypeContainers}
+
+// ContainersIn returns the list of containers in the PodSpec for the container type; an empty type means containers.
+// It returns a pointer to the list, so that callers building a PodSpec can append to it.
+func (t ContainerType) ContainersIn(podSpec *v1.PodSpec) *[]v1.Container {
+	if t == ContainerTypeInitContainers {
+		return &podSpec.InitContainers
+	}
+	return &podSpec.Containers
+}
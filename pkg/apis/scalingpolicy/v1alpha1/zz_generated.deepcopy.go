@@ -23,6 +23,8 @@ package v1alpha1
 import (
 	reflect "reflect"
 
+	core_v1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -41,10 +43,30 @@ func RegisterDeepCopies(scheme *runtime.Scheme) error {
 			in.(*ContainerScalingRule).DeepCopyInto(out.(*ContainerScalingRule))
 			return nil
 		}, InType: reflect.TypeOf(&ContainerScalingRule{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ContainerScalingStatus).DeepCopyInto(out.(*ContainerScalingStatus))
+			return nil
+		}, InType: reflect.TypeOf(&ContainerScalingStatus{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*DelayScaling).DeepCopyInto(out.(*DelayScaling))
 			return nil
 		}, InType: reflect.TypeOf(&DelayScaling{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*InputValue).DeepCopyInto(out.(*InputValue))
+			return nil
+		}, InType: reflect.TypeOf(&InputValue{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*NodeFilter).DeepCopyInto(out.(*NodeFilter))
+			return nil
+		}, InType: reflect.TypeOf(&NodeFilter{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ObjectFilter).DeepCopyInto(out.(*ObjectFilter))
+			return nil
+		}, InType: reflect.TypeOf(&ObjectFilter{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*OutputRounding).DeepCopyInto(out.(*OutputRounding))
+			return nil
+		}, InType: reflect.TypeOf(&OutputRounding{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*ResourceRequirements).DeepCopyInto(out.(*ResourceRequirements))
 			return nil
@@ -65,6 +87,14 @@ func RegisterDeepCopies(scheme *runtime.Scheme) error {
 			in.(*ScalingPolicy).DeepCopyInto(out.(*ScalingPolicy))
 			return nil
 		}, InType: reflect.TypeOf(&ScalingPolicy{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingInput).DeepCopyInto(out.(*ScalingInput))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingInput{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingPolicyCondition).DeepCopyInto(out.(*ScalingPolicyCondition))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingPolicyCondition{})},
 		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*ScalingPolicyList).DeepCopyInto(out.(*ScalingPolicyList))
 			return nil
@@ -77,6 +107,10 @@ func RegisterDeepCopies(scheme *runtime.Scheme) error {
 			in.(*ScalingPolicyStatus).DeepCopyInto(out.(*ScalingPolicyStatus))
 			return nil
 		}, InType: reflect.TypeOf(&ScalingPolicyStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*Smoothing).DeepCopyInto(out.(*Smoothing))
+			return nil
+		}, InType: reflect.TypeOf(&Smoothing{})},
 	)
 }
 
@@ -97,6 +131,25 @@ func (in *ContainerScalingRule) DeepCopy() *ContainerScalingRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerScalingStatus) DeepCopyInto(out *ContainerScalingStatus) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	in.ScaleDownThreshold.DeepCopyInto(&out.ScaleDownThreshold)
+	in.ScaleUpThreshold.DeepCopyInto(&out.ScaleUpThreshold)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerScalingStatus.
+func (in *ContainerScalingStatus) DeepCopy() *ContainerScalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerScalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DelayScaling) DeepCopyInto(out *DelayScaling) {
 	*out = *in
@@ -113,6 +166,97 @@ func (in *DelayScaling) DeepCopy() *DelayScaling {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputValue) DeepCopyInto(out *InputValue) {
+	*out = *in
+	out.Value = in.Value.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputValue.
+func (in *InputValue) DeepCopy() *InputValue {
+	if in == nil {
+		return nil
+	}
+	out := new(InputValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFilter) DeepCopyInto(out *NodeFilter) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFilter.
+func (in *NodeFilter) DeepCopy() *NodeFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectFilter) DeepCopyInto(out *ObjectFilter) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectFilter.
+func (in *ObjectFilter) DeepCopy() *ObjectFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputRounding) DeepCopyInto(out *OutputRounding) {
+	*out = *in
+	out.RoundTo = in.RoundTo.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputRounding.
+func (in *OutputRounding) DeepCopy() *OutputRounding {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputRounding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
 	*out = *in
@@ -153,6 +297,15 @@ func (in *ResourceScalingFunction) DeepCopyInto(out *ResourceScalingFunction) {
 		*out = make([]ResourceScalingSegment, len(*in))
 		copy(*out, *in)
 	}
+	if in.Smoothing != nil {
+		in, out := &in.Smoothing, &out.Smoothing
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Smoothing)
+			**out = **in
+		}
+	}
 	if in.DelayScaleDown != nil {
 		in, out := &in.DelayScaleDown, &out.DelayScaleDown
 		if *in == nil {
@@ -162,6 +315,15 @@ func (in *ResourceScalingFunction) DeepCopyInto(out *ResourceScalingFunction) {
 			**out = **in
 		}
 	}
+	if in.DelayScaleUp != nil {
+		in, out := &in.DelayScaleUp, &out.DelayScaleUp
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DelayScaling)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -180,6 +342,16 @@ func (in *ResourceScalingRule) DeepCopyInto(out *ResourceScalingRule) {
 	*out = *in
 	in.Function.DeepCopyInto(&out.Function)
 	out.Max = in.Max.DeepCopy()
+	out.Min = in.Min.DeepCopy()
+	if in.Rounding != nil {
+		in, out := &in.Rounding, &out.Rounding
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(OutputRounding)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
@@ -209,13 +381,47 @@ func (in *ResourceScalingSegment) DeepCopy() *ResourceScalingSegment {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingInput) DeepCopyInto(out *ScalingInput) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NodeFilter)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ObjectFilter)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingInput.
+func (in *ScalingInput) DeepCopy() *ScalingInput {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalingPolicy) DeepCopyInto(out *ScalingPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -238,6 +444,23 @@ func (in *ScalingPolicy) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicyCondition) DeepCopyInto(out *ScalingPolicyCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicyCondition.
+func (in *ScalingPolicyCondition) DeepCopy() *ScalingPolicyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalingPolicyList) DeepCopyInto(out *ScalingPolicyList) {
 	*out = *in
@@ -276,6 +499,13 @@ func (in *ScalingPolicyList) DeepCopyObject() runtime.Object {
 func (in *ScalingPolicySpec) DeepCopyInto(out *ScalingPolicySpec) {
 	*out = *in
 	out.ScaleTargetRef = in.ScaleTargetRef
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ScalingInput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
 		*out = make([]ContainerScalingRule, len(*in))
@@ -299,6 +529,45 @@ func (in *ScalingPolicySpec) DeepCopy() *ScalingPolicySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScalingPolicyStatus) DeepCopyInto(out *ScalingPolicyStatus) {
 	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]InputValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InputsTime != nil {
+		in, out := &in.InputsTime, &out.InputsTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerScalingStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ScalingPolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -311,3 +580,19 @@ func (in *ScalingPolicyStatus) DeepCopy() *ScalingPolicyStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Smoothing) DeepCopyInto(out *Smoothing) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Smoothing.
+func (in *Smoothing) DeepCopy() *Smoothing {
+	if in == nil {
+		return nil
+	}
+	out := new(Smoothing)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/conversion"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func addConversionFuncs(scheme *runtime.Scheme) error {
+	return scheme.AddConversionFuncs(
+		Convert_v1beta1_ResourceScalingFunction_To_v1alpha1_ResourceScalingFunction,
+		Convert_v1alpha1_ResourceScalingFunction_To_v1beta1_ResourceScalingFunction,
+		Convert_v1beta1_ResourceScalingRule_To_v1alpha1_ResourceScalingRule,
+		Convert_v1alpha1_ResourceScalingRule_To_v1beta1_ResourceScalingRule,
+		Convert_v1beta1_ResourceScalingSegment_To_v1alpha1_ResourceScalingSegment,
+		Convert_v1alpha1_ResourceScalingSegment_To_v1beta1_ResourceScalingSegment,
+	)
+}
+
+// Convert_v1beta1_ResourceScalingFunction_To_v1alpha1_ResourceScalingFunction converts per to an integer
+func Convert_v1beta1_ResourceScalingFunction_To_v1alpha1_ResourceScalingFunction(in *ResourceScalingFunction, out *v1alpha1.ResourceScalingFunction, s conversion.Scope) error {
+	if err := autoConvert_v1beta1_ResourceScalingFunction_To_v1alpha1_ResourceScalingFunction(in, out, s); err != nil {
+		return err
+	}
+
+	per, err := quantityToInt64("per", &in.Per)
+	if err != nil {
+		return err
+	}
+	out.Per = per
+	return nil
+}
+
+// Convert_v1alpha1_ResourceScalingFunction_To_v1beta1_ResourceScalingFunction converts per to a quantity.
+// Smoothing and the delays are converted with the rule, because they are part of the rule in v1beta1.
+func Convert_v1alpha1_ResourceScalingFunction_To_v1beta1_ResourceScalingFunction(in *v1alpha1.ResourceScalingFunction, out *ResourceScalingFunction, s conversion.Scope) error {
+	if err := autoConvert_v1alpha1_ResourceScalingFunction_To_v1beta1_ResourceScalingFunction(in, out, s); err != nil {
+		return err
+	}
+
+	out.Per = int64ToQuantity(in.Per)
+	return nil
+}
+
+// Convert_v1beta1_ResourceScalingRule_To_v1alpha1_ResourceScalingRule moves smoothing and the delays into the function
+func Convert_v1beta1_ResourceScalingRule_To_v1alpha1_ResourceScalingRule(in *ResourceScalingRule, out *v1alpha1.ResourceScalingRule, s conversion.Scope) error {
+	if err := autoConvert_v1beta1_ResourceScalingRule_To_v1alpha1_ResourceScalingRule(in, out, s); err != nil {
+		return err
+	}
+
+	out.Function.Smoothing = (*v1alpha1.Smoothing)(unsafe.Pointer(in.Smoothing))
+	out.Function.DelayScaleDown = (*v1alpha1.DelayScaling)(unsafe.Pointer(in.DelayScaleDown))
+	out.Function.DelayScaleUp = (*v1alpha1.DelayScaling)(unsafe.Pointer(in.DelayScaleUp))
+	return nil
+}
+
+// Convert_v1alpha1_ResourceScalingRule_To_v1beta1_ResourceScalingRule moves smoothing and the delays out of the function
+func Convert_v1alpha1_ResourceScalingRule_To_v1beta1_ResourceScalingRule(in *v1alpha1.ResourceScalingRule, out *ResourceScalingRule, s conversion.Scope) error {
+	if err := autoConvert_v1alpha1_ResourceScalingRule_To_v1beta1_ResourceScalingRule(in, out, s); err != nil {
+		return err
+	}
+
+	out.Smoothing = (*Smoothing)(unsafe.Pointer(in.Function.Smoothing))
+	out.DelayScaleDown = (*DelayScaling)(unsafe.Pointer(in.Function.DelayScaleDown))
+	out.DelayScaleUp = (*DelayScaling)(unsafe.Pointer(in.Function.DelayScaleUp))
+	return nil
+}
+
+// Convert_v1beta1_ResourceScalingSegment_To_v1alpha1_ResourceScalingSegment converts at & every to integers
+func Convert_v1beta1_ResourceScalingSegment_To_v1alpha1_ResourceScalingSegment(in *ResourceScalingSegment, out *v1alpha1.ResourceScalingSegment, s conversion.Scope) error {
+	if err := autoConvert_v1beta1_ResourceScalingSegment_To_v1alpha1_ResourceScalingSegment(in, out, s); err != nil {
+		return err
+	}
+
+	at, err := quantityToInt64("at", &in.At)
+	if err != nil {
+		return err
+	}
+	every, err := quantityToInt64("every", &in.Every)
+	if err != nil {
+		return err
+	}
+	out.At = at
+	out.Every = every
+	return nil
+}
+
+// Convert_v1alpha1_ResourceScalingSegment_To_v1beta1_ResourceScalingSegment converts at & every to quantities
+func Convert_v1alpha1_ResourceScalingSegment_To_v1beta1_ResourceScalingSegment(in *v1alpha1.ResourceScalingSegment, out *ResourceScalingSegment, s conversion.Scope) error {
+	if err := autoConvert_v1alpha1_ResourceScalingSegment_To_v1beta1_ResourceScalingSegment(in, out, s); err != nil {
+		return err
+	}
+
+	out.At = int64ToQuantity(in.At)
+	out.Every = int64ToQuantity(in.Every)
+	return nil
+}
+
+// quantityToInt64 returns the value of the quantity, which must be a whole number because v1alpha1 uses integers
+func quantityToInt64(fieldName string, q *resource.Quantity) (int64, error) {
+	if q.IsZero() {
+		return 0, nil
+	}
+	v, ok := q.AsInt64()
+	if !ok {
+		return 0, fmt.Errorf("%s %s cannot be represented in v1alpha1, which only supports whole numbers", fieldName, q.String())
+	}
+	return v, nil
+}
+
+// int64ToQuantity returns the value as a quantity; we leave zero values unset, as they are in v1alpha1.
+// Multiples of 1024 are almost always memory sizes, so we use binary suffixes for them (64Gi, not 68719476736).
+func int64ToQuantity(v int64) resource.Quantity {
+	if v == 0 {
+		return resource.Quantity{}
+	}
+	format := resource.DecimalSI
+	if v%1024 == 0 {
+		format = resource.BinarySI
+	}
+	return *resource.NewQuantity(v, format)
+}
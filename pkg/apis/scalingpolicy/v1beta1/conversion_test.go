@@ -0,0 +1,270 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func buildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("error building scheme: %v", err)
+	}
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatalf("error building scheme: %v", err)
+	}
+	return scheme
+}
+
+func TestRoundTripFromV1alpha1(t *testing.T) {
+	scheme := buildScheme(t)
+
+	in := &v1alpha1.ScalingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy1", Namespace: "kube-system"},
+	}
+	in.Spec.ScaleTargetRef.Kind = "Deployment"
+	in.Spec.ScaleTargetRef.Name = "deployment1"
+	in.Spec.Inputs = []v1alpha1.ScalingInput{{Name: "workers", Source: "nodes", Nodes: &v1alpha1.NodeFilter{ReadyOnly: true}}}
+	in.Spec.Containers = []v1alpha1.ContainerScalingRule{
+		{
+			Name: "container1",
+			Resources: v1alpha1.ResourceRequirements{
+				Limits: []v1alpha1.ResourceScalingRule{
+					{
+						Resource: "cpu",
+						Function: v1alpha1.ResourceScalingFunction{
+							Input:          "workers",
+							Base:           resource.MustParse("200m"),
+							Slope:          resource.MustParse("1m"),
+							Per:            2,
+							Segments:       []v1alpha1.ResourceScalingSegment{{At: 0, Every: 1}, {At: 10, Every: 5}},
+							Smoothing:      &v1alpha1.Smoothing{Percentile: 90, WindowSeconds: 300},
+							DelayScaleDown: &v1alpha1.DelayScaling{Max: 20, DelaySeconds: 300},
+						},
+						Max:      resource.MustParse("4"),
+						Rounding: &v1alpha1.OutputRounding{RoundTo: resource.MustParse("100m"), Mode: v1alpha1.RoundNearest},
+						Combiner: v1alpha1.CombineMax,
+					},
+				},
+				Requests: []v1alpha1.ResourceScalingRule{
+					{
+						Resource: "cpu",
+						Function: v1alpha1.ResourceScalingFunction{
+							Base:         resource.MustParse("100m"),
+							DelayScaleUp: &v1alpha1.DelayScaling{DelaySeconds: 30},
+						},
+					},
+				},
+			},
+		},
+	}
+	in.Status.ObservedGeneration = 2
+	in.Status.Inputs = []v1alpha1.InputValue{{Name: "workers", Value: resource.MustParse("10")}}
+
+	beta := &ScalingPolicy{}
+	if err := scheme.Convert(in, beta, nil); err != nil {
+		t.Fatalf("error converting to v1beta1: %v", err)
+	}
+
+	rule := &beta.Spec.Containers[0].Resources.Limits[0]
+	if rule.Smoothing == nil || rule.Smoothing.Percentile != 90 || rule.DelayScaleDown == nil || rule.DelayScaleDown.DelaySeconds != 300 {
+		t.Errorf("smoothing & delays were not moved to the rule: %+v", rule)
+	}
+	if rule.Function.Per.String() != "2" || rule.Function.Segments[1].Every.String() != "5" {
+		t.Errorf("unexpected function %+v", rule.Function)
+	}
+	if beta.Spec.Containers[0].Resources.Requests[0].DelayScaleUp == nil {
+		t.Errorf("delayScaleUp was not moved to the rule")
+	}
+
+	out := &v1alpha1.ScalingPolicy{}
+	if err := scheme.Convert(beta, out, nil); err != nil {
+		t.Fatalf("error converting to v1alpha1: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(in, out) {
+		t.Errorf("round trip did not preserve object\n  actual=%+v\nexpected=%+v", out, in)
+	}
+}
+
+// TestStoredV1alpha1RoundTrip checks that a v1alpha1 object, as stored by the apiserver, is re-encoded unchanged,
+// both directly and after a round trip through v1beta1; at, every & per remain numbers in v1alpha1.
+func TestStoredV1alpha1RoundTrip(t *testing.T) {
+	scheme := buildScheme(t)
+
+	stored := `{"kind":"ScalingPolicy","apiVersion":"scalingpolicy.kope.io/v1alpha1",` +
+		`"metadata":{"name":"policy1","namespace":"kube-system","creationTimestamp":null},` +
+		`"spec":{"scaleTargetRef":{"kind":"Deployment","name":"deployment1"},"containers":[{"name":"container1","resources":{` +
+		`"limits":[{"resource":"memory","function":{"input":"memory","base":"100Mi","slope":"3Mi","int":1073741824,` +
+		`"segments":[{"every":1},{"at":68719476736,"every":17179869184}]},"max":"1Gi","min":"100Mi"}],` +
+		`"requests":[{"resource":"cpu","function":{"input":"cores","base":"100m","slope":"10m","int":2,"segments":[{"at":10,"every":5}]},` +
+		`"max":"1","min":"10m"}]}}]},` +
+		`"status":{}}`
+
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(stored), &expected); err != nil {
+		t.Fatalf("error parsing %s: %v", stored, err)
+	}
+
+	alpha := &v1alpha1.ScalingPolicy{}
+	if err := json.Unmarshal([]byte(stored), alpha); err != nil {
+		t.Fatalf("error decoding v1alpha1: %v", err)
+	}
+	if fn := alpha.Spec.Containers[0].Resources.Limits[0].Function; fn.Per != 1<<30 || fn.Segments[1].At != 64<<30 {
+		t.Errorf("unexpected function %+v", fn)
+	}
+
+	beta := &ScalingPolicy{}
+	if err := scheme.Convert(alpha, beta, nil); err != nil {
+		t.Fatalf("error converting to v1beta1: %v", err)
+	}
+	if per := beta.Spec.Containers[0].Resources.Requests[0].Function.Per; per.String() != "2" {
+		t.Errorf("unexpected v1beta1 per %s", per.String())
+	}
+	if fn := beta.Spec.Containers[0].Resources.Limits[0].Function; fn.Per.String() != "1Gi" || fn.Segments[1].At.String() != "64Gi" || fn.Segments[1].Every.String() != "16Gi" {
+		t.Errorf("unexpected v1beta1 function %+v", fn)
+	}
+	roundTripped := &v1alpha1.ScalingPolicy{}
+	if err := scheme.Convert(beta, roundTripped, nil); err != nil {
+		t.Fatalf("error converting to v1alpha1: %v", err)
+	}
+	// The conversion doesn't set the type meta, the apiserver does
+	roundTripped.TypeMeta = alpha.TypeMeta
+
+	for _, obj := range []*v1alpha1.ScalingPolicy{alpha, roundTripped} {
+		b, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("error encoding v1alpha1: %v", err)
+		}
+		var actual map[string]interface{}
+		if err := json.Unmarshal(b, &actual); err != nil {
+			t.Fatalf("error parsing %s: %v", b, err)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("re-encoded object does not match the stored object\n  actual=%s\nexpected=%s", b, stored)
+		}
+	}
+}
+
+func TestConvertToV1alpha1(t *testing.T) {
+	scheme := buildScheme(t)
+
+	grid := []struct {
+		Name     string
+		Function string
+		Error    string
+		Per      int64
+		Segments []v1alpha1.ResourceScalingSegment
+	}{
+		{
+			Name:     "memory segments",
+			Function: `{"input": "memory", "per": "1Gi", "segments": [{"at": "64Gi", "every": "16Gi"}]}`,
+			Per:      1 << 30,
+			Segments: []v1alpha1.ResourceScalingSegment{{At: 64 << 30, Every: 16 << 30}},
+		},
+		{
+			Name:     "plain numbers",
+			Function: `{"input": "nodes", "per": 2, "segments": [{"at": 10, "every": 5}]}`,
+			Per:      2,
+			Segments: []v1alpha1.ResourceScalingSegment{{At: 10, Every: 5}},
+		},
+		{
+			Name:     "fractional every",
+			Function: `{"input": "cores", "segments": [{"at": 0, "every": "500m"}]}`,
+			Error:    "every 500m cannot be represented in v1alpha1",
+		},
+		{
+			Name:     "per above 2Gi",
+			Function: `{"input": "memory", "per": "4Gi"}`,
+			Per:      4 << 30,
+		},
+	}
+
+	for _, g := range grid {
+		beta := &ScalingPolicy{}
+		beta.Spec.Containers = []ContainerScalingRule{{Name: "container1", Resources: ResourceRequirements{Limits: []ResourceScalingRule{{Resource: "memory"}}}}}
+		if err := json.Unmarshal([]byte(g.Function), &beta.Spec.Containers[0].Resources.Limits[0].Function); err != nil {
+			t.Fatalf("error parsing %s: %v", g.Function, err)
+		}
+
+		out := &v1alpha1.ScalingPolicy{}
+		err := scheme.Convert(beta, out, nil)
+		if g.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), g.Error) {
+				t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, err, g.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test failure\nname=%s\nunexpected error %v", g.Name, err)
+			continue
+		}
+
+		fn := &out.Spec.Containers[0].Resources.Limits[0].Function
+		if fn.Per != g.Per || !equality.Semantic.DeepEqual(fn.Segments, g.Segments) {
+			t.Errorf("test failure\nname=%s\n  actual=%d %v\nexpected=%d %v", g.Name, fn.Per, fn.Segments, g.Per, g.Segments)
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	scheme := buildScheme(t)
+
+	policy := &ScalingPolicy{}
+	policy.Spec.Containers = []ContainerScalingRule{
+		{
+			Name: "container1",
+			Resources: ResourceRequirements{
+				Limits: []ResourceScalingRule{
+					{Resource: "memory", Rounding: &OutputRounding{RoundTo: resource.MustParse("32Mi")}},
+				},
+				Requests: []ResourceScalingRule{
+					{Resource: "memory", Function: ResourceScalingFunction{Per: resource.MustParse("1Gi")}},
+					{Resource: "memory", Combiner: CombineMax},
+				},
+			},
+		},
+	}
+	scheme.Default(policy)
+
+	if policy.Spec.RequestLimitPolicy != RaiseLimits {
+		t.Errorf("unexpected default requestLimitPolicy %q", policy.Spec.RequestLimitPolicy)
+	}
+	if policy.Spec.Containers[0].ContainerType != ContainerTypeContainers {
+		t.Errorf("unexpected default containerType %q", policy.Spec.Containers[0].ContainerType)
+	}
+	limit := &policy.Spec.Containers[0].Resources.Limits[0]
+	if limit.Combiner != CombineSum || limit.Rounding.Mode != RoundUp || limit.Function.Per.String() != "1" {
+		t.Errorf("unexpected defaults %+v", limit)
+	}
+	request := &policy.Spec.Containers[0].Resources.Requests[0]
+	if request.Combiner != CombineMax || request.Rounding != nil || request.Function.Per.String() != "1Gi" {
+		t.Errorf("defaults overrode values %+v", request)
+	}
+	if combiner := policy.Spec.Containers[0].Resources.Requests[1].Combiner; combiner != CombineMax {
+		t.Errorf("defaults overrode combiner %q", combiner)
+	}
+}
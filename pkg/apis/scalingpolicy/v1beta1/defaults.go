@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The defaults are the values we already assume when a field is not set, so that
+// objects read through v1beta1 show the behaviour explicitly.
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+func SetDefaults_ScalingPolicySpec(obj *ScalingPolicySpec) {
+	if obj.RequestLimitPolicy == "" {
+		obj.RequestLimitPolicy = RaiseLimits
+	}
+}
+
+func SetDefaults_ContainerScalingRule(obj *ContainerScalingRule) {
+	if obj.ContainerType == "" {
+		obj.ContainerType = ContainerTypeContainers
+	}
+}
+
+// SetDefaults_ResourceRequirements defaults the combiner of each rule to the combiner declared on another
+// rule for the same resource, or to sum, so that the rules for a resource always agree.
+func SetDefaults_ResourceRequirements(obj *ResourceRequirements) {
+	defaultCombiners(obj.Limits)
+	defaultCombiners(obj.Requests)
+}
+
+func defaultCombiners(rules []ResourceScalingRule) {
+	declared := make(map[string]ResourceCombiner)
+	for i := range rules {
+		if rules[i].Combiner != "" && declared[string(rules[i].Resource)] == "" {
+			declared[string(rules[i].Resource)] = rules[i].Combiner
+		}
+	}
+	for i := range rules {
+		if rules[i].Combiner != "" {
+			continue
+		}
+		rules[i].Combiner = declared[string(rules[i].Resource)]
+		if rules[i].Combiner == "" {
+			rules[i].Combiner = CombineSum
+		}
+	}
+}
+
+func SetDefaults_ResourceScalingFunction(obj *ResourceScalingFunction) {
+	if obj.Per.IsZero() {
+		obj.Per = *resource.NewQuantity(1, resource.DecimalSI)
+	}
+}
+
+func SetDefaults_OutputRounding(obj *OutputRounding) {
+	if obj.Mode == "" {
+		obj.Mode = RoundUp
+	}
+}
@@ -0,0 +1,24 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// +k8s:deepcopy-gen=package,register
+// +k8s:conversion-gen=github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1
+// +k8s:defaulter-gen=TypeMeta
+
+// Package v1beta1 is the v1beta1 version of the API.
+// It is converted to and from v1alpha1, which remains the version stored and used by the controller.
+// +groupName=scalingpolicy.kope.io
+package v1beta1
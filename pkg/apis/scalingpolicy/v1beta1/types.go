@@ -0,0 +1,375 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	autoscaling "k8s.io/api/autoscaling/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScalingPolicy is a specification for an ScalingPolicy resource
+type ScalingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec   ScalingPolicySpec   `json:"spec"`
+	Status ScalingPolicyStatus `json:"status,omitempty"`
+}
+
+// ScalingPolicySpec is the spec for an ScalingPolicy resource
+type ScalingPolicySpec struct {
+	// This is what HPA uses but I don’t love it
+
+	// reference to scaled resource; horizontal pod autoscaler will learn the current resource consumption
+	// and will set the desired number of pods by using its Scale subresource.
+	// Bare pods are not supported, because the resources of a pod cannot be changed; target the controller of the pod.
+	ScaleTargetRef autoscaling.CrossVersionObjectReference `json:"scaleTargetRef"`
+
+	// PodSpecPath is the path to the PodSpec in the target, used for kinds that are not built in (such as CRDs).
+	// It is a dotted path and defaults to spec.template.spec; the apiVersion must be set in scaleTargetRef.
+	PodSpecPath string `json:"podSpecPath,omitempty"`
+
+	// Inputs defines named inputs, which are computed over a subset of the cluster.
+	// Rules can refer to these inputs by name, in the same way as the built-in inputs.
+	Inputs []ScalingInput `json:"inputs,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	Containers []ContainerScalingRule `json:"containers" patchStrategy:"merge"`
+
+	// RequestLimitPolicy determines what we do when a computed request would exceed the limit for the resource,
+	// which the apiserver would reject: raiseLimits (the default) or clampRequests.
+	RequestLimitPolicy RequestLimitPolicy `json:"requestLimitPolicy,omitempty"`
+}
+
+// RequestLimitPolicy specifies how we keep requests within limits
+type RequestLimitPolicy string
+
+const (
+	// RaiseLimits raises the limit to the request, so that the request is always what we computed
+	RaiseLimits RequestLimitPolicy = "raiseLimits"
+	// ClampRequests lowers the request to the limit, so that the limit is never exceeded
+	ClampRequests RequestLimitPolicy = "clampRequests"
+)
+
+// ScalingInput defines a named input, computed from one of the built-in inputs over a subset of the cluster
+type ScalingInput struct {
+	// Name is the name by which rules refer to the input
+	Name string `json:"name"`
+
+	// Source is the built-in input we compute: cores, memory, nodes, pods, containers, services, endpoints or namespaces
+	Source string `json:"source"`
+
+	// Nodes restricts the nodes which are counted, for the node inputs (cores, memory or nodes)
+	Nodes *NodeFilter `json:"nodes,omitempty"`
+
+	// Objects restricts the objects which are counted, for the object inputs (pods, containers, services, endpoints or namespaces)
+	Objects *ObjectFilter `json:"objects,omitempty"`
+}
+
+// NodeFilter selects the nodes which are counted for an input
+type NodeFilter struct {
+	// Selector is a label query over the nodes; if not set all nodes are selected
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Tolerations are the taints we tolerate.  A node with NoSchedule or NoExecute taints
+	// is only counted if all those taints are tolerated, matching where pods would be scheduled.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+
+	// ReadyOnly counts only the nodes which are Ready
+	ReadyOnly bool `json:"readyOnly,omitempty"`
+}
+
+// ObjectFilter selects the objects which are counted for an input
+type ObjectFilter struct {
+	// Selector is a label query over the objects; if not set all objects are selected
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// FieldSelector is a field query over the objects, for example status.phase=Running for pods.
+	// metadata.name and metadata.namespace are supported for all kinds, along with spec.nodeName and
+	// status.phase for pods, spec.type for services, and status.phase for namespaces.
+	FieldSelector string `json:"fieldSelector,omitempty"`
+}
+
+type DelayScaling struct {
+	// Max is the input value skew we tolerate in the output value
+	Max float64 `json:"max,omitempty"`
+
+	// DelaySeconds is the delay before we scale (down or up)
+	DelaySeconds int32 `json:"delaySeconds,omitempty"`
+}
+
+// ContainerScalingRule defines how container resources are scaled
+type ContainerScalingRule struct {
+	// Name of the container specified as a DNS_LABEL.
+	// Each container in a pod must have a unique name (DNS_LABEL).
+	// Cannot be updated.
+	Name string `json:"name"`
+
+	// ContainerType selects whether the rule applies to one of the containers or one of the initContainers
+	// of the pod: containers (the default) or initContainers.
+	ContainerType ContainerType `json:"containerType,omitempty"`
+
+	// Compute Resources required by this container.
+	// cf Container resources
+	// +optional
+	Resources ResourceRequirements `json:"resources,omitempty"`
+}
+
+// ContainerType specifies the list of containers in the PodSpec to which a ContainerScalingRule applies
+type ContainerType string
+
+const (
+	// ContainerTypeContainers applies the rule to one of the containers of the pod
+	ContainerTypeContainers ContainerType = "containers"
+	// ContainerTypeInitContainers applies the rule to one of the initContainers of the pod
+	ContainerTypeInitContainers ContainerType = "initContainers"
+)
+
+// ResourceRequirements holds the functions for resource limits & requests
+// TODO: Should we just embed this in the parent?
+type ResourceRequirements struct {
+	// Limits describes the maximum amount of compute resources allowed.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/
+	// +optional
+	Limits []ResourceScalingRule `json:"limits,omitempty"`
+	// Requests describes the minimum amount of compute resources required.
+	// If Requests is omitted for a container, it defaults to Limits if that is explicitly specified,
+	// otherwise to an implementation-defined value.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/
+	// +optional
+	Requests []ResourceScalingRule `json:"requests,omitempty"`
+}
+
+type ResourceScalingRule struct {
+	// Resource is the name of the resource we are scaling
+	Resource v1.ResourceName `json:"resource"`
+
+	// Function defines how the target resource usage
+	// depends on a set of input values (such as cluster core count, number of nodes etc)
+	Function ResourceScalingFunction `json:"function"`
+
+	// Max limits the maximum computed value of the resource.
+	// If the value computed is greater than Max, we will use Max instead
+	Max resource.Quantity `json:"max,omitempty"`
+
+	// Min limits the minimum computed value of the resource.
+	// If the value computed is less than Min, we will use Min instead.
+	// We never compute zero or a negative value, even if Min is not specified: the floor is then 1m for cpu and 1 otherwise.
+	Min resource.Quantity `json:"min,omitempty"`
+
+	// Rounding rounds the computed value to a multiple of a step, so that we use values that are friendly
+	// to humans (e.g. 128Mi rather than 107Mi).  It applies to both the target and the scale-down threshold,
+	// and is applied before the min & max bounds.
+	Rounding *OutputRounding `json:"rounding,omitempty"`
+
+	// Combiner determines how the values are combined when there are multiple rules for the same resource.
+	// Each rule is evaluated independently (with its own segments and delays), and the results are combined.
+	// The combiner can be set on any of the rules for the resource, but rules which set it must agree; the default is to sum the values.
+	// The min & max of every rule also bound the combined value.
+	Combiner ResourceCombiner `json:"combiner,omitempty"`
+
+	// Smoothing computes the target from a smoothed estimate of the computed values, rather than the latest value.
+	// This avoids following brief dips in the inputs, for example while nodes are replaced during a rolling update.
+	Smoothing *Smoothing `json:"smoothing,omitempty"`
+
+	// DelayScaleDown delays scaling down, so that we don't restart the target for brief dips in the inputs.
+	// We scale down once the target has been lower than the current value for DelaySeconds,
+	// or when the target computed with the input increased by Max is lower than the current value.
+	DelayScaleDown *DelayScaling `json:"delayScaleDown,omitempty"`
+
+	// DelayScaleUp delays scaling up, for targets which are expensive to restart.
+	// We scale up once the target has been higher than the current value for DelaySeconds,
+	// or when the target computed with the input reduced by Max is higher than the current value.
+	DelayScaleUp *DelayScaling `json:"delayScaleUp,omitempty"`
+}
+
+// OutputRounding defines how we round the computed resource value
+type OutputRounding struct {
+	// RoundTo is the step to which we round, for example 32Mi or 100m
+	RoundTo resource.Quantity `json:"roundTo"`
+
+	// Mode is the direction in which we round: up, down or nearest.  The default is up.
+	Mode RoundingMode `json:"mode,omitempty"`
+}
+
+// RoundingMode specifies the direction in which we round values
+type RoundingMode string
+
+const (
+	// RoundUp rounds up to the next multiple, so that we never allocate less than the computed value
+	RoundUp RoundingMode = "up"
+	// RoundDown rounds down to the previous multiple
+	RoundDown RoundingMode = "down"
+	// RoundNearest rounds to the closest multiple, rounding halfway values up
+	RoundNearest RoundingMode = "nearest"
+)
+
+// ResourceCombiner specifies how we combine the values of multiple rules for the same resource
+type ResourceCombiner string
+
+const (
+	// CombineSum adds the values of the rules, so that we can express e.g. base + pods*a + nodes*b
+	CombineSum ResourceCombiner = "sum"
+	// CombineMax uses the largest value of any rule
+	CombineMax ResourceCombiner = "max"
+	// CombineMin uses the smallest value of any rule
+	CombineMin ResourceCombiner = "min"
+)
+
+type ResourceScalingFunction struct {
+	// Input is the source value to use as the input to scaling: `cores`, `memory`, `nodes`
+	Input string `json:"input,omitempty"`
+
+	// Base is the constant resource value we use regardless of input, the y-axis intercept
+	Base resource.Quantity `json:"base,omitempty"`
+
+	// Slope determines how fast the resource usage changes per unit of input.
+	// For each Input unit, we increase resources by Slope
+	Slope resource.Quantity `json:"slope,omitempty"`
+
+	// Per divides Input before multiplying by Slope, allowing us to specify slopes of < 1m per input unit.
+	// It has the units of the input, for example 2 cores or 1Gi of memory.  The default is 1.
+	Per resource.Quantity `json:"per,omitempty"`
+
+	// Segments defines a set of segments of the resource line.
+	// In each segment we define the interval with which we change values.
+	// This is typically used so that we resize for every input unit for small cluster,
+	// but for larger clusters we only resize for changes of N units or more.
+	// Where it is not otherwise defined, we assume a first value of { at: 0, every: 1 }
+	Segments []ResourceScalingSegment `json:"segments,omitempty"`
+}
+
+// Smoothing defines how we smooth the computed values.
+// Either HalfLifeSeconds or Percentile (with WindowSeconds) should be specified; if both are set, the percentile is used.
+type Smoothing struct {
+	// HalfLifeSeconds uses an exponentially weighted moving average of the values, where the weight of each value
+	// halves after HalfLifeSeconds.
+	HalfLifeSeconds int32 `json:"halfLifeSeconds,omitempty"`
+
+	// Percentile uses the Nth percentile (1-100) of the values observed in the last WindowSeconds; 0 uses HalfLifeSeconds.
+	// For example a percentile of 90 ignores brief dips, while still following sustained changes.
+	Percentile int32 `json:"percentile,omitempty"`
+
+	// WindowSeconds is the window over which we compute the percentile
+	WindowSeconds int32 `json:"windowSeconds,omitempty"`
+}
+
+// ResourceScalingSegment describes a segment of input values and the rounding policy we apply to it
+type ResourceScalingSegment struct {
+	// The segment applies to values greater than or equal to at.  The "closest" segment is selected
+	// It has the units of the input, for example 64Gi for the memory input.
+	At resource.Quantity `json:"at,omitempty"`
+
+	// Every specifies the granularity to which we round.  We always round up to the next multiple of Every.
+	Every resource.Quantity `json:"every,omitempty"`
+}
+
+// ScalingPolicyStatus is the status for an ScalingPolicy resource
+type ScalingPolicyStatus struct {
+	// ObservedGeneration is the generation of the ScalingPolicy most recently observed by the scaler
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Inputs holds the most recently observed values of the inputs used by the policy
+	Inputs []InputValue `json:"inputs,omitempty"`
+
+	// InputsTime is the time at which the Inputs were observed
+	InputsTime *metav1.Time `json:"inputsTime,omitempty"`
+
+	// Containers holds the values we have most recently computed for each container
+	Containers []ContainerScalingStatus `json:"containers,omitempty"`
+
+	// LastAppliedTime is the time at which we last applied a change to the target
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// Conditions holds the latest observations of the state of the policy
+	Conditions []ScalingPolicyCondition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// InputValue is an observed value of a scaling input
+type InputValue struct {
+	// Name is the name of the input, e.g. `cores`, `memory`, `nodes`
+	Name string `json:"name"`
+
+	// Value is the observed value of the input
+	Value resource.Quantity `json:"value"`
+}
+
+// ContainerScalingStatus holds the computed values for a container
+type ContainerScalingStatus struct {
+	// Name of the container
+	Name string `json:"name"`
+
+	// Target holds the latest computed target values
+	Target v1.ResourceRequirements `json:"target,omitempty"`
+
+	// ScaleDownThreshold holds the values above which we will scale down
+	ScaleDownThreshold v1.ResourceRequirements `json:"scaleDownThreshold,omitempty"`
+
+	// ScaleUpThreshold holds the values below which we will scale up, when scale-up is delayed
+	ScaleUpThreshold v1.ResourceRequirements `json:"scaleUpThreshold,omitempty"`
+}
+
+type ScalingPolicyConditionType string
+
+const (
+	// TargetFound is true when the scaleTargetRef could be read
+	TargetFound ScalingPolicyConditionType = "TargetFound"
+
+	// InputsAvailable is true when all the inputs used by the policy have been observed
+	InputsAvailable ScalingPolicyConditionType = "InputsAvailable"
+
+	// Applied is true when the target matches the computed values, or the last patch succeeded
+	Applied ScalingPolicyConditionType = "Applied"
+
+	// RolloutComplete is true when the rollout of our last patch to the target has converged,
+	// and false while it is in progress or if it has stalled
+	RolloutComplete ScalingPolicyConditionType = "RolloutComplete"
+
+	// Valid is false when the spec of the policy failed validation; we don't apply a policy until it is valid
+	Valid ScalingPolicyConditionType = "Valid"
+)
+
+// ScalingPolicyCondition describes the state of a ScalingPolicy at a certain point
+type ScalingPolicyCondition struct {
+	// Type of the condition
+	Type ScalingPolicyConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown
+	Status v1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the last time the condition transitioned from one status to another
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a machine-readable explanation for the condition's last transition
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human-readable explanation for the condition's last transition
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ScalingPolicyList is a list of ScalingPolicy resources
+type ScalingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ScalingPolicy `json:"items"`
+}
@@ -0,0 +1,610 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was autogenerated by conversion-gen. Do not edit it manually!
+
+package v1beta1
+
+import (
+	unsafe "unsafe"
+
+	v1alpha1 "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	conversion "k8s.io/apimachinery/pkg/conversion"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	localSchemeBuilder.Register(RegisterConversions)
+}
+
+// RegisterConversions adds conversion functions to the given scheme.
+// Public to allow building arbitrary schemes.
+func RegisterConversions(scheme *runtime.Scheme) error {
+	return scheme.AddGeneratedConversionFuncs(
+		Convert_v1beta1_ContainerScalingRule_To_v1alpha1_ContainerScalingRule,
+		Convert_v1alpha1_ContainerScalingRule_To_v1beta1_ContainerScalingRule,
+		Convert_v1beta1_ContainerScalingStatus_To_v1alpha1_ContainerScalingStatus,
+		Convert_v1alpha1_ContainerScalingStatus_To_v1beta1_ContainerScalingStatus,
+		Convert_v1beta1_DelayScaling_To_v1alpha1_DelayScaling,
+		Convert_v1alpha1_DelayScaling_To_v1beta1_DelayScaling,
+		Convert_v1beta1_InputValue_To_v1alpha1_InputValue,
+		Convert_v1alpha1_InputValue_To_v1beta1_InputValue,
+		Convert_v1beta1_NodeFilter_To_v1alpha1_NodeFilter,
+		Convert_v1alpha1_NodeFilter_To_v1beta1_NodeFilter,
+		Convert_v1beta1_ObjectFilter_To_v1alpha1_ObjectFilter,
+		Convert_v1alpha1_ObjectFilter_To_v1beta1_ObjectFilter,
+		Convert_v1beta1_OutputRounding_To_v1alpha1_OutputRounding,
+		Convert_v1alpha1_OutputRounding_To_v1beta1_OutputRounding,
+		Convert_v1beta1_ResourceRequirements_To_v1alpha1_ResourceRequirements,
+		Convert_v1alpha1_ResourceRequirements_To_v1beta1_ResourceRequirements,
+		Convert_v1beta1_ScalingInput_To_v1alpha1_ScalingInput,
+		Convert_v1alpha1_ScalingInput_To_v1beta1_ScalingInput,
+		Convert_v1beta1_ScalingPolicy_To_v1alpha1_ScalingPolicy,
+		Convert_v1alpha1_ScalingPolicy_To_v1beta1_ScalingPolicy,
+		Convert_v1beta1_ScalingPolicyCondition_To_v1alpha1_ScalingPolicyCondition,
+		Convert_v1alpha1_ScalingPolicyCondition_To_v1beta1_ScalingPolicyCondition,
+		Convert_v1beta1_ScalingPolicyList_To_v1alpha1_ScalingPolicyList,
+		Convert_v1alpha1_ScalingPolicyList_To_v1beta1_ScalingPolicyList,
+		Convert_v1beta1_ScalingPolicySpec_To_v1alpha1_ScalingPolicySpec,
+		Convert_v1alpha1_ScalingPolicySpec_To_v1beta1_ScalingPolicySpec,
+		Convert_v1beta1_ScalingPolicyStatus_To_v1alpha1_ScalingPolicyStatus,
+		Convert_v1alpha1_ScalingPolicyStatus_To_v1beta1_ScalingPolicyStatus,
+		Convert_v1beta1_Smoothing_To_v1alpha1_Smoothing,
+		Convert_v1alpha1_Smoothing_To_v1beta1_Smoothing,
+	)
+}
+
+func autoConvert_v1beta1_ContainerScalingRule_To_v1alpha1_ContainerScalingRule(in *ContainerScalingRule, out *v1alpha1.ContainerScalingRule, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ContainerType = v1alpha1.ContainerType(in.ContainerType)
+	if err := Convert_v1beta1_ResourceRequirements_To_v1alpha1_ResourceRequirements(&in.Resources, &out.Resources, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_ContainerScalingRule_To_v1alpha1_ContainerScalingRule is an autogenerated conversion function.
+func Convert_v1beta1_ContainerScalingRule_To_v1alpha1_ContainerScalingRule(in *ContainerScalingRule, out *v1alpha1.ContainerScalingRule, s conversion.Scope) error {
+	return autoConvert_v1beta1_ContainerScalingRule_To_v1alpha1_ContainerScalingRule(in, out, s)
+}
+
+func autoConvert_v1alpha1_ContainerScalingRule_To_v1beta1_ContainerScalingRule(in *v1alpha1.ContainerScalingRule, out *ContainerScalingRule, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ContainerType = ContainerType(in.ContainerType)
+	if err := Convert_v1alpha1_ResourceRequirements_To_v1beta1_ResourceRequirements(&in.Resources, &out.Resources, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_ContainerScalingRule_To_v1beta1_ContainerScalingRule is an autogenerated conversion function.
+func Convert_v1alpha1_ContainerScalingRule_To_v1beta1_ContainerScalingRule(in *v1alpha1.ContainerScalingRule, out *ContainerScalingRule, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ContainerScalingRule_To_v1beta1_ContainerScalingRule(in, out, s)
+}
+
+func autoConvert_v1beta1_ContainerScalingStatus_To_v1alpha1_ContainerScalingStatus(in *ContainerScalingStatus, out *v1alpha1.ContainerScalingStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Target = in.Target
+	out.ScaleDownThreshold = in.ScaleDownThreshold
+	out.ScaleUpThreshold = in.ScaleUpThreshold
+	return nil
+}
+
+// Convert_v1beta1_ContainerScalingStatus_To_v1alpha1_ContainerScalingStatus is an autogenerated conversion function.
+func Convert_v1beta1_ContainerScalingStatus_To_v1alpha1_ContainerScalingStatus(in *ContainerScalingStatus, out *v1alpha1.ContainerScalingStatus, s conversion.Scope) error {
+	return autoConvert_v1beta1_ContainerScalingStatus_To_v1alpha1_ContainerScalingStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_ContainerScalingStatus_To_v1beta1_ContainerScalingStatus(in *v1alpha1.ContainerScalingStatus, out *ContainerScalingStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Target = in.Target
+	out.ScaleDownThreshold = in.ScaleDownThreshold
+	out.ScaleUpThreshold = in.ScaleUpThreshold
+	return nil
+}
+
+// Convert_v1alpha1_ContainerScalingStatus_To_v1beta1_ContainerScalingStatus is an autogenerated conversion function.
+func Convert_v1alpha1_ContainerScalingStatus_To_v1beta1_ContainerScalingStatus(in *v1alpha1.ContainerScalingStatus, out *ContainerScalingStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ContainerScalingStatus_To_v1beta1_ContainerScalingStatus(in, out, s)
+}
+
+func autoConvert_v1beta1_DelayScaling_To_v1alpha1_DelayScaling(in *DelayScaling, out *v1alpha1.DelayScaling, s conversion.Scope) error {
+	out.Max = in.Max
+	out.DelaySeconds = in.DelaySeconds
+	return nil
+}
+
+// Convert_v1beta1_DelayScaling_To_v1alpha1_DelayScaling is an autogenerated conversion function.
+func Convert_v1beta1_DelayScaling_To_v1alpha1_DelayScaling(in *DelayScaling, out *v1alpha1.DelayScaling, s conversion.Scope) error {
+	return autoConvert_v1beta1_DelayScaling_To_v1alpha1_DelayScaling(in, out, s)
+}
+
+func autoConvert_v1alpha1_DelayScaling_To_v1beta1_DelayScaling(in *v1alpha1.DelayScaling, out *DelayScaling, s conversion.Scope) error {
+	out.Max = in.Max
+	out.DelaySeconds = in.DelaySeconds
+	return nil
+}
+
+// Convert_v1alpha1_DelayScaling_To_v1beta1_DelayScaling is an autogenerated conversion function.
+func Convert_v1alpha1_DelayScaling_To_v1beta1_DelayScaling(in *v1alpha1.DelayScaling, out *DelayScaling, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DelayScaling_To_v1beta1_DelayScaling(in, out, s)
+}
+
+func autoConvert_v1beta1_InputValue_To_v1alpha1_InputValue(in *InputValue, out *v1alpha1.InputValue, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Value = in.Value
+	return nil
+}
+
+// Convert_v1beta1_InputValue_To_v1alpha1_InputValue is an autogenerated conversion function.
+func Convert_v1beta1_InputValue_To_v1alpha1_InputValue(in *InputValue, out *v1alpha1.InputValue, s conversion.Scope) error {
+	return autoConvert_v1beta1_InputValue_To_v1alpha1_InputValue(in, out, s)
+}
+
+func autoConvert_v1alpha1_InputValue_To_v1beta1_InputValue(in *v1alpha1.InputValue, out *InputValue, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Value = in.Value
+	return nil
+}
+
+// Convert_v1alpha1_InputValue_To_v1beta1_InputValue is an autogenerated conversion function.
+func Convert_v1alpha1_InputValue_To_v1beta1_InputValue(in *v1alpha1.InputValue, out *InputValue, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InputValue_To_v1beta1_InputValue(in, out, s)
+}
+
+func autoConvert_v1beta1_NodeFilter_To_v1alpha1_NodeFilter(in *NodeFilter, out *v1alpha1.NodeFilter, s conversion.Scope) error {
+	out.Selector = (*meta_v1.LabelSelector)(unsafe.Pointer(in.Selector))
+	out.Tolerations = *(*[]core_v1.Toleration)(unsafe.Pointer(&in.Tolerations))
+	out.ReadyOnly = in.ReadyOnly
+	return nil
+}
+
+// Convert_v1beta1_NodeFilter_To_v1alpha1_NodeFilter is an autogenerated conversion function.
+func Convert_v1beta1_NodeFilter_To_v1alpha1_NodeFilter(in *NodeFilter, out *v1alpha1.NodeFilter, s conversion.Scope) error {
+	return autoConvert_v1beta1_NodeFilter_To_v1alpha1_NodeFilter(in, out, s)
+}
+
+func autoConvert_v1alpha1_NodeFilter_To_v1beta1_NodeFilter(in *v1alpha1.NodeFilter, out *NodeFilter, s conversion.Scope) error {
+	out.Selector = (*meta_v1.LabelSelector)(unsafe.Pointer(in.Selector))
+	out.Tolerations = *(*[]core_v1.Toleration)(unsafe.Pointer(&in.Tolerations))
+	out.ReadyOnly = in.ReadyOnly
+	return nil
+}
+
+// Convert_v1alpha1_NodeFilter_To_v1beta1_NodeFilter is an autogenerated conversion function.
+func Convert_v1alpha1_NodeFilter_To_v1beta1_NodeFilter(in *v1alpha1.NodeFilter, out *NodeFilter, s conversion.Scope) error {
+	return autoConvert_v1alpha1_NodeFilter_To_v1beta1_NodeFilter(in, out, s)
+}
+
+func autoConvert_v1beta1_ObjectFilter_To_v1alpha1_ObjectFilter(in *ObjectFilter, out *v1alpha1.ObjectFilter, s conversion.Scope) error {
+	out.Selector = (*meta_v1.LabelSelector)(unsafe.Pointer(in.Selector))
+	out.FieldSelector = in.FieldSelector
+	return nil
+}
+
+// Convert_v1beta1_ObjectFilter_To_v1alpha1_ObjectFilter is an autogenerated conversion function.
+func Convert_v1beta1_ObjectFilter_To_v1alpha1_ObjectFilter(in *ObjectFilter, out *v1alpha1.ObjectFilter, s conversion.Scope) error {
+	return autoConvert_v1beta1_ObjectFilter_To_v1alpha1_ObjectFilter(in, out, s)
+}
+
+func autoConvert_v1alpha1_ObjectFilter_To_v1beta1_ObjectFilter(in *v1alpha1.ObjectFilter, out *ObjectFilter, s conversion.Scope) error {
+	out.Selector = (*meta_v1.LabelSelector)(unsafe.Pointer(in.Selector))
+	out.FieldSelector = in.FieldSelector
+	return nil
+}
+
+// Convert_v1alpha1_ObjectFilter_To_v1beta1_ObjectFilter is an autogenerated conversion function.
+func Convert_v1alpha1_ObjectFilter_To_v1beta1_ObjectFilter(in *v1alpha1.ObjectFilter, out *ObjectFilter, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ObjectFilter_To_v1beta1_ObjectFilter(in, out, s)
+}
+
+func autoConvert_v1beta1_OutputRounding_To_v1alpha1_OutputRounding(in *OutputRounding, out *v1alpha1.OutputRounding, s conversion.Scope) error {
+	out.RoundTo = in.RoundTo
+	out.Mode = v1alpha1.RoundingMode(in.Mode)
+	return nil
+}
+
+// Convert_v1beta1_OutputRounding_To_v1alpha1_OutputRounding is an autogenerated conversion function.
+func Convert_v1beta1_OutputRounding_To_v1alpha1_OutputRounding(in *OutputRounding, out *v1alpha1.OutputRounding, s conversion.Scope) error {
+	return autoConvert_v1beta1_OutputRounding_To_v1alpha1_OutputRounding(in, out, s)
+}
+
+func autoConvert_v1alpha1_OutputRounding_To_v1beta1_OutputRounding(in *v1alpha1.OutputRounding, out *OutputRounding, s conversion.Scope) error {
+	out.RoundTo = in.RoundTo
+	out.Mode = RoundingMode(in.Mode)
+	return nil
+}
+
+// Convert_v1alpha1_OutputRounding_To_v1beta1_OutputRounding is an autogenerated conversion function.
+func Convert_v1alpha1_OutputRounding_To_v1beta1_OutputRounding(in *v1alpha1.OutputRounding, out *OutputRounding, s conversion.Scope) error {
+	return autoConvert_v1alpha1_OutputRounding_To_v1beta1_OutputRounding(in, out, s)
+}
+
+func autoConvert_v1beta1_ResourceRequirements_To_v1alpha1_ResourceRequirements(in *ResourceRequirements, out *v1alpha1.ResourceRequirements, s conversion.Scope) error {
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make([]v1alpha1.ResourceScalingRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ResourceScalingRule_To_v1alpha1_ResourceScalingRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Limits = nil
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]v1alpha1.ResourceScalingRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ResourceScalingRule_To_v1alpha1_ResourceScalingRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Requests = nil
+	}
+	return nil
+}
+
+// Convert_v1beta1_ResourceRequirements_To_v1alpha1_ResourceRequirements is an autogenerated conversion function.
+func Convert_v1beta1_ResourceRequirements_To_v1alpha1_ResourceRequirements(in *ResourceRequirements, out *v1alpha1.ResourceRequirements, s conversion.Scope) error {
+	return autoConvert_v1beta1_ResourceRequirements_To_v1alpha1_ResourceRequirements(in, out, s)
+}
+
+func autoConvert_v1alpha1_ResourceRequirements_To_v1beta1_ResourceRequirements(in *v1alpha1.ResourceRequirements, out *ResourceRequirements, s conversion.Scope) error {
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make([]ResourceScalingRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ResourceScalingRule_To_v1beta1_ResourceScalingRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Limits = nil
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]ResourceScalingRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ResourceScalingRule_To_v1beta1_ResourceScalingRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Requests = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha1_ResourceRequirements_To_v1beta1_ResourceRequirements is an autogenerated conversion function.
+func Convert_v1alpha1_ResourceRequirements_To_v1beta1_ResourceRequirements(in *v1alpha1.ResourceRequirements, out *ResourceRequirements, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ResourceRequirements_To_v1beta1_ResourceRequirements(in, out, s)
+}
+
+func autoConvert_v1beta1_ResourceScalingFunction_To_v1alpha1_ResourceScalingFunction(in *ResourceScalingFunction, out *v1alpha1.ResourceScalingFunction, s conversion.Scope) error {
+	out.Input = in.Input
+	out.Base = in.Base
+	out.Slope = in.Slope
+	// WARNING: in.Per requires manual conversion: inconvertible types (k8s.io/apimachinery/pkg/api/resource.Quantity vs int64)
+	if in.Segments != nil {
+		in, out := &in.Segments, &out.Segments
+		*out = make([]v1alpha1.ResourceScalingSegment, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ResourceScalingSegment_To_v1alpha1_ResourceScalingSegment(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Segments = nil
+	}
+	return nil
+}
+
+func autoConvert_v1alpha1_ResourceScalingFunction_To_v1beta1_ResourceScalingFunction(in *v1alpha1.ResourceScalingFunction, out *ResourceScalingFunction, s conversion.Scope) error {
+	out.Input = in.Input
+	out.Base = in.Base
+	out.Slope = in.Slope
+	// WARNING: in.Per requires manual conversion: inconvertible types (int64 vs k8s.io/apimachinery/pkg/api/resource.Quantity)
+	if in.Segments != nil {
+		in, out := &in.Segments, &out.Segments
+		*out = make([]ResourceScalingSegment, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ResourceScalingSegment_To_v1beta1_ResourceScalingSegment(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Segments = nil
+	}
+	// WARNING: in.Smoothing requires manual conversion: does not exist in peer-type
+	// WARNING: in.DelayScaleDown requires manual conversion: does not exist in peer-type
+	// WARNING: in.DelayScaleUp requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1beta1_ResourceScalingRule_To_v1alpha1_ResourceScalingRule(in *ResourceScalingRule, out *v1alpha1.ResourceScalingRule, s conversion.Scope) error {
+	out.Resource = core_v1.ResourceName(in.Resource)
+	if err := Convert_v1beta1_ResourceScalingFunction_To_v1alpha1_ResourceScalingFunction(&in.Function, &out.Function, s); err != nil {
+		return err
+	}
+	out.Max = in.Max
+	out.Min = in.Min
+	out.Rounding = (*v1alpha1.OutputRounding)(unsafe.Pointer(in.Rounding))
+	out.Combiner = v1alpha1.ResourceCombiner(in.Combiner)
+	// WARNING: in.Smoothing requires manual conversion: does not exist in peer-type
+	// WARNING: in.DelayScaleDown requires manual conversion: does not exist in peer-type
+	// WARNING: in.DelayScaleUp requires manual conversion: does not exist in peer-type
+	return nil
+}
+
+func autoConvert_v1alpha1_ResourceScalingRule_To_v1beta1_ResourceScalingRule(in *v1alpha1.ResourceScalingRule, out *ResourceScalingRule, s conversion.Scope) error {
+	out.Resource = core_v1.ResourceName(in.Resource)
+	if err := Convert_v1alpha1_ResourceScalingFunction_To_v1beta1_ResourceScalingFunction(&in.Function, &out.Function, s); err != nil {
+		return err
+	}
+	out.Max = in.Max
+	out.Min = in.Min
+	out.Rounding = (*OutputRounding)(unsafe.Pointer(in.Rounding))
+	out.Combiner = ResourceCombiner(in.Combiner)
+	return nil
+}
+
+func autoConvert_v1beta1_ResourceScalingSegment_To_v1alpha1_ResourceScalingSegment(in *ResourceScalingSegment, out *v1alpha1.ResourceScalingSegment, s conversion.Scope) error {
+	// WARNING: in.At requires manual conversion: inconvertible types (k8s.io/apimachinery/pkg/api/resource.Quantity vs int64)
+	// WARNING: in.Every requires manual conversion: inconvertible types (k8s.io/apimachinery/pkg/api/resource.Quantity vs int64)
+	return nil
+}
+
+func autoConvert_v1alpha1_ResourceScalingSegment_To_v1beta1_ResourceScalingSegment(in *v1alpha1.ResourceScalingSegment, out *ResourceScalingSegment, s conversion.Scope) error {
+	// WARNING: in.At requires manual conversion: inconvertible types (int64 vs k8s.io/apimachinery/pkg/api/resource.Quantity)
+	// WARNING: in.Every requires manual conversion: inconvertible types (int64 vs k8s.io/apimachinery/pkg/api/resource.Quantity)
+	return nil
+}
+
+func autoConvert_v1beta1_ScalingInput_To_v1alpha1_ScalingInput(in *ScalingInput, out *v1alpha1.ScalingInput, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Source = in.Source
+	out.Nodes = (*v1alpha1.NodeFilter)(unsafe.Pointer(in.Nodes))
+	out.Objects = (*v1alpha1.ObjectFilter)(unsafe.Pointer(in.Objects))
+	return nil
+}
+
+// Convert_v1beta1_ScalingInput_To_v1alpha1_ScalingInput is an autogenerated conversion function.
+func Convert_v1beta1_ScalingInput_To_v1alpha1_ScalingInput(in *ScalingInput, out *v1alpha1.ScalingInput, s conversion.Scope) error {
+	return autoConvert_v1beta1_ScalingInput_To_v1alpha1_ScalingInput(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScalingInput_To_v1beta1_ScalingInput(in *v1alpha1.ScalingInput, out *ScalingInput, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Source = in.Source
+	out.Nodes = (*NodeFilter)(unsafe.Pointer(in.Nodes))
+	out.Objects = (*ObjectFilter)(unsafe.Pointer(in.Objects))
+	return nil
+}
+
+// Convert_v1alpha1_ScalingInput_To_v1beta1_ScalingInput is an autogenerated conversion function.
+func Convert_v1alpha1_ScalingInput_To_v1beta1_ScalingInput(in *v1alpha1.ScalingInput, out *ScalingInput, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScalingInput_To_v1beta1_ScalingInput(in, out, s)
+}
+
+func autoConvert_v1beta1_ScalingPolicy_To_v1alpha1_ScalingPolicy(in *ScalingPolicy, out *v1alpha1.ScalingPolicy, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1beta1_ScalingPolicySpec_To_v1alpha1_ScalingPolicySpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1beta1_ScalingPolicyStatus_To_v1alpha1_ScalingPolicyStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_ScalingPolicy_To_v1alpha1_ScalingPolicy is an autogenerated conversion function.
+func Convert_v1beta1_ScalingPolicy_To_v1alpha1_ScalingPolicy(in *ScalingPolicy, out *v1alpha1.ScalingPolicy, s conversion.Scope) error {
+	return autoConvert_v1beta1_ScalingPolicy_To_v1alpha1_ScalingPolicy(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScalingPolicy_To_v1beta1_ScalingPolicy(in *v1alpha1.ScalingPolicy, out *ScalingPolicy, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1alpha1_ScalingPolicySpec_To_v1beta1_ScalingPolicySpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1alpha1_ScalingPolicyStatus_To_v1beta1_ScalingPolicyStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1alpha1_ScalingPolicy_To_v1beta1_ScalingPolicy is an autogenerated conversion function.
+func Convert_v1alpha1_ScalingPolicy_To_v1beta1_ScalingPolicy(in *v1alpha1.ScalingPolicy, out *ScalingPolicy, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScalingPolicy_To_v1beta1_ScalingPolicy(in, out, s)
+}
+
+func autoConvert_v1beta1_ScalingPolicyCondition_To_v1alpha1_ScalingPolicyCondition(in *ScalingPolicyCondition, out *v1alpha1.ScalingPolicyCondition, s conversion.Scope) error {
+	out.Type = v1alpha1.ScalingPolicyConditionType(in.Type)
+	out.Status = core_v1.ConditionStatus(in.Status)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1beta1_ScalingPolicyCondition_To_v1alpha1_ScalingPolicyCondition is an autogenerated conversion function.
+func Convert_v1beta1_ScalingPolicyCondition_To_v1alpha1_ScalingPolicyCondition(in *ScalingPolicyCondition, out *v1alpha1.ScalingPolicyCondition, s conversion.Scope) error {
+	return autoConvert_v1beta1_ScalingPolicyCondition_To_v1alpha1_ScalingPolicyCondition(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScalingPolicyCondition_To_v1beta1_ScalingPolicyCondition(in *v1alpha1.ScalingPolicyCondition, out *ScalingPolicyCondition, s conversion.Scope) error {
+	out.Type = ScalingPolicyConditionType(in.Type)
+	out.Status = core_v1.ConditionStatus(in.Status)
+	out.LastTransitionTime = in.LastTransitionTime
+	out.Reason = in.Reason
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_ScalingPolicyCondition_To_v1beta1_ScalingPolicyCondition is an autogenerated conversion function.
+func Convert_v1alpha1_ScalingPolicyCondition_To_v1beta1_ScalingPolicyCondition(in *v1alpha1.ScalingPolicyCondition, out *ScalingPolicyCondition, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScalingPolicyCondition_To_v1beta1_ScalingPolicyCondition(in, out, s)
+}
+
+func autoConvert_v1beta1_ScalingPolicyList_To_v1alpha1_ScalingPolicyList(in *ScalingPolicyList, out *v1alpha1.ScalingPolicyList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]v1alpha1.ScalingPolicy, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ScalingPolicy_To_v1alpha1_ScalingPolicy(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1beta1_ScalingPolicyList_To_v1alpha1_ScalingPolicyList is an autogenerated conversion function.
+func Convert_v1beta1_ScalingPolicyList_To_v1alpha1_ScalingPolicyList(in *ScalingPolicyList, out *v1alpha1.ScalingPolicyList, s conversion.Scope) error {
+	return autoConvert_v1beta1_ScalingPolicyList_To_v1alpha1_ScalingPolicyList(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScalingPolicyList_To_v1beta1_ScalingPolicyList(in *v1alpha1.ScalingPolicyList, out *ScalingPolicyList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScalingPolicy, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ScalingPolicy_To_v1beta1_ScalingPolicy(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Items = nil
+	}
+	return nil
+}
+
+// Convert_v1alpha1_ScalingPolicyList_To_v1beta1_ScalingPolicyList is an autogenerated conversion function.
+func Convert_v1alpha1_ScalingPolicyList_To_v1beta1_ScalingPolicyList(in *v1alpha1.ScalingPolicyList, out *ScalingPolicyList, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScalingPolicyList_To_v1beta1_ScalingPolicyList(in, out, s)
+}
+
+func autoConvert_v1beta1_ScalingPolicySpec_To_v1alpha1_ScalingPolicySpec(in *ScalingPolicySpec, out *v1alpha1.ScalingPolicySpec, s conversion.Scope) error {
+	out.ScaleTargetRef = in.ScaleTargetRef
+	out.PodSpecPath = in.PodSpecPath
+	out.Inputs = *(*[]v1alpha1.ScalingInput)(unsafe.Pointer(&in.Inputs))
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]v1alpha1.ContainerScalingRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta1_ContainerScalingRule_To_v1alpha1_ContainerScalingRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Containers = nil
+	}
+	out.RequestLimitPolicy = v1alpha1.RequestLimitPolicy(in.RequestLimitPolicy)
+	return nil
+}
+
+// Convert_v1beta1_ScalingPolicySpec_To_v1alpha1_ScalingPolicySpec is an autogenerated conversion function.
+func Convert_v1beta1_ScalingPolicySpec_To_v1alpha1_ScalingPolicySpec(in *ScalingPolicySpec, out *v1alpha1.ScalingPolicySpec, s conversion.Scope) error {
+	return autoConvert_v1beta1_ScalingPolicySpec_To_v1alpha1_ScalingPolicySpec(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScalingPolicySpec_To_v1beta1_ScalingPolicySpec(in *v1alpha1.ScalingPolicySpec, out *ScalingPolicySpec, s conversion.Scope) error {
+	out.ScaleTargetRef = in.ScaleTargetRef
+	out.PodSpecPath = in.PodSpecPath
+	out.Inputs = *(*[]ScalingInput)(unsafe.Pointer(&in.Inputs))
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerScalingRule, len(*in))
+		for i := range *in {
+			if err := Convert_v1alpha1_ContainerScalingRule_To_v1beta1_ContainerScalingRule(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Containers = nil
+	}
+	out.RequestLimitPolicy = RequestLimitPolicy(in.RequestLimitPolicy)
+	return nil
+}
+
+// Convert_v1alpha1_ScalingPolicySpec_To_v1beta1_ScalingPolicySpec is an autogenerated conversion function.
+func Convert_v1alpha1_ScalingPolicySpec_To_v1beta1_ScalingPolicySpec(in *v1alpha1.ScalingPolicySpec, out *ScalingPolicySpec, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScalingPolicySpec_To_v1beta1_ScalingPolicySpec(in, out, s)
+}
+
+func autoConvert_v1beta1_ScalingPolicyStatus_To_v1alpha1_ScalingPolicyStatus(in *ScalingPolicyStatus, out *v1alpha1.ScalingPolicyStatus, s conversion.Scope) error {
+	out.ObservedGeneration = in.ObservedGeneration
+	out.Inputs = *(*[]v1alpha1.InputValue)(unsafe.Pointer(&in.Inputs))
+	out.InputsTime = (*meta_v1.Time)(unsafe.Pointer(in.InputsTime))
+	out.Containers = *(*[]v1alpha1.ContainerScalingStatus)(unsafe.Pointer(&in.Containers))
+	out.LastAppliedTime = (*meta_v1.Time)(unsafe.Pointer(in.LastAppliedTime))
+	out.Conditions = *(*[]v1alpha1.ScalingPolicyCondition)(unsafe.Pointer(&in.Conditions))
+	return nil
+}
+
+// Convert_v1beta1_ScalingPolicyStatus_To_v1alpha1_ScalingPolicyStatus is an autogenerated conversion function.
+func Convert_v1beta1_ScalingPolicyStatus_To_v1alpha1_ScalingPolicyStatus(in *ScalingPolicyStatus, out *v1alpha1.ScalingPolicyStatus, s conversion.Scope) error {
+	return autoConvert_v1beta1_ScalingPolicyStatus_To_v1alpha1_ScalingPolicyStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_ScalingPolicyStatus_To_v1beta1_ScalingPolicyStatus(in *v1alpha1.ScalingPolicyStatus, out *ScalingPolicyStatus, s conversion.Scope) error {
+	out.ObservedGeneration = in.ObservedGeneration
+	out.Inputs = *(*[]InputValue)(unsafe.Pointer(&in.Inputs))
+	out.InputsTime = (*meta_v1.Time)(unsafe.Pointer(in.InputsTime))
+	out.Containers = *(*[]ContainerScalingStatus)(unsafe.Pointer(&in.Containers))
+	out.LastAppliedTime = (*meta_v1.Time)(unsafe.Pointer(in.LastAppliedTime))
+	out.Conditions = *(*[]ScalingPolicyCondition)(unsafe.Pointer(&in.Conditions))
+	return nil
+}
+
+// Convert_v1alpha1_ScalingPolicyStatus_To_v1beta1_ScalingPolicyStatus is an autogenerated conversion function.
+func Convert_v1alpha1_ScalingPolicyStatus_To_v1beta1_ScalingPolicyStatus(in *v1alpha1.ScalingPolicyStatus, out *ScalingPolicyStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ScalingPolicyStatus_To_v1beta1_ScalingPolicyStatus(in, out, s)
+}
+
+func autoConvert_v1beta1_Smoothing_To_v1alpha1_Smoothing(in *Smoothing, out *v1alpha1.Smoothing, s conversion.Scope) error {
+	out.HalfLifeSeconds = in.HalfLifeSeconds
+	out.Percentile = in.Percentile
+	out.WindowSeconds = in.WindowSeconds
+	return nil
+}
+
+// Convert_v1beta1_Smoothing_To_v1alpha1_Smoothing is an autogenerated conversion function.
+func Convert_v1beta1_Smoothing_To_v1alpha1_Smoothing(in *Smoothing, out *v1alpha1.Smoothing, s conversion.Scope) error {
+	return autoConvert_v1beta1_Smoothing_To_v1alpha1_Smoothing(in, out, s)
+}
+
+func autoConvert_v1alpha1_Smoothing_To_v1beta1_Smoothing(in *v1alpha1.Smoothing, out *Smoothing, s conversion.Scope) error {
+	out.HalfLifeSeconds = in.HalfLifeSeconds
+	out.Percentile = in.Percentile
+	out.WindowSeconds = in.WindowSeconds
+	return nil
+}
+
+// Convert_v1alpha1_Smoothing_To_v1beta1_Smoothing is an autogenerated conversion function.
+func Convert_v1alpha1_Smoothing_To_v1beta1_Smoothing(in *v1alpha1.Smoothing, out *Smoothing, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Smoothing_To_v1beta1_Smoothing(in, out, s)
+}
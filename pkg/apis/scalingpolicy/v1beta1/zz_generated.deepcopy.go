@@ -0,0 +1,603 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was autogenerated by deepcopy-gen. Do not edit it manually!
+
+package v1beta1
+
+import (
+	reflect "reflect"
+
+	core_v1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	conversion "k8s.io/apimachinery/pkg/conversion"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+func init() {
+	SchemeBuilder.Register(RegisterDeepCopies)
+}
+
+// RegisterDeepCopies adds deep-copy functions to the given scheme. Public
+// to allow building arbitrary schemes.
+//
+// Deprecated: deepcopy registration will go away when static deepcopy is fully implemented.
+func RegisterDeepCopies(scheme *runtime.Scheme) error {
+	return scheme.AddGeneratedDeepCopyFuncs(
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ContainerScalingRule).DeepCopyInto(out.(*ContainerScalingRule))
+			return nil
+		}, InType: reflect.TypeOf(&ContainerScalingRule{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ContainerScalingStatus).DeepCopyInto(out.(*ContainerScalingStatus))
+			return nil
+		}, InType: reflect.TypeOf(&ContainerScalingStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DelayScaling).DeepCopyInto(out.(*DelayScaling))
+			return nil
+		}, InType: reflect.TypeOf(&DelayScaling{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*InputValue).DeepCopyInto(out.(*InputValue))
+			return nil
+		}, InType: reflect.TypeOf(&InputValue{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*NodeFilter).DeepCopyInto(out.(*NodeFilter))
+			return nil
+		}, InType: reflect.TypeOf(&NodeFilter{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ObjectFilter).DeepCopyInto(out.(*ObjectFilter))
+			return nil
+		}, InType: reflect.TypeOf(&ObjectFilter{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*OutputRounding).DeepCopyInto(out.(*OutputRounding))
+			return nil
+		}, InType: reflect.TypeOf(&OutputRounding{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceRequirements).DeepCopyInto(out.(*ResourceRequirements))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceRequirements{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceScalingFunction).DeepCopyInto(out.(*ResourceScalingFunction))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceScalingFunction{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceScalingRule).DeepCopyInto(out.(*ResourceScalingRule))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceScalingRule{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ResourceScalingSegment).DeepCopyInto(out.(*ResourceScalingSegment))
+			return nil
+		}, InType: reflect.TypeOf(&ResourceScalingSegment{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingPolicy).DeepCopyInto(out.(*ScalingPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingPolicy{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingInput).DeepCopyInto(out.(*ScalingInput))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingInput{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingPolicyCondition).DeepCopyInto(out.(*ScalingPolicyCondition))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingPolicyCondition{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingPolicyList).DeepCopyInto(out.(*ScalingPolicyList))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingPolicyList{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingPolicySpec).DeepCopyInto(out.(*ScalingPolicySpec))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingPolicySpec{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ScalingPolicyStatus).DeepCopyInto(out.(*ScalingPolicyStatus))
+			return nil
+		}, InType: reflect.TypeOf(&ScalingPolicyStatus{})},
+		conversion.GeneratedDeepCopyFunc{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*Smoothing).DeepCopyInto(out.(*Smoothing))
+			return nil
+		}, InType: reflect.TypeOf(&Smoothing{})},
+	)
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerScalingRule) DeepCopyInto(out *ContainerScalingRule) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerScalingRule.
+func (in *ContainerScalingRule) DeepCopy() *ContainerScalingRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerScalingRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerScalingStatus) DeepCopyInto(out *ContainerScalingStatus) {
+	*out = *in
+	in.Target.DeepCopyInto(&out.Target)
+	in.ScaleDownThreshold.DeepCopyInto(&out.ScaleDownThreshold)
+	in.ScaleUpThreshold.DeepCopyInto(&out.ScaleUpThreshold)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerScalingStatus.
+func (in *ContainerScalingStatus) DeepCopy() *ContainerScalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerScalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DelayScaling) DeepCopyInto(out *DelayScaling) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DelayScaling.
+func (in *DelayScaling) DeepCopy() *DelayScaling {
+	if in == nil {
+		return nil
+	}
+	out := new(DelayScaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InputValue) DeepCopyInto(out *InputValue) {
+	*out = *in
+	out.Value = in.Value.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InputValue.
+func (in *InputValue) DeepCopy() *InputValue {
+	if in == nil {
+		return nil
+	}
+	out := new(InputValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFilter) DeepCopyInto(out *NodeFilter) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFilter.
+func (in *NodeFilter) DeepCopy() *NodeFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectFilter) DeepCopyInto(out *ObjectFilter) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectFilter.
+func (in *ObjectFilter) DeepCopy() *ObjectFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OutputRounding) DeepCopyInto(out *OutputRounding) {
+	*out = *in
+	out.RoundTo = in.RoundTo.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OutputRounding.
+func (in *OutputRounding) DeepCopy() *OutputRounding {
+	if in == nil {
+		return nil
+	}
+	out := new(OutputRounding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRequirements) DeepCopyInto(out *ResourceRequirements) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make([]ResourceScalingRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]ResourceScalingRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRequirements.
+func (in *ResourceRequirements) DeepCopy() *ResourceRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceScalingFunction) DeepCopyInto(out *ResourceScalingFunction) {
+	*out = *in
+	out.Base = in.Base.DeepCopy()
+	out.Slope = in.Slope.DeepCopy()
+	out.Per = in.Per.DeepCopy()
+	if in.Segments != nil {
+		in, out := &in.Segments, &out.Segments
+		*out = make([]ResourceScalingSegment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceScalingFunction.
+func (in *ResourceScalingFunction) DeepCopy() *ResourceScalingFunction {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceScalingFunction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceScalingRule) DeepCopyInto(out *ResourceScalingRule) {
+	*out = *in
+	in.Function.DeepCopyInto(&out.Function)
+	out.Max = in.Max.DeepCopy()
+	out.Min = in.Min.DeepCopy()
+	if in.Rounding != nil {
+		in, out := &in.Rounding, &out.Rounding
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(OutputRounding)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Smoothing != nil {
+		in, out := &in.Smoothing, &out.Smoothing
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(Smoothing)
+			**out = **in
+		}
+	}
+	if in.DelayScaleDown != nil {
+		in, out := &in.DelayScaleDown, &out.DelayScaleDown
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DelayScaling)
+			**out = **in
+		}
+	}
+	if in.DelayScaleUp != nil {
+		in, out := &in.DelayScaleUp, &out.DelayScaleUp
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DelayScaling)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceScalingRule.
+func (in *ResourceScalingRule) DeepCopy() *ResourceScalingRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceScalingRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceScalingSegment) DeepCopyInto(out *ResourceScalingSegment) {
+	*out = *in
+	out.At = in.At.DeepCopy()
+	out.Every = in.Every.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceScalingSegment.
+func (in *ResourceScalingSegment) DeepCopy() *ResourceScalingSegment {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceScalingSegment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingInput) DeepCopyInto(out *ScalingInput) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NodeFilter)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ObjectFilter)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingInput.
+func (in *ScalingInput) DeepCopy() *ScalingInput {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingInput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicy) DeepCopyInto(out *ScalingPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicy.
+func (in *ScalingPolicy) DeepCopy() *ScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScalingPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicyCondition) DeepCopyInto(out *ScalingPolicyCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicyCondition.
+func (in *ScalingPolicyCondition) DeepCopy() *ScalingPolicyCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicyCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicyList) DeepCopyInto(out *ScalingPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScalingPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicyList.
+func (in *ScalingPolicyList) DeepCopy() *ScalingPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScalingPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicySpec) DeepCopyInto(out *ScalingPolicySpec) {
+	*out = *in
+	out.ScaleTargetRef = in.ScaleTargetRef
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]ScalingInput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerScalingRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicySpec.
+func (in *ScalingPolicySpec) DeepCopy() *ScalingPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicyStatus) DeepCopyInto(out *ScalingPolicyStatus) {
+	*out = *in
+	if in.Inputs != nil {
+		in, out := &in.Inputs, &out.Inputs
+		*out = make([]InputValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InputsTime != nil {
+		in, out := &in.InputsTime, &out.InputsTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]ContainerScalingStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ScalingPolicyCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicyStatus.
+func (in *ScalingPolicyStatus) DeepCopy() *ScalingPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Smoothing) DeepCopyInto(out *Smoothing) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Smoothing.
+func (in *Smoothing) DeepCopy() *Smoothing {
+	if in == nil {
+		return nil
+	}
+	out := new(Smoothing)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,64 @@
+// +build !ignore_autogenerated
+
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file was autogenerated by defaulter-gen. Do not edit it manually!
+
+package v1beta1
+
+import (
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// RegisterDefaults adds defaulters functions to the given scheme.
+// Public to allow building arbitrary schemes.
+// All generated defaulters are covering - they call all nested defaulters.
+func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&ScalingPolicy{}, func(obj interface{}) { SetObjectDefaults_ScalingPolicy(obj.(*ScalingPolicy)) })
+	scheme.AddTypeDefaultingFunc(&ScalingPolicyList{}, func(obj interface{}) { SetObjectDefaults_ScalingPolicyList(obj.(*ScalingPolicyList)) })
+	return nil
+}
+
+func SetObjectDefaults_ScalingPolicy(in *ScalingPolicy) {
+	SetDefaults_ScalingPolicySpec(&in.Spec)
+	for i := range in.Spec.Containers {
+		a := &in.Spec.Containers[i]
+		SetDefaults_ContainerScalingRule(a)
+		SetDefaults_ResourceRequirements(&a.Resources)
+		for j := range a.Resources.Limits {
+			b := &a.Resources.Limits[j]
+			SetDefaults_ResourceScalingFunction(&b.Function)
+			if b.Rounding != nil {
+				SetDefaults_OutputRounding(b.Rounding)
+			}
+		}
+		for j := range a.Resources.Requests {
+			b := &a.Resources.Requests[j]
+			SetDefaults_ResourceScalingFunction(&b.Function)
+			if b.Rounding != nil {
+				SetDefaults_OutputRounding(b.Rounding)
+			}
+		}
+	}
+}
+
+func SetObjectDefaults_ScalingPolicyList(in *ScalingPolicyList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_ScalingPolicy(a)
+	}
+}
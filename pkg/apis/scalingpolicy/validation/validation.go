@@ -0,0 +1,339 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation validates ScalingPolicy objects, so that we can reject a malformed policy
+// up front, rather than failing (or misbehaving) when we evaluate it.
+package validation
+
+import (
+	"strings"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/factors"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var supportedSources = []string{
+	factors.InputCores, factors.InputMemory, factors.InputNodes,
+	factors.InputPods, factors.InputContainers, factors.InputServices, factors.InputEndpoints, factors.InputNamespaces,
+}
+
+var supportedRoundingModes = []string{string(scalingpolicy.RoundUp), string(scalingpolicy.RoundDown), string(scalingpolicy.RoundNearest)}
+
+var supportedContainerTypes = []string{string(scalingpolicy.ContainerTypeContainers), string(scalingpolicy.ContainerTypeInitContainers)}
+
+var supportedRequestLimitPolicies = []string{string(scalingpolicy.RaiseLimits), string(scalingpolicy.ClampRequests)}
+
+var supportedCombiners = []string{string(scalingpolicy.CombineSum), string(scalingpolicy.CombineMax), string(scalingpolicy.CombineMin)}
+
+// ValidateScalingPolicy validates the ScalingPolicy, returning the errors with the path to each invalid field
+func ValidateScalingPolicy(policy *scalingpolicy.ScalingPolicy) field.ErrorList {
+	return ValidateScalingPolicySpec(&policy.Spec, field.NewPath("spec"))
+}
+
+// ValidateScalingPolicySpec validates the spec of a ScalingPolicy
+func ValidateScalingPolicySpec(spec *scalingpolicy.ScalingPolicySpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	targetPath := fldPath.Child("scaleTargetRef")
+	if spec.ScaleTargetRef.Kind == "" {
+		allErrs = append(allErrs, field.Required(targetPath.Child("kind"), ""))
+	}
+	if spec.ScaleTargetRef.Name == "" {
+		allErrs = append(allErrs, field.Required(targetPath.Child("name"), ""))
+	}
+	if strings.ToLower(spec.ScaleTargetRef.Kind) == "pod" && (spec.ScaleTargetRef.APIVersion == "" || spec.ScaleTargetRef.APIVersion == "v1") {
+		// The resources of a pod are immutable, so we could only ever report the values
+		allErrs = append(allErrs, field.Invalid(targetPath.Child("kind"), spec.ScaleTargetRef.Kind, "bare pods cannot be targeted because their resources cannot be changed; target the controller of the pod instead"))
+	}
+	if spec.PodSpecPath != "" && spec.ScaleTargetRef.APIVersion == "" {
+		allErrs = append(allErrs, field.Required(targetPath.Child("apiVersion"), "must be specified with podSpecPath"))
+	}
+
+	inputs := make(map[string]bool)
+	for i := range spec.Inputs {
+		in := &spec.Inputs[i]
+		inputPath := fldPath.Child("inputs").Index(i)
+		allErrs = append(allErrs, validateScalingInput(in, inputPath)...)
+		if in.Name != "" {
+			if inputs[in.Name] {
+				allErrs = append(allErrs, field.Duplicate(inputPath.Child("name"), in.Name))
+			}
+			inputs[in.Name] = true
+		}
+	}
+
+	if spec.RequestLimitPolicy != "" && !contains(supportedRequestLimitPolicies, string(spec.RequestLimitPolicy)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("requestLimitPolicy"), string(spec.RequestLimitPolicy), supportedRequestLimitPolicies))
+	}
+
+	if len(spec.Containers) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("containers"), "at least one container must be specified"))
+	}
+	containers := make(map[string]bool)
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		containerPath := fldPath.Child("containers").Index(i)
+
+		if c.Name == "" {
+			allErrs = append(allErrs, field.Required(containerPath.Child("name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(c.Name) {
+				allErrs = append(allErrs, field.Invalid(containerPath.Child("name"), c.Name, msg))
+			}
+			if containers[c.Name] {
+				allErrs = append(allErrs, field.Duplicate(containerPath.Child("name"), c.Name))
+			}
+			containers[c.Name] = true
+		}
+
+		if c.ContainerType != "" && !contains(supportedContainerTypes, string(c.ContainerType)) {
+			allErrs = append(allErrs, field.NotSupported(containerPath.Child("containerType"), string(c.ContainerType), supportedContainerTypes))
+		}
+
+		resourcesPath := containerPath.Child("resources")
+		for j := range c.Resources.Limits {
+			allErrs = append(allErrs, validateResourceScalingRule(&c.Resources.Limits[j], inputs, resourcesPath.Child("limits").Index(j))...)
+		}
+		for j := range c.Resources.Requests {
+			allErrs = append(allErrs, validateResourceScalingRule(&c.Resources.Requests[j], inputs, resourcesPath.Child("requests").Index(j))...)
+		}
+		allErrs = append(allErrs, validateCombiners(c.Resources.Limits, resourcesPath.Child("limits"))...)
+		allErrs = append(allErrs, validateCombiners(c.Resources.Requests, resourcesPath.Child("requests"))...)
+	}
+
+	return allErrs
+}
+
+// ValidateTargetContainers checks that each container in the policy is present in the PodSpec of the target,
+// in the containers or initContainers as selected by the containerType.
+// It is separate from ValidateScalingPolicySpec, because it requires that we read the target.
+func ValidateTargetContainers(spec *scalingpolicy.ScalingPolicySpec, podSpec *v1.PodSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i := range spec.Containers {
+		c := &spec.Containers[i]
+		if c.Name == "" {
+			continue
+		}
+		found := false
+		for _, container := range *c.ContainerType.ContainersIn(podSpec) {
+			if container.Name == c.Name {
+				found = true
+			}
+		}
+		if !found {
+			allErrs = append(allErrs, field.NotFound(fldPath.Child("containers").Index(i).Child("name"), c.Name))
+		}
+	}
+	return allErrs
+}
+
+func validateScalingInput(in *scalingpolicy.ScalingInput, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if in.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+	if !factors.IsBuiltinInput(in.Source) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("source"), in.Source, supportedSources))
+	}
+
+	if in.Nodes != nil {
+		if !factors.IsNodeInput(in.Source) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("nodes"), "nodes can only be filtered for the cores, memory and nodes sources"))
+		}
+		allErrs = append(allErrs, validateLabelSelector(in.Nodes.Selector, fldPath.Child("nodes", "selector"))...)
+	}
+	if in.Objects != nil {
+		if !factors.IsObjectInput(in.Source) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("objects"), "objects can only be filtered for the pods, containers, services, endpoints and namespaces sources"))
+		}
+		allErrs = append(allErrs, validateLabelSelector(in.Objects.Selector, fldPath.Child("objects", "selector"))...)
+		if in.Objects.FieldSelector != "" {
+			if _, err := fields.ParseSelector(in.Objects.FieldSelector); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("objects", "fieldSelector"), in.Objects.FieldSelector, err.Error()))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateLabelSelector(selector *metav1.LabelSelector, fldPath *field.Path) field.ErrorList {
+	if selector == nil {
+		return nil
+	}
+	if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, selector, err.Error())}
+	}
+	return nil
+}
+
+// validateCombiners checks that the rules for the same resource do not declare different combiners,
+// because the rules are combined into a single value for the resource
+func validateCombiners(rules []scalingpolicy.ResourceScalingRule, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	combiners := make(map[string]scalingpolicy.ResourceCombiner)
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Combiner == "" {
+			continue
+		}
+		k := string(rule.Resource)
+		if combiner, found := combiners[k]; found && combiner != rule.Combiner {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("combiner"), string(rule.Combiner), "must match the combiner of the other rules for resource "+k+": "+string(combiner)))
+			continue
+		}
+		combiners[k] = rule.Combiner
+	}
+	return allErrs
+}
+
+// validateResourceScalingRule validates a rule; inputs holds the names of the inputs defined in the policy
+func validateResourceScalingRule(rule *scalingpolicy.ResourceScalingRule, inputs map[string]bool, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if rule.Resource == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("resource"), ""))
+	} else {
+		for _, msg := range validation.IsQualifiedName(string(rule.Resource)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("resource"), string(rule.Resource), msg))
+		}
+	}
+
+	allErrs = append(allErrs, validateResourceScalingFunction(rule.Resource, &rule.Function, inputs, fldPath.Child("function"))...)
+
+	allErrs = append(allErrs, validateQuantity(rule.Resource, rule.Max, fldPath.Child("max"))...)
+	allErrs = append(allErrs, validateQuantity(rule.Resource, rule.Min, fldPath.Child("min"))...)
+	if !rule.Max.IsZero() && rule.Min.Cmp(rule.Max) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("min"), rule.Min.String(), "must be less than or equal to max"))
+	}
+
+	if rule.Rounding != nil {
+		roundingPath := fldPath.Child("rounding")
+		if rule.Rounding.RoundTo.Sign() <= 0 {
+			allErrs = append(allErrs, field.Invalid(roundingPath.Child("roundTo"), rule.Rounding.RoundTo.String(), "must be greater than zero"))
+		} else {
+			allErrs = append(allErrs, validateQuantity(rule.Resource, rule.Rounding.RoundTo, roundingPath.Child("roundTo"))...)
+		}
+		if rule.Rounding.Mode != "" && !contains(supportedRoundingModes, string(rule.Rounding.Mode)) {
+			allErrs = append(allErrs, field.NotSupported(roundingPath.Child("mode"), string(rule.Rounding.Mode), supportedRoundingModes))
+		}
+	}
+
+	if rule.Combiner != "" && !contains(supportedCombiners, string(rule.Combiner)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("combiner"), string(rule.Combiner), supportedCombiners))
+	}
+
+	return allErrs
+}
+
+func validateResourceScalingFunction(resourceName v1.ResourceName, fn *scalingpolicy.ResourceScalingFunction, inputs map[string]bool, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if fn.Input != "" && !inputs[fn.Input] && !factors.IsBuiltinInput(fn.Input) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("input"), fn.Input, "must be a built-in input ("+strings.Join(supportedSources, ", ")+") or the name of an input defined in spec.inputs"))
+	}
+
+	allErrs = append(allErrs, validateQuantity(resourceName, fn.Base, fldPath.Child("base"))...)
+	// A negative slope is allowed: the value shrinks as the input grows, typically with a min floor on the rule
+	allErrs = append(allErrs, validateWholeQuantity(resourceName, fn.Slope, fldPath.Child("slope"))...)
+
+	// Per is serialized as "int"
+	if fn.Per < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("int"), fn.Per, "must be non-negative"))
+	}
+
+	for i := range fn.Segments {
+		segment := &fn.Segments[i]
+		segmentPath := fldPath.Child("segments").Index(i)
+		if segment.At < 0 {
+			allErrs = append(allErrs, field.Invalid(segmentPath.Child("at"), segment.At, "must be non-negative"))
+		}
+		if segment.Every <= 0 {
+			allErrs = append(allErrs, field.Invalid(segmentPath.Child("every"), segment.Every, "must be greater than zero"))
+		}
+	}
+
+	if fn.Smoothing != nil {
+		smoothingPath := fldPath.Child("smoothing")
+		if fn.Smoothing.HalfLifeSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(smoothingPath.Child("halfLifeSeconds"), fn.Smoothing.HalfLifeSeconds, "must be non-negative"))
+		}
+		if fn.Smoothing.Percentile < 0 || fn.Smoothing.Percentile > 100 {
+			allErrs = append(allErrs, field.Invalid(smoothingPath.Child("percentile"), fn.Smoothing.Percentile, "must be between 1 and 100, or 0 to use halfLifeSeconds"))
+		}
+		if fn.Smoothing.WindowSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(smoothingPath.Child("windowSeconds"), fn.Smoothing.WindowSeconds, "must be non-negative"))
+		} else if fn.Smoothing.Percentile > 0 && fn.Smoothing.WindowSeconds == 0 {
+			allErrs = append(allErrs, field.Required(smoothingPath.Child("windowSeconds"), "must be specified with percentile"))
+		}
+	}
+
+	allErrs = append(allErrs, validateDelayScaling(fn.DelayScaleDown, fldPath.Child("delayScaleDown"))...)
+	allErrs = append(allErrs, validateDelayScaling(fn.DelayScaleUp, fldPath.Child("delayScaleUp"))...)
+
+	return allErrs
+}
+
+func validateDelayScaling(delay *scalingpolicy.DelayScaling, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if delay == nil {
+		return allErrs
+	}
+	if delay.Max < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("max"), delay.Max, "must be non-negative"))
+	}
+	if delay.DelaySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("delaySeconds"), delay.DelaySeconds, "must be non-negative"))
+	}
+	return allErrs
+}
+
+// validateQuantity checks that the quantity is non-negative, and is a whole number for resources other than cpu.
+// Kubernetes only accepts fractional values for cpu, so other resources could never be patched to e.g. 500m.
+func validateQuantity(resourceName v1.ResourceName, q resource.Quantity, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if q.Sign() < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, q.String(), "must be non-negative"))
+	}
+	allErrs = append(allErrs, validateWholeQuantity(resourceName, q, fldPath)...)
+	return allErrs
+}
+
+// validateWholeQuantity checks that the quantity is a whole number for resources other than cpu.
+func validateWholeQuantity(resourceName v1.ResourceName, q resource.Quantity, fldPath *field.Path) field.ErrorList {
+	if resourceName != v1.ResourceCPU && q.MilliValue()%1000 != 0 {
+		return field.ErrorList{field.Invalid(fldPath, q.String(), "must be a whole number for resource "+string(resourceName))}
+	}
+	return nil
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
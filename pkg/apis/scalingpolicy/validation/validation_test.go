@@ -0,0 +1,351 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func buildPolicy(mutate func(spec *scalingpolicy.ScalingPolicySpec)) *scalingpolicy.ScalingPolicy {
+	policy := &scalingpolicy.ScalingPolicy{}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	policy.Spec.ScaleTargetRef.Name = "deployment1"
+	policy.Spec.Containers = []scalingpolicy.ContainerScalingRule{
+		{
+			Name: "container1",
+			Resources: scalingpolicy.ResourceRequirements{
+				Limits: []scalingpolicy.ResourceScalingRule{
+					{
+						Resource: "memory",
+						Function: scalingpolicy.ResourceScalingFunction{
+							Input: "nodes",
+							Base:  resource.MustParse("100Mi"),
+							Slope: resource.MustParse("1Mi"),
+						},
+					},
+				},
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(&policy.Spec)
+	}
+	return policy
+}
+
+// firstRule returns the first limits rule of the first container
+func firstRule(spec *scalingpolicy.ScalingPolicySpec) *scalingpolicy.ResourceScalingRule {
+	return &spec.Containers[0].Resources.Limits[0]
+}
+
+func TestValidateScalingPolicy(t *testing.T) {
+	grid := []struct {
+		Name   string
+		Mutate func(spec *scalingpolicy.ScalingPolicySpec)
+		Errors []string
+	}{
+		{
+			Name: "valid",
+		},
+		{
+			Name: "named input",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.Inputs = []scalingpolicy.ScalingInput{{Name: "workers", Source: "nodes"}}
+				firstRule(spec).Function.Input = "workers"
+			},
+		},
+		{
+			Name: "unknown input",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Input = "widgets"
+			},
+			Errors: []string{`spec.containers[0].resources.limits[0].function.input: Invalid value: "widgets"`},
+		},
+		{
+			Name: "missing target",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.ScaleTargetRef.Kind = ""
+				spec.ScaleTargetRef.Name = ""
+			},
+			Errors: []string{
+				"spec.scaleTargetRef.kind: Required value",
+				"spec.scaleTargetRef.name: Required value",
+			},
+		},
+		{
+			Name: "pod target",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.ScaleTargetRef.Kind = "Pod"
+			},
+			Errors: []string{`spec.scaleTargetRef.kind: Invalid value: "Pod": bare pods cannot be targeted because their resources cannot be changed; target the controller of the pod instead`},
+		},
+		{
+			Name: "podSpecPath without apiVersion",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.PodSpecPath = "spec.template.spec"
+			},
+			Errors: []string{"spec.scaleTargetRef.apiVersion: Required value"},
+		},
+		{
+			Name: "same combiner on multiple rules",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Combiner = scalingpolicy.CombineMax
+				second := *firstRule(spec)
+				second.Combiner = ""
+				third := *firstRule(spec)
+				spec.Containers[0].Resources.Limits = append(spec.Containers[0].Resources.Limits, second, third)
+			},
+		},
+		{
+			Name: "different combiners on multiple rules",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Combiner = scalingpolicy.CombineMax
+				second := *firstRule(spec)
+				second.Combiner = scalingpolicy.CombineSum
+				spec.Containers[0].Resources.Limits = append(spec.Containers[0].Resources.Limits, second)
+			},
+			Errors: []string{`spec.containers[0].resources.limits[1].combiner: Invalid value: "sum": must match the combiner of the other rules for resource memory: max`},
+		},
+		{
+			Name: "init container",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.Containers[0].ContainerType = scalingpolicy.ContainerTypeInitContainers
+			},
+		},
+		{
+			Name: "unknown containerType",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.Containers[0].ContainerType = "ephemeralContainers"
+			},
+			Errors: []string{`spec.containers[0].containerType: Unsupported value: "ephemeralContainers"`},
+		},
+		{
+			Name: "clamp requests",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.RequestLimitPolicy = scalingpolicy.ClampRequests
+			},
+		},
+		{
+			Name: "unknown requestLimitPolicy",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.RequestLimitPolicy = "ignore"
+			},
+			Errors: []string{`spec.requestLimitPolicy: Unsupported value: "ignore"`},
+		},
+		{
+			Name: "segment every 0",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Segments = []scalingpolicy.ResourceScalingSegment{{At: 0, Every: 1}, {At: 100, Every: 0}}
+			},
+			Errors: []string{"spec.containers[0].resources.limits[0].function.segments[1].every: Invalid value: 0"},
+		},
+		{
+			Name: "memory segments",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Input = "memory"
+				firstRule(spec).Function.Per = 1 << 30
+				firstRule(spec).Function.Segments = []scalingpolicy.ResourceScalingSegment{{At: 64 << 30, Every: 16 << 30}}
+			},
+		},
+		{
+			Name: "negative per",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Per = -1
+			},
+			Errors: []string{"spec.containers[0].resources.limits[0].function.int: Invalid value: -1"},
+		},
+		{
+			Name: "negative per without slope",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Slope = resource.Quantity{}
+				firstRule(spec).Function.Per = -1
+			},
+			Errors: []string{"spec.containers[0].resources.limits[0].function.int: Invalid value: -1"},
+		},
+		{
+			Name: "zero per without slope",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Slope = resource.Quantity{}
+				firstRule(spec).Function.Per = 0
+			},
+		},
+		{
+			Name: "negative slope",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Slope = resource.MustParse("-1Mi")
+			},
+		},
+		{
+			Name: "fractional memory slope",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Slope = resource.MustParse("500m")
+			},
+			Errors: []string{`spec.containers[0].resources.limits[0].function.slope: Invalid value: "500m": must be a whole number for resource memory`},
+		},
+		{
+			Name: "fractional memory",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Base = resource.MustParse("500m")
+			},
+			Errors: []string{`spec.containers[0].resources.limits[0].function.base: Invalid value: "500m": must be a whole number for resource memory`},
+		},
+		{
+			Name: "fractional cpu",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Resource = "cpu"
+				firstRule(spec).Function.Base = resource.MustParse("500m")
+			},
+		},
+		{
+			Name: "invalid resource name",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Resource = "cpu cores"
+			},
+			Errors: []string{`spec.containers[0].resources.limits[0].resource: Invalid value: "cpu cores"`},
+		},
+		{
+			Name: "min greater than max",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Min = resource.MustParse("200Mi")
+				firstRule(spec).Max = resource.MustParse("100Mi")
+			},
+			Errors: []string{`spec.containers[0].resources.limits[0].min: Invalid value: "200Mi": must be less than or equal to max`},
+		},
+		{
+			Name: "rounding",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Rounding = &scalingpolicy.OutputRounding{Mode: "sideways"}
+			},
+			Errors: []string{
+				`spec.containers[0].resources.limits[0].rounding.roundTo: Invalid value: "0": must be greater than zero`,
+				`spec.containers[0].resources.limits[0].rounding.mode: Unsupported value: "sideways"`,
+			},
+		},
+		{
+			Name: "combiner",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Combiner = "product"
+			},
+			Errors: []string{`spec.containers[0].resources.limits[0].combiner: Unsupported value: "product"`},
+		},
+		{
+			Name: "smoothing",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Smoothing = &scalingpolicy.Smoothing{Percentile: 101}
+			},
+			Errors: []string{
+				"spec.containers[0].resources.limits[0].function.smoothing.percentile: Invalid value: 101",
+				"spec.containers[0].resources.limits[0].function.smoothing.windowSeconds: Required value",
+			},
+		},
+		{
+			Name: "smoothing without percentile",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.Smoothing = &scalingpolicy.Smoothing{HalfLifeSeconds: 60, Percentile: 0}
+			},
+		},
+		{
+			Name: "negative delay",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				firstRule(spec).Function.DelayScaleDown = &scalingpolicy.DelayScaling{DelaySeconds: -1}
+				firstRule(spec).Function.DelayScaleUp = &scalingpolicy.DelayScaling{Max: -1}
+			},
+			Errors: []string{
+				"spec.containers[0].resources.limits[0].function.delayScaleDown.delaySeconds: Invalid value: -1",
+				"spec.containers[0].resources.limits[0].function.delayScaleUp.max: Invalid value: -1",
+			},
+		},
+		{
+			Name: "containers",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.Containers = append(spec.Containers, spec.Containers[0], scalingpolicy.ContainerScalingRule{Name: "Container_3"})
+			},
+			Errors: []string{
+				`spec.containers[1].name: Duplicate value: "container1"`,
+				`spec.containers[2].name: Invalid value: "Container_3"`,
+			},
+		},
+		{
+			Name: "no containers",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.Containers = nil
+			},
+			Errors: []string{"spec.containers: Required value"},
+		},
+		{
+			Name: "inputs",
+			Mutate: func(spec *scalingpolicy.ScalingPolicySpec) {
+				spec.Inputs = []scalingpolicy.ScalingInput{
+					{Name: "workers", Source: "nodes"},
+					{Name: "workers", Source: "widgets"},
+					{Name: "web", Source: "pods", Nodes: &scalingpolicy.NodeFilter{}, Objects: &scalingpolicy.ObjectFilter{FieldSelector: "status.phase"}},
+				}
+			},
+			Errors: []string{
+				`spec.inputs[1].source: Unsupported value: "widgets"`,
+				`spec.inputs[1].name: Duplicate value: "workers"`,
+				"spec.inputs[2].nodes: Forbidden",
+				`spec.inputs[2].objects.fieldSelector: Invalid value: "status.phase"`,
+			},
+		},
+	}
+
+	for _, g := range grid {
+		errs := ValidateScalingPolicy(buildPolicy(g.Mutate))
+		if len(errs) != len(g.Errors) {
+			t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, errs, g.Errors)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.HasPrefix(err.Error(), g.Errors[i]) {
+				t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, err, g.Errors[i])
+			}
+		}
+	}
+}
+
+func TestValidateTargetContainers(t *testing.T) {
+	policy := buildPolicy(nil)
+	podSpec := &v1.PodSpec{Containers: []v1.Container{{Name: "sidecar"}}}
+
+	errs := ValidateTargetContainers(&policy.Spec, podSpec, field.NewPath("spec"))
+	if len(errs) != 1 || errs[0].Error() != `spec.containers[0].name: Not found: "container1"` {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	podSpec.Containers = append(podSpec.Containers, v1.Container{Name: "container1"})
+	if errs := ValidateTargetContainers(&policy.Spec, podSpec, field.NewPath("spec")); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	// An init container is only matched against the initContainers of the pod
+	policy.Spec.Containers[0].ContainerType = scalingpolicy.ContainerTypeInitContainers
+	if errs := ValidateTargetContainers(&policy.Spec, podSpec, field.NewPath("spec")); len(errs) != 1 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	podSpec.InitContainers = []v1.Container{{Name: "container1"}}
+	podSpec.Containers = podSpec.Containers[:1]
+	if errs := ValidateTargetContainers(&policy.Spec, podSpec, field.NewPath("spec")); len(errs) != 0 {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
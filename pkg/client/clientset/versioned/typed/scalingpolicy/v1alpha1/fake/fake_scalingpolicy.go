@@ -98,6 +98,18 @@ func (c *FakeScalingPolicies) Update(scalingPolicy *v1alpha1.ScalingPolicy) (res
 	return obj.(*v1alpha1.ScalingPolicy), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeScalingPolicies) UpdateStatus(scalingPolicy *v1alpha1.ScalingPolicy) (*v1alpha1.ScalingPolicy, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(scalingpoliciesResource, "status", c.ns, scalingPolicy), &v1alpha1.ScalingPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.ScalingPolicy), err
+}
+
 // Delete takes name of the scalingPolicy and deletes it. Returns an error if one occurs.
 func (c *FakeScalingPolicies) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
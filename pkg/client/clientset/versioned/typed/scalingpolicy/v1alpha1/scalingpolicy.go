@@ -35,6 +35,7 @@ type ScalingPoliciesGetter interface {
 type ScalingPolicyInterface interface {
 	Create(*v1alpha1.ScalingPolicy) (*v1alpha1.ScalingPolicy, error)
 	Update(*v1alpha1.ScalingPolicy) (*v1alpha1.ScalingPolicy, error)
+	UpdateStatus(*v1alpha1.ScalingPolicy) (*v1alpha1.ScalingPolicy, error)
 	Delete(name string, options *v1.DeleteOptions) error
 	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
 	Get(name string, options v1.GetOptions) (*v1alpha1.ScalingPolicy, error)
@@ -118,6 +119,22 @@ func (c *scalingPolicies) Update(scalingPolicy *v1alpha1.ScalingPolicy) (result
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *scalingPolicies) UpdateStatus(scalingPolicy *v1alpha1.ScalingPolicy) (result *v1alpha1.ScalingPolicy, err error) {
+	result = &v1alpha1.ScalingPolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("scalingpolicies").
+		Name(scalingPolicy.Name).
+		SubResource("status").
+		Body(scalingPolicy).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the scalingPolicy and deletes it. Returns an error if one occurs.
 func (c *scalingPolicies) Delete(name string, options *v1.DeleteOptions) error {
 	return c.client.Delete().
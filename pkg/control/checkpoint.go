@@ -0,0 +1,105 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/scaling"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// checkpointKey is the key in the ConfigMap data under which we store the checkpoint
+const checkpointKey = "checkpoint.json"
+
+// CheckpointStore persists the evaluator state for each policy
+type CheckpointStore interface {
+	// Load returns the checkpoint for the policy, or nil if there is none
+	Load(policy *scalingpolicy.ScalingPolicy) (*scaling.Checkpoint, error)
+	// Save replaces the checkpoint for the policy
+	Save(policy *scalingpolicy.ScalingPolicy, checkpoint *scaling.Checkpoint) error
+}
+
+// configMapCheckpointStore stores the checkpoint for each policy in a ConfigMap alongside the policy.
+// The ConfigMap is owned by the policy, so it is garbage collected when the policy is deleted.
+type configMapCheckpointStore struct {
+	client kubernetes.Interface
+}
+
+var _ CheckpointStore = &configMapCheckpointStore{}
+
+// NewConfigMapCheckpointStore builds a CheckpointStore that stores checkpoints in ConfigMaps
+func NewConfigMapCheckpointStore(client kubernetes.Interface) CheckpointStore {
+	return &configMapCheckpointStore{client: client}
+}
+
+// checkpointName returns the name of the ConfigMap holding the checkpoint for the policy
+func checkpointName(policy *scalingpolicy.ScalingPolicy) string {
+	return policy.Name + "-scaler-checkpoint"
+}
+
+func (s *configMapCheckpointStore) Load(policy *scalingpolicy.ScalingPolicy) (*scaling.Checkpoint, error) {
+	name := checkpointName(policy)
+	cm, err := s.client.CoreV1().ConfigMaps(policy.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading configmap %s/%s: %v", policy.Namespace, name, err)
+	}
+
+	data := cm.Data[checkpointKey]
+	if data == "" {
+		return nil, nil
+	}
+
+	checkpoint := &scaling.Checkpoint{}
+	if err := json.Unmarshal([]byte(data), checkpoint); err != nil {
+		return nil, fmt.Errorf("error parsing checkpoint in configmap %s/%s: %v", policy.Namespace, name, err)
+	}
+	return checkpoint, nil
+}
+
+func (s *configMapCheckpointStore) Save(policy *scalingpolicy.ScalingPolicy, checkpoint *scaling.Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("error serializing checkpoint: %v", err)
+	}
+
+	name := checkpointName(policy)
+	configMaps := s.client.CoreV1().ConfigMaps(policy.Namespace)
+	cm, err := configMaps.Get(name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("error reading configmap %s/%s: %v", policy.Namespace, name, err)
+		}
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: policy.Namespace,
+				Name:      name,
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(policy, scalingpolicy.SchemeGroupVersion.WithKind("ScalingPolicy")),
+				},
+			},
+			Data: map[string]string{checkpointKey: string(data)},
+		}
+		if _, err := configMaps.Create(cm); err != nil {
+			return fmt.Errorf("error creating configmap %s/%s: %v", policy.Namespace, name, err)
+		}
+		return nil
+	}
+
+	cm = cm.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[checkpointKey] = string(data)
+	if _, err := configMaps.Update(cm); err != nil {
+		return fmt.Errorf("error updating configmap %s/%s: %v", policy.Namespace, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package control
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/cmd/scaler/options"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"github.com/justinsb/scaler/pkg/scaling"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// checkpointRule is the key of the rule built by buildCheckpointPolicy in a checkpoint
+const checkpointRule = "container1.limits.memory[0]"
+
+func buildCheckpointPolicy(slope string) *scalingpolicy.ScalingPolicy {
+	policy := buildPolicyUsingInput("nodes")
+	policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1", UID: types.UID("uid1")}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	policy.Spec.Containers[0].Resources.Limits[0].Function.Slope = resource.MustParse(slope)
+	return policy
+}
+
+// checkpointJSON returns the checkpoint as JSON, so that we can compare checkpoints (which hold times and quantities)
+func checkpointJSON(t *testing.T, checkpoint *scaling.Checkpoint) string {
+	if checkpoint == nil {
+		return ""
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		t.Fatalf("error serializing checkpoint: %v", err)
+	}
+	return string(data)
+}
+
+func buildCheckpointConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "policy1-scaler-checkpoint"},
+		Data:       data,
+	}
+}
+
+func TestConfigMapCheckpointStoreLoad(t *testing.T) {
+	grid := []struct {
+		Name     string
+		Existing *corev1.ConfigMap
+		Expected *scaling.Checkpoint
+		Error    string
+	}{
+		{
+			Name: "missing configmap",
+		},
+		{
+			Name:     "no checkpoint in configmap",
+			Existing: buildCheckpointConfigMap(nil),
+		},
+		{
+			Name:     "checkpoint",
+			Existing: buildCheckpointConfigMap(map[string]string{checkpointKey: `{"timestamp":"2018-01-02T03:04:05Z","rules":{"container1.limits.memory[0]":{"lastScaleUp":"2018-01-02T03:00:00Z"}}}`}),
+			Expected: &scaling.Checkpoint{
+				Timestamp: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+				Rules: map[string]*scaling.RuleCheckpoint{
+					checkpointRule: {LastScaleUp: time.Date(2018, 1, 2, 3, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+		{
+			Name:     "corrupt checkpoint",
+			Existing: buildCheckpointConfigMap(map[string]string{checkpointKey: `{"timestamp":`}),
+			Error:    "error parsing checkpoint in configmap ns1/policy1-scaler-checkpoint",
+		},
+	}
+
+	for _, g := range grid {
+		var objects []runtime.Object
+		if g.Existing != nil {
+			objects = append(objects, g.Existing)
+		}
+		store := NewConfigMapCheckpointStore(fake.NewSimpleClientset(objects...))
+
+		actual, err := store.Load(buildCheckpointPolicy("1Mi"))
+		if g.Error != "" {
+			if err == nil || !strings.Contains(err.Error(), g.Error) {
+				t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, err, g.Error)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test failure\nname=%s\nunexpected error %v", g.Name, err)
+			continue
+		}
+		if a, e := checkpointJSON(t, actual), checkpointJSON(t, g.Expected); a != e {
+			t.Errorf("test failure\nname=%s\n  actual=%s\nexpected=%s", g.Name, a, e)
+		}
+	}
+}
+
+func TestConfigMapCheckpointStoreSave(t *testing.T) {
+	policy := buildCheckpointPolicy("1Mi")
+	ownerReference := *metav1.NewControllerRef(policy, scalingpolicy.SchemeGroupVersion.WithKind("ScalingPolicy"))
+
+	grid := []struct {
+		Name     string
+		Existing *corev1.ConfigMap
+		// ExpectedVerb is the verb we expect to use to write the configmap
+		ExpectedVerb string
+		// ExpectedOwners is the expected owner references of the configmap
+		ExpectedOwners []metav1.OwnerReference
+		// ExpectedData is the expected data in the configmap, other than the checkpoint
+		ExpectedData map[string]string
+	}{
+		{
+			Name:           "create",
+			ExpectedVerb:   "create",
+			ExpectedOwners: []metav1.OwnerReference{ownerReference},
+		},
+		{
+			Name:         "update",
+			Existing:     buildCheckpointConfigMap(map[string]string{checkpointKey: `{}`, "other": "value"}),
+			ExpectedVerb: "update",
+			ExpectedData: map[string]string{"other": "value"},
+		},
+		{
+			Name:         "update without data",
+			Existing:     buildCheckpointConfigMap(nil),
+			ExpectedVerb: "update",
+		},
+	}
+
+	checkpoint := &scaling.Checkpoint{
+		Timestamp: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+		Rules: map[string]*scaling.RuleCheckpoint{
+			checkpointRule: {LastScaleUp: time.Date(2018, 1, 2, 3, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, g := range grid {
+		var objects []runtime.Object
+		if g.Existing != nil {
+			objects = append(objects, g.Existing)
+		}
+		client := fake.NewSimpleClientset(objects...)
+		store := NewConfigMapCheckpointStore(client)
+
+		if err := store.Save(policy, checkpoint); err != nil {
+			t.Errorf("test failure\nname=%s\nunexpected error %v", g.Name, err)
+			continue
+		}
+
+		var verbs []string
+		for _, action := range client.Actions() {
+			verbs = append(verbs, action.GetVerb())
+		}
+		if expected := []string{"get", g.ExpectedVerb}; !reflect.DeepEqual(verbs, expected) {
+			t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, verbs, expected)
+		}
+
+		cm, err := client.CoreV1().ConfigMaps("ns1").Get("policy1-scaler-checkpoint", metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("test failure\nname=%s\nerror reading configmap: %v", g.Name, err)
+			continue
+		}
+		if !reflect.DeepEqual(cm.OwnerReferences, g.ExpectedOwners) {
+			t.Errorf("test failure\nname=%s\n  actual=%+v\nexpected=%+v", g.Name, cm.OwnerReferences, g.ExpectedOwners)
+		}
+
+		if a, e := cm.Data[checkpointKey], checkpointJSON(t, checkpoint); a != e {
+			t.Errorf("test failure\nname=%s\n  actual=%s\nexpected=%s", g.Name, a, e)
+		}
+
+		delete(cm.Data, checkpointKey)
+		if len(cm.Data) != 0 || len(g.ExpectedData) != 0 {
+			if !reflect.DeepEqual(cm.Data, g.ExpectedData) {
+				t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, cm.Data, g.ExpectedData)
+			}
+		}
+	}
+}
+
+// TestSaveAndRestoreCheckpoints checks that the checkpoints saved by one State are restored by the next,
+// as happens when the scaler restarts or another replica becomes the leader
+func TestSaveAndRestoreCheckpoints(t *testing.T) {
+	grid := []struct {
+		Name string
+		// Slope is the slope of the policy after the restart; the checkpoint is saved with a slope of 1Mi
+		Slope string
+		// Corrupt replaces the saved checkpoint with invalid JSON
+		Corrupt  bool
+		Expected bool
+	}{
+		{
+			Name:     "restored",
+			Slope:    "1Mi",
+			Expected: true,
+		},
+		{
+			Name:  "spec changed",
+			Slope: "2Mi",
+		},
+		{
+			Name:    "corrupt checkpoint",
+			Slope:   "1Mi",
+			Corrupt: true,
+		},
+	}
+
+	key := types.NamespacedName{Namespace: "ns1", Name: "policy1"}
+	for _, g := range grid {
+		fakeClock := clock.NewFakeClock(time.Now())
+		client := fake.NewSimpleClientset()
+		inputs := map[string]float64{"nodes": 10}
+
+		buildState := func() *State {
+			state, err := NewState(fakeClock, target.NewSimulationTarget(), static.NewStaticFactors(fakeClock, inputs), options.NewAutoScalerConfig())
+			if err != nil {
+				t.Fatalf("error building state: %v", err)
+			}
+			state.checkpoints = NewConfigMapCheckpointStore(client)
+			return state
+		}
+
+		state1 := buildState()
+		state1.upsert(buildCheckpointPolicy("1Mi"), nil)
+		if err := state1.makeObservation(); err != nil {
+			t.Fatalf("error observing: %v", err)
+		}
+		if err := state1.saveCheckpoints(); err != nil {
+			t.Fatalf("error saving checkpoints: %v", err)
+		}
+
+		if g.Corrupt {
+			cm := buildCheckpointConfigMap(map[string]string{checkpointKey: "{"})
+			if _, err := client.CoreV1().ConfigMaps("ns1").Update(cm); err != nil {
+				t.Fatalf("error updating configmap: %v", err)
+			}
+		}
+
+		// The policy is restored when it is first added to the new State
+		state2 := buildState()
+		state2.upsert(buildCheckpointPolicy(g.Slope), nil)
+
+		_, checkpoint := state2.policies[key].checkpoint()
+		actual := checkpoint != nil && len(checkpoint.Rules[checkpointRule].Target.Samples) != 0
+		if actual != g.Expected {
+			t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, actual, g.Expected)
+		}
+	}
+}
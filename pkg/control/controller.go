@@ -22,12 +22,15 @@ import (
 
 	"github.com/golang/glog"
 	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/apis/scalingpolicy/validation"
 	clientset "github.com/justinsb/scaler/pkg/client/clientset/versioned"
 	informers "github.com/justinsb/scaler/pkg/client/informers/externalversions"
 	scalingpolicylister "github.com/justinsb/scaler/pkg/client/listers/scalingpolicy/v1alpha1"
 	"github.com/justinsb/scaler/pkg/debug"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	kubeinformers "k8s.io/client-go/informers"
@@ -115,6 +118,10 @@ func NewController(
 		recorder:              recorder,
 		state:                 state,
 	}
+	state.recorder = recorder
+	if state.options.CheckpointPeriod != 0 {
+		state.checkpoints = NewConfigMapCheckpointStore(kubeClient)
+	}
 
 	glog.Info("Setting up event handlers")
 	// Set up an event handler for when ScalingPolicy resources change
@@ -184,6 +191,29 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 	return nil
 }
 
+// RunLeader applies the policies to their targets and reports their status, until stopCh is closed.
+// When we are running with leader election, it must only be called while we are the leader.
+func (c *Controller) RunLeader(stopCh <-chan struct{}) {
+	if ok := cache.WaitForCacheSync(stopCh, c.scalingPoliciesSynced); !ok {
+		glog.Warningf("failed to wait for caches to sync")
+		return
+	}
+
+	glog.Info("Starting to apply policies")
+	c.state.RunApply(stopCh)
+
+	// Only the leader writes the status; StopLeading waits for us to stop
+	c.state.RunWhileLeading(c.updateStatuses, c.state.options.UpdatePeriod, stopCh)
+
+	<-stopCh
+	glog.Info("Stopped applying policies")
+}
+
+// Recorder returns the event recorder used by the controller
+func (c *Controller) Recorder() record.EventRecorder {
+	return c.recorder
+}
+
 // runWorker is a long-running function that will continually call the
 // processNextWorkItem function in order to read and process a message on the
 // workqueue.
@@ -273,7 +303,13 @@ func (c *Controller) syncHandler(key string) error {
 	}
 
 	glog.V(8).Infof("syncing scaling policy: %v", debug.Print(scalingPolicy))
-	c.state.upsert(scalingPolicy)
+
+	// We don't return an error for an invalid policy, because retrying won't help; it will be synced again when it is fixed
+	validationErrors := validation.ValidateScalingPolicy(scalingPolicy)
+	if len(validationErrors) != 0 {
+		glog.Warningf("scaling policy %s/%s is not valid: %v", namespace, name, validationErrors.ToAggregate())
+	}
+	c.state.upsert(scalingPolicy, validationErrors)
 	return nil
 
 	//deploymentName := scalingPolicy.Spec.DeploymentName
@@ -333,19 +369,39 @@ func (c *Controller) syncHandler(key string) error {
 	//return nil
 }
 
-//func (c *Controller) updateScalingPoliciestatus(scalingPolicy *scalerv1alpha1.ScalingPolicy, deployment *appsv1beta2.Deployment) error {
-//	// NEVER modify objects from the store. It's a read-only, local cache.
-//	// You can use DeepCopy() to make a deep copy of original object and modify this copy
-//	// Or create a copy manually for better performance
-//	scalingPolicyCopy := scalingPolicy.DeepCopy()
-//	scalingPolicyCopy.Status.AvailableReplicas = deployment.Status.AvailableReplicas
-//	// Until #38113 is merged, we must use Update instead of UpdateStatus to
-//	// update the Status block of the ScalingPolicy resource. UpdateStatus will not
-//	// allow changes to the Spec of the resource, which is ideal for ensuring
-//	// nothing other than resource status has been updated.
-//	_, err := c.scalerclientset.ScalingpolicyV1alpha1().ScalingPolicies(scalingPolicy.Namespace).Update(scalingPolicyCopy)
-//	return err
-//}
+// updateStatuses writes the status block of each ScalingPolicy resource, where it has changed
+func (c *Controller) updateStatuses() {
+	for key, status := range c.state.statuses() {
+		if err := c.updateScalingPolicyStatus(key, status); err != nil {
+			glog.Warningf("error updating status of scaling policy %s: %v", key, err)
+		}
+	}
+}
+
+func (c *Controller) updateScalingPolicyStatus(key types.NamespacedName, status *scalingpolicy.ScalingPolicyStatus) error {
+	scalingPolicy, err := c.scalingPoliciesLister.ScalingPolicies(key.Namespace).Get(key.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	// We don't write the status just because the inputs were observed again; InputsTime reflects
+	// the observation time of the values we last wrote
+	existing := scalingPolicy.Status.DeepCopy()
+	existing.InputsTime = status.InputsTime
+	if equality.Semantic.DeepEqual(existing, status) {
+		return nil
+	}
+
+	// NEVER modify objects from the store. It's a read-only, local cache.
+	// You can use DeepCopy() to make a deep copy of original object and modify this copy
+	scalingPolicyCopy := scalingPolicy.DeepCopy()
+	scalingPolicyCopy.Status = *status
+	_, err = c.scalerClient.ScalingpolicyV1alpha1().ScalingPolicies(key.Namespace).UpdateStatus(scalingPolicyCopy)
+	return err
+}
 
 // enqueueScalingPolicy takes a ScalingPolicy resource and converts it into a namespace/name
 // string which is then put onto the work queue. This method should *not* be
@@ -0,0 +1,159 @@
+package control
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// ScaledUp is the Event reason when we patch the target to increase resources
+	ScaledUp = "ScaledUp"
+	// ScaledDown is the Event reason when we patch the target to decrease resources
+	ScaledDown = "ScaledDown"
+	// ScaleDownDeferred is the Event reason when the target is over the computed value, but delayScaleDown holds the current value
+	ScaleDownDeferred = "ScaleDownDeferred"
+	// TargetNotFound is the Event reason when the target of the policy does not exist
+	TargetNotFound = "TargetNotFound"
+	// ContainerNotFound is the Event reason when a container named in the policy is not in the target
+	ContainerNotFound = "ContainerNotFound"
+	// PatchFailed is the Event reason when we could not patch the target
+	PatchFailed = "PatchFailed"
+)
+
+// resourceChange describes a change (or a deferred change) to a single resource of a container
+type resourceChange struct {
+	container    string
+	resourceType string
+	resource     v1.ResourceName
+
+	// from is the current value, or nil if the resource is not set
+	from *resource.Quantity
+	to   resource.Quantity
+}
+
+// key identifies the resource, e.g. container1 limits.cpu
+func (c *resourceChange) key() string {
+	return c.container + " " + c.resourceType + "." + string(c.resource)
+}
+
+func (c *resourceChange) String() string {
+	from := "unset"
+	if c.from != nil {
+		from = c.from.String()
+	}
+	return fmt.Sprintf("%s %s -> %s", c.key(), from, c.to.String())
+}
+
+// isIncrease returns true if the change sets or increases the resource
+func (c *resourceChange) isIncrease() bool {
+	return c.from == nil || c.to.Cmp(*c.from) > 0
+}
+
+// buildResourceChanges returns the changes from the actual PodSpec, for each resource set in the updated PodSpec,
+// sorted for stable messages.  If onlyDecreases is set, we only return resources that are lower in updated.
+func buildResourceChanges(actual *v1.PodSpec, updated *v1.PodSpec, onlyDecreases bool) []resourceChange {
+	var changes []resourceChange
+	if updated == nil {
+		return nil
+	}
+	for _, containerType := range scalingpolicy.ContainerTypes {
+		containers := *containerType.ContainersIn(updated)
+		for i := range containers {
+			c := &containers[i]
+			var current v1.ResourceRequirements
+			if actual != nil {
+				if a := findContainer(actual, containerType, c.Name); a != nil {
+					current = a.Resources
+				}
+			}
+			changes = appendResourceChanges(changes, c.Name, "limits", current.Limits, c.Resources.Limits, onlyDecreases)
+			changes = appendResourceChanges(changes, c.Name, "requests", current.Requests, c.Resources.Requests, onlyDecreases)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].key() < changes[j].key()
+	})
+	return changes
+}
+
+func appendResourceChanges(changes []resourceChange, container string, resourceType string, current v1.ResourceList, updated v1.ResourceList, onlyDecreases bool) []resourceChange {
+	for k, v := range updated {
+		change := resourceChange{
+			container:    container,
+			resourceType: resourceType,
+			resource:     k,
+			to:           v,
+		}
+		if q, found := current[k]; found {
+			change.from = &q
+		}
+		if onlyDecreases && change.isIncrease() {
+			continue
+		}
+		if change.from != nil && change.from.Cmp(change.to) == 0 {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// findContainer returns the container of the container type with the specified name, or nil if not found
+func findContainer(podSpec *v1.PodSpec, containerType scalingpolicy.ContainerType, name string) *v1.Container {
+	containers := *containerType.ContainersIn(podSpec)
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+// formatChanges formats the changes for an event message
+func formatChanges(changes []resourceChange) string {
+	var s []string
+	for i := range changes {
+		s = append(s, changes[i].String())
+	}
+	return strings.Join(s, ", ")
+}
+
+// formatInputs formats the input values that drove a decision, for an event message
+func formatInputs(inputs []scalingpolicy.InputValue) string {
+	if len(inputs) == 0 {
+		return "no inputs observed"
+	}
+	var s []string
+	for _, input := range inputs {
+		s = append(s, input.Name+"="+input.Value.String())
+	}
+	return "inputs " + strings.Join(s, ", ")
+}
+
+// recordEvent records an event on the ScalingPolicy, and on the target (if ref is not nil),
+// so that the event is shown when describing either object.
+func (s *PolicyState) recordEvent(ref *target.Ref, eventType, reason, message string) {
+	if s.parent.recorder == nil {
+		return
+	}
+
+	s.parent.eventf(s.policy, eventType, reason, "%s", message)
+
+	if ref == nil {
+		return
+	}
+	// We read the target so that the event includes the UID, which kubectl describe uses to find events
+	objectRef, err := s.target.ReadObjectReference(ref)
+	if err != nil {
+		glog.Warningf("unable to record %s event on %s: %v", reason, ref, err)
+		return
+	}
+	s.parent.eventf(objectRef, eventType, reason, "%s (ScalingPolicy %s)", message, s.policy.Name)
+}
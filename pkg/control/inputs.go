@@ -0,0 +1,83 @@
+package control
+
+import (
+	"fmt"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/factors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// buildInputs converts the named inputs defined in the policy, and verifies that
+// every input used by a rule is either a built-in input or a named input.
+func buildInputs(policy *scalingpolicy.ScalingPolicy) (map[string]*factors.Input, error) {
+	inputs := make(map[string]*factors.Input)
+	for i := range policy.Spec.Inputs {
+		in := &policy.Spec.Inputs[i]
+
+		if in.Name == "" {
+			return nil, fmt.Errorf("input name is required")
+		}
+		if inputs[in.Name] != nil {
+			return nil, fmt.Errorf("duplicate input %q", in.Name)
+		}
+		if !factors.IsBuiltinInput(in.Source) {
+			return nil, fmt.Errorf("unknown source %q for input %q", in.Source, in.Name)
+		}
+
+		input := &factors.Input{Source: in.Source}
+		if in.Nodes != nil {
+			if !factors.IsNodeInput(in.Source) {
+				return nil, fmt.Errorf("input %q cannot filter nodes for source %q", in.Name, in.Source)
+			}
+			filter := &factors.NodeFilter{
+				Tolerations: in.Nodes.Tolerations,
+				ReadyOnly:   in.Nodes.ReadyOnly,
+			}
+			selector, err := buildLabelSelector(in.Nodes.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid node selector for input %q: %v", in.Name, err)
+			}
+			filter.Selector = selector
+			input.Nodes = filter
+		}
+		if in.Objects != nil {
+			if !factors.IsObjectInput(in.Source) {
+				return nil, fmt.Errorf("input %q cannot filter objects for source %q", in.Name, in.Source)
+			}
+			filter := &factors.ObjectFilter{}
+			selector, err := buildLabelSelector(in.Objects.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector for input %q: %v", in.Name, err)
+			}
+			filter.Selector = selector
+			if in.Objects.FieldSelector != "" {
+				fieldSelector, err := fields.ParseSelector(in.Objects.FieldSelector)
+				if err != nil {
+					return nil, fmt.Errorf("invalid field selector for input %q: %v", in.Name, err)
+				}
+				filter.FieldSelector = fieldSelector
+			}
+			input.Objects = filter
+		}
+		inputs[in.Name] = input
+	}
+
+	for _, name := range policyInputs(policy) {
+		if inputs[name] == nil && !factors.IsBuiltinInput(name) {
+			return nil, fmt.Errorf("unknown input %q", name)
+		}
+	}
+
+	return inputs, nil
+}
+
+// buildLabelSelector converts the LabelSelector, treating nil as selecting everything
+func buildLabelSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
@@ -0,0 +1,112 @@
+package control
+
+import (
+	"testing"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func buildPolicyUsingInput(input string, inputs ...scalingpolicy.ScalingInput) *scalingpolicy.ScalingPolicy {
+	return &scalingpolicy.ScalingPolicy{
+		Spec: scalingpolicy.ScalingPolicySpec{
+			Inputs: inputs,
+			Containers: []scalingpolicy.ContainerScalingRule{
+				{
+					Name: "container1",
+					Resources: scalingpolicy.ResourceRequirements{
+						Limits: []scalingpolicy.ResourceScalingRule{
+							{
+								Resource: "memory",
+								Function: scalingpolicy.ResourceScalingFunction{Input: input},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildInputs(t *testing.T) {
+	grid := []struct {
+		Name   string
+		Policy *scalingpolicy.ScalingPolicy
+		Valid  bool
+	}{
+		{
+			Name:   "built-in node input",
+			Policy: buildPolicyUsingInput("cores"),
+			Valid:  true,
+		},
+		{
+			Name:   "built-in object input",
+			Policy: buildPolicyUsingInput("services"),
+			Valid:  true,
+		},
+		{
+			Name:   "unknown input",
+			Policy: buildPolicyUsingInput("widgets"),
+			Valid:  false,
+		},
+		{
+			Name: "named input",
+			Policy: buildPolicyUsingInput("webPods", scalingpolicy.ScalingInput{
+				Name:   "webPods",
+				Source: "pods",
+				Objects: &scalingpolicy.ObjectFilter{
+					Selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					FieldSelector: "status.phase=Running",
+				},
+			}),
+			Valid: true,
+		},
+		{
+			Name:   "named input with unknown source",
+			Policy: buildPolicyUsingInput("x", scalingpolicy.ScalingInput{Name: "x", Source: "widgets"}),
+			Valid:  false,
+		},
+		{
+			Name: "node filter on object input",
+			Policy: buildPolicyUsingInput("x", scalingpolicy.ScalingInput{
+				Name:   "x",
+				Source: "pods",
+				Nodes:  &scalingpolicy.NodeFilter{ReadyOnly: true},
+			}),
+			Valid: false,
+		},
+		{
+			Name: "invalid field selector",
+			Policy: buildPolicyUsingInput("x", scalingpolicy.ScalingInput{
+				Name:    "x",
+				Source:  "pods",
+				Objects: &scalingpolicy.ObjectFilter{FieldSelector: "status.phase"},
+			}),
+			Valid: false,
+		},
+		{
+			Name: "duplicate input",
+			Policy: buildPolicyUsingInput("x",
+				scalingpolicy.ScalingInput{Name: "x", Source: "pods"},
+				scalingpolicy.ScalingInput{Name: "x", Source: "nodes"},
+			),
+			Valid: false,
+		},
+	}
+
+	for _, g := range grid {
+		inputs, err := buildInputs(g.Policy)
+		if g.Valid {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", g.Name, err)
+			}
+			for _, in := range g.Policy.Spec.Inputs {
+				if inputs[in.Name] == nil {
+					t.Errorf("%s: input %q not built", g.Name, in.Name)
+				}
+			}
+		} else if err == nil {
+			t.Errorf("%s: expected error", g.Name)
+		}
+	}
+}
@@ -17,23 +17,7 @@ var _ graph.Graphable = &PolicyState{}
 func (s *PolicyState) ListGraphs() ([]*graph.Metadata, error) {
 	var metadata []*graph.Metadata
 
-	inputs := make(map[string]bool)
-	for _, c := range s.policy.Spec.Containers {
-		for _, r := range c.Resources.Limits {
-			fn := r.Function
-			if fn.Input != "" {
-				inputs[fn.Input] = true
-			}
-		}
-		for _, r := range c.Resources.Requests {
-			fn := r.Function
-			if fn.Input != "" {
-				inputs[fn.Input] = true
-			}
-		}
-	}
-
-	for input := range inputs {
+	for _, input := range policyInputs(s.policy) {
 		{
 			g := &graph.Metadata{}
 			g.Key = input
@@ -91,8 +75,9 @@ func (s *PolicyState) Query() *PolicyInfo {
 
 	info := &PolicyInfo{
 		Policy: s.policy,
-		//State:  s.evaluator.Query(),
+		State:  s.evaluator.Query(),
 	}
+	info.State.LatestActual = s.actual
 	return info
 }
 
@@ -114,15 +99,19 @@ func (s *PolicyState) ListSimulations() ([]*simulate.Metadata, error) {
 }
 
 type StateInfo struct {
+	Leader   *http.LeaderInfo       `json:"leader"`
 	Policies map[string]*PolicyInfo `json:"policies"`
 }
 
 // Query returns the current state, for reporting e.g. via the /statz endpoint
 func (c *State) Query() interface{} {
+	leader := c.LeaderInfo()
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	info := &StateInfo{
+		Leader:   leader,
 		Policies: make(map[string]*PolicyInfo),
 	}
 	for k, v := range c.policies {
@@ -0,0 +1,198 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakecluster simulates a cluster for tests, using the client-go fakes: discovery is served by the
+// fake discovery client, and objects are read & patched through the fake dynamic client, with reactors that behave
+// like the apiserver (including for API versions that are newer than the client library).
+package fakecluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Cluster is a fake cluster, serving discovery information and a set of objects
+type Cluster struct {
+	// Discovery serves discovery information
+	Discovery *discoveryfake.FakeDiscovery
+
+	// ClientPool serves the objects
+	ClientPool *dynamicfake.FakeClientPool
+
+	mutex sync.Mutex
+
+	// objects holds the JSON for each object, keyed by the URL path
+	objects map[string][]byte
+
+	// Patches records all the patches we have received, in order
+	Patches []Patch
+}
+
+// Patch records a patch request
+type Patch struct {
+	Path string
+	Body []byte
+}
+
+// NewCluster builds a new fake cluster, which initially serves no resources
+func NewCluster() *Cluster {
+	c := &Cluster{
+		Discovery:  &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{}},
+		ClientPool: &dynamicfake.FakeClientPool{},
+		objects:    make(map[string][]byte),
+	}
+	c.ClientPool.AddReactor("get", "*", c.reactGet)
+	c.ClientPool.AddReactor("patch", "*", c.reactPatch)
+	return c
+}
+
+// AddResource registers a namespaced resource in discovery
+func (c *Cluster) AddResource(groupVersion string, resource string, kind string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	res := metav1.APIResource{
+		Name:       resource,
+		Namespaced: true,
+		Kind:       kind,
+		Verbs:      metav1.Verbs{"get", "list", "watch", "patch", "update"},
+	}
+	for _, resourceList := range c.Discovery.Resources {
+		if resourceList.GroupVersion == groupVersion {
+			resourceList.APIResources = append(resourceList.APIResources, res)
+			return
+		}
+	}
+	c.Discovery.Resources = append(c.Discovery.Resources, &metav1.APIResourceList{
+		GroupVersion: groupVersion,
+		APIResources: []metav1.APIResource{res},
+	})
+}
+
+// RemoveGroupVersion removes a groupVersion from discovery, simulating an upgrade of the cluster
+func (c *Cluster) RemoveGroupVersion(groupVersion string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var resourceLists []*metav1.APIResourceList
+	for _, resourceList := range c.Discovery.Resources {
+		if resourceList.GroupVersion != groupVersion {
+			resourceLists = append(resourceLists, resourceList)
+		}
+	}
+	c.Discovery.Resources = resourceLists
+}
+
+// DiscoveryCalls returns the number of discovery queries we have received
+func (c *Cluster) DiscoveryCalls() int {
+	n := 0
+	for _, action := range c.Discovery.Actions() {
+		if action.GetVerb() == "get" && action.GetResource().Resource == "resource" {
+			n++
+		}
+	}
+	return n
+}
+
+// AddObject stores an object, which will be returned for get requests
+func (c *Cluster) AddObject(groupVersion string, resource string, namespace string, name string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("error serializing object: %v", err)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.objects[ObjectPath(groupVersion, resource, namespace, name)] = data
+	return nil
+}
+
+// ObjectPath returns the URL path for an object
+func ObjectPath(groupVersion string, resource string, namespace string, name string) string {
+	prefix := "/apis/" + groupVersion
+	if !strings.Contains(groupVersion, "/") {
+		prefix = "/api/" + groupVersion
+	}
+	return prefix + "/namespaces/" + namespace + "/" + resource + "/" + name
+}
+
+func (c *Cluster) reactGet(action k8stesting.Action) (bool, runtime.Object, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	obj, err := c.findObject(action.GetResource(), action.GetNamespace(), action.(k8stesting.GetAction).GetName())
+	return true, obj, err
+}
+
+func (c *Cluster) reactPatch(action k8stesting.Action) (bool, runtime.Object, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	patch := action.(k8stesting.PatchAction)
+	obj, err := c.findObject(action.GetResource(), action.GetNamespace(), patch.GetName())
+	if err != nil {
+		return true, nil, err
+	}
+
+	c.Patches = append(c.Patches, Patch{
+		Path: ObjectPath(action.GetResource().GroupVersion().String(), action.GetResource().Resource, action.GetNamespace(), patch.GetName()),
+		Body: patch.GetPatch(),
+	})
+	// We return the object as-is
+	return true, obj, nil
+}
+
+// findObject returns the stored object, or a NotFound error.
+// Like the real apiserver, we don't serve objects for group versions that have been removed.
+func (c *Cluster) findObject(gvr schema.GroupVersionResource, namespace string, name string) (runtime.Object, error) {
+	data := c.objects[ObjectPath(gvr.GroupVersion().String(), gvr.Resource, namespace, name)]
+	if data == nil || !c.serves(gvr) {
+		return nil, errors.NewNotFound(gvr.GroupResource(), name)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(data, &obj.Object); err != nil {
+		return nil, fmt.Errorf("error parsing object: %v", err)
+	}
+	return obj, nil
+}
+
+// serves returns true if the resource is in discovery
+func (c *Cluster) serves(gvr schema.GroupVersionResource) bool {
+	for _, resourceList := range c.Discovery.Resources {
+		if resourceList.GroupVersion != gvr.GroupVersion().String() {
+			continue
+		}
+		for _, res := range resourceList.APIResources {
+			if res.Name == gvr.Resource {
+				return true
+			}
+		}
+	}
+	return false
+}
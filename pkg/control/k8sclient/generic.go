@@ -0,0 +1,230 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultPodSpecPath is the path to the PodSpec in objects that embed a pod template
+const DefaultPodSpecPath = "spec.template.spec"
+
+// GenericClient reads & patches the PodSpec of an object of any kind, for example a CRD that
+// embeds a pod template.  The resource is found via (cached) discovery and the object is accessed
+// with the dynamic client, so that we don't need code for each kind.
+type GenericClient struct {
+	clientPool dynamic.ClientPool
+	versions   *APIVersions
+}
+
+func NewGenericClient(clientPool dynamic.ClientPool, versions *APIVersions) *GenericClient {
+	return &GenericClient{
+		clientPool: clientPool,
+		versions:   versions,
+	}
+}
+
+// resourceClient returns the dynamic client for the kind, mapping the kind to its resource via discovery
+func (g *GenericClient) resourceClient(apiVersion string, kind string, namespace string) (dynamic.ResourceInterface, error) {
+	res, err := g.versions.Find(apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing apiVersion %q: %v", apiVersion, err)
+	}
+	return resourceClient(g.clientPool, gv, res, namespace)
+}
+
+// resourceClient returns the dynamic client for the namespaced resource in the group version
+func resourceClient(clientPool dynamic.ClientPool, gv schema.GroupVersion, res *metav1.APIResource, namespace string) (dynamic.ResourceInterface, error) {
+	client, err := clientPool.ClientForGroupVersionKind(gv.WithKind(res.Kind))
+	if err != nil {
+		return nil, fmt.Errorf("error building client for %s: %v", gv, err)
+	}
+	return client.Resource(res, namespace), nil
+}
+
+// readObject reads the object as unstructured JSON.
+// Errors from the server are returned unwrapped, so that callers can check for NotFound.
+func readObject(client dynamic.ResourceInterface, name string) (map[string]interface{}, error) {
+	obj, err := client.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.Object, nil
+}
+
+// splitPodSpecPath splits a dotted path (e.g. spec.template.spec) into fields
+func splitPodSpecPath(podSpecPath string) []string {
+	if podSpecPath == "" {
+		podSpecPath = DefaultPodSpecPath
+	}
+	return strings.Split(strings.Trim(podSpecPath, "."), ".")
+}
+
+// findPodSpec returns the PodSpec in the object at the specified path
+func findPodSpec(obj map[string]interface{}, fields []string) (*corev1.PodSpec, error) {
+	var current interface{} = obj
+	for _, field := range fields {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field %q in path %q was not an object", field, strings.Join(fields, "."))
+		}
+		current = m[field]
+		if current == nil {
+			return nil, fmt.Errorf("field %q in path %q was not found", field, strings.Join(fields, "."))
+		}
+	}
+
+	// Round-trip via JSON to convert to a PodSpec
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("error serializing pod spec: %v", err)
+	}
+	podSpec := &corev1.PodSpec{}
+	if err := json.Unmarshal(data, podSpec); err != nil {
+		return nil, fmt.Errorf("error parsing pod spec: %v", err)
+	}
+	return podSpec, nil
+}
+
+// ReadPodSpec reads the PodSpec from the object at podSpecPath
+func (g *GenericClient) ReadPodSpec(apiVersion, kind, namespace, name, podSpecPath string) (*corev1.PodSpec, error) {
+	client, err := g.resourceClient(apiVersion, kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// We don't wrap the error, so that callers can check for NotFound
+	obj, err := readObject(client, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return findPodSpec(obj, splitPodSpecPath(podSpecPath))
+}
+
+// jsonPatchOperation is an operation in an RFC 6902 JSON patch
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// UpdateResources patches the resources of the containers in the PodSpec at podSpecPath.
+// We use a JSON patch, because CRDs don't support strategic merge patches, and a merge patch
+// would replace the whole list of containers.
+func (g *GenericClient) UpdateResources(apiVersion, kind, namespace, name, podSpecPath string, update *corev1.PodSpec, dryRun bool) error {
+	client, err := g.resourceClient(apiVersion, kind, namespace)
+	if err != nil {
+		return err
+	}
+
+	objectName := fmt.Sprintf("%s %s/%s", kind, namespace, name)
+	obj, err := readObject(client, name)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %v", objectName, err)
+	}
+
+	fields := splitPodSpecPath(podSpecPath)
+	current, err := findPodSpec(obj, fields)
+	if err != nil {
+		return err
+	}
+
+	var ops []jsonPatchOperation
+	ops, err = appendContainerPatches(ops, objectName, fields, "initContainers", current.InitContainers, update.InitContainers)
+	if err != nil {
+		return err
+	}
+	ops, err = appendContainerPatches(ops, objectName, fields, "containers", current.Containers, update.Containers)
+	if err != nil {
+		return err
+	}
+
+	jb, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("can't marshal patch to JSON: %v", err)
+	}
+
+	if dryRun {
+		glog.Infof("Performing dry-run, only printing updates:")
+		glog.Infof("patch: %s", string(jb))
+		return nil
+	}
+	glog.Infof("patching %s %s/%s: %s", kind, namespace, name, string(jb))
+	if _, err := client.Patch(name, types.JSONPatchType, jb); err != nil {
+		return fmt.Errorf("patch failed: %v", err)
+	}
+
+	return nil
+}
+
+// appendContainerPatches appends the operations to set the resources of each of the updated containers in the named
+// list of the PodSpec, returning an error if a container is not in the current list.
+func appendContainerPatches(ops []jsonPatchOperation, objectName string, fields []string, listName string, current []corev1.Container, updates []corev1.Container) ([]jsonPatchOperation, error) {
+	for i := range updates {
+		container := &updates[i]
+
+		index := -1
+		for j := range current {
+			if current[j].Name == container.Name {
+				index = j
+			}
+		}
+		if index == -1 {
+			return nil, fmt.Errorf("container %q not found in %s", container.Name, objectName)
+		}
+
+		resources := current[index].Resources
+		resources.Limits = mergeResourceList(resources.Limits, container.Resources.Limits)
+		resources.Requests = mergeResourceList(resources.Requests, container.Resources.Requests)
+
+		containerPath := fmt.Sprintf("/%s/%s/%d", strings.Join(fields, "/"), listName, index)
+		// The test guards against the containers being reordered since we read them
+		ops = append(ops, jsonPatchOperation{Op: "test", Path: containerPath + "/name", Value: container.Name})
+		ops = append(ops, jsonPatchOperation{Op: "add", Path: containerPath + "/resources", Value: resources})
+	}
+	return ops, nil
+}
+
+// mergeResourceList returns a copy of the current values, with the updated values applied
+func mergeResourceList(current corev1.ResourceList, updates corev1.ResourceList) corev1.ResourceList {
+	if len(current) == 0 && len(updates) == 0 {
+		return nil
+	}
+	merged := make(corev1.ResourceList)
+	for k, v := range current {
+		merged[k] = v
+	}
+	for k, v := range updates {
+		merged[k] = v
+	}
+	return merged
+}
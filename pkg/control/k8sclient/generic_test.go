@@ -0,0 +1,158 @@
+package k8sclient
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/justinsb/scaler/pkg/control/k8sclient/fakecluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// buildRollout builds an object for a CRD that embeds a pod template
+func buildRollout(podSpec map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata": map[string]interface{}{
+			"namespace": "ns1",
+			"name":      "name1",
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": podSpec,
+			},
+		},
+	}
+}
+
+func TestGenericClient(t *testing.T) {
+	cluster := fakecluster.NewCluster()
+
+	cluster.AddResource("argoproj.io/v1alpha1", "rollouts", "Rollout")
+	cluster.AddResource("argoproj.io/v1alpha1", "rollouts/status", "Rollout")
+	rollout := buildRollout(map[string]interface{}{
+		"initContainers": []interface{}{
+			map[string]interface{}{"name": "init1"},
+		},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "sidecar"},
+			map[string]interface{}{
+				"name": "container1",
+				"resources": map[string]interface{}{
+					"limits": map[string]interface{}{"memory": "100Mi"},
+				},
+			},
+		},
+	})
+	if err := cluster.AddObject("argoproj.io/v1alpha1", "rollouts", "ns1", "name1", rollout); err != nil {
+		t.Fatalf("error adding object: %v", err)
+	}
+
+	versions, err := NewAPIVersions(cluster.Discovery)
+	if err != nil {
+		t.Fatalf("error querying API versions: %v", err)
+	}
+	generic := NewGenericClient(cluster.ClientPool, versions)
+	discoveryCalls := cluster.DiscoveryCalls()
+
+	podSpec, err := generic.ReadPodSpec("argoproj.io/v1alpha1", "Rollout", "ns1", "name1", "")
+	if err != nil {
+		t.Fatalf("unexpected error from ReadPodSpec: %v", err)
+	}
+	if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != "container1" {
+		t.Fatalf("unexpected PodSpec: %v", podSpec)
+	}
+
+	if _, err := generic.ReadPodSpec("argoproj.io/v1alpha1", "Rollout", "ns1", "name1", "spec.doesnotexist"); err == nil {
+		t.Errorf("expected error reading PodSpec from missing path")
+	}
+	if _, err := generic.ReadPodSpec("", "Rollout", "ns1", "name1", ""); err == nil {
+		t.Errorf("expected error when apiVersion not specified")
+	}
+	if _, err := generic.ReadPodSpec("argoproj.io/v1alpha1", "Unknown", "ns1", "name1", ""); err == nil {
+		t.Errorf("expected error for kind not in discovery")
+	}
+
+	update := &corev1.PodSpec{
+		InitContainers: []corev1.Container{
+			{
+				Name: "init1",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("50Mi"),
+					},
+				},
+			},
+		},
+		Containers: []corev1.Container{
+			{
+				Name: "container1",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("200m"),
+					},
+				},
+			},
+		},
+	}
+	if err := generic.UpdateResources("argoproj.io/v1alpha1", "Rollout", "ns1", "name1", "", update, false); err != nil {
+		t.Fatalf("unexpected error from UpdateResources: %v", err)
+	}
+
+	if len(cluster.Patches) != 1 {
+		t.Fatalf("expected exactly one patch, got %d", len(cluster.Patches))
+	}
+	patch := cluster.Patches[0]
+	if patch.Path != "/apis/argoproj.io/v1alpha1/namespaces/ns1/rollouts/name1" {
+		t.Errorf("unexpected patch path %q", patch.Path)
+	}
+
+	var actual []interface{}
+	if err := json.Unmarshal(patch.Body, &actual); err != nil {
+		t.Fatalf("error parsing patch: %v", err)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"op": "test", "path": "/spec/template/spec/initContainers/0/name", "value": "init1"},
+		map[string]interface{}{"op": "add", "path": "/spec/template/spec/initContainers/0/resources", "value": map[string]interface{}{
+			"requests": map[string]interface{}{"memory": "50Mi"},
+		}},
+		map[string]interface{}{"op": "test", "path": "/spec/template/spec/containers/1/name", "value": "container1"},
+		map[string]interface{}{"op": "add", "path": "/spec/template/spec/containers/1/resources", "value": map[string]interface{}{
+			"limits":   map[string]interface{}{"memory": "100Mi"},
+			"requests": map[string]interface{}{"cpu": "200m"},
+		}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected patch; expected %v, actual %v", expected, actual)
+	}
+
+	// A container which is not in the target should be an error, and no patch should be sent
+	update.Containers[0].Name = "doesnotexist"
+	if err := generic.UpdateResources("argoproj.io/v1alpha1", "Rollout", "ns1", "name1", "", update, false); err == nil {
+		t.Errorf("expected error updating unknown container")
+	}
+	if len(cluster.Patches) != 1 {
+		t.Errorf("unexpected patch sent for unknown container")
+	}
+
+	// Reads & patches use the cached discovery information
+	if cluster.DiscoveryCalls() != discoveryCalls {
+		t.Errorf("unexpected discovery queries; expected %d, actual %d", discoveryCalls, cluster.DiscoveryCalls())
+	}
+
+	// A group version created after we queried discovery is queried once, and then cached
+	cluster.AddResource("example.com/v1", "widgets", "Widget")
+	if err := cluster.AddObject("example.com/v1", "widgets", "ns1", "name1", buildRollout(map[string]interface{}{})); err != nil {
+		t.Fatalf("error adding object: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := generic.ReadPodSpec("example.com/v1", "Widget", "ns1", "name1", ""); err != nil {
+			t.Fatalf("unexpected error from ReadPodSpec: %v", err)
+		}
+	}
+	if cluster.DiscoveryCalls() != discoveryCalls+1 {
+		t.Errorf("unexpected discovery queries; expected %d, actual %d", discoveryCalls+1, cluster.DiscoveryCalls())
+	}
+}
@@ -22,188 +22,130 @@ package k8sclient
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/golang/glog"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/dynamic"
 )
 
 type ResourcePatcher interface {
 	UpdateResources(kind, namespace, name string, update *corev1.PodSpec, dryRun bool) error
 }
 
+// ResourceReader reads the PodSpec, rollout status and object reference of a built-in kind
+type ResourceReader interface {
+	ReadPodSpec(kind, namespace, name string) (*corev1.PodSpec, error)
+	ReadRolloutStatus(kind, namespace, name string) (*RolloutStatus, error)
+	ReadObjectReference(kind, namespace, name string) (*corev1.ObjectReference, error)
+}
+
 type kubernetesPatcher struct {
-	client        kubernetes.Interface
-	groupVersions map[string]bool
+	clientPool dynamic.ClientPool
+	versions   *APIVersions
 }
 
 var _ ResourcePatcher = &kubernetesPatcher{}
+var _ ResourceReader = &kubernetesPatcher{}
 
-func NewKubernetesPatcher(client kubernetes.Interface) (ResourcePatcher, error) {
-	resourceLists, err := client.Discovery().ServerResources()
-	if err != nil {
-		return nil, fmt.Errorf("failed to query server preferred namespaced resources: %v", err)
-	}
-
-	// TODO: Periodically refresh API versions?
-
-	groupVersions := map[string]bool{}
-	for _, resourceList := range resourceLists {
-		for _, res := range resourceList.APIResources {
-			gvk := resourceList.GroupVersion + "/" + res.Kind
-			groupVersions[gvk] = true
-		}
+func NewKubernetesPatcher(clientPool dynamic.ClientPool, versions *APIVersions) ResourcePatcher {
+	return &kubernetesPatcher{
+		clientPool: clientPool,
+		versions:   versions,
 	}
+}
 
+func NewKubernetesReader(clientPool dynamic.ClientPool, versions *APIVersions) ResourceReader {
 	return &kubernetesPatcher{
-		client:        client,
-		groupVersions: groupVersions,
-	}, nil
+		clientPool: clientPool,
+		versions:   versions,
+	}
 }
 
-// Captures the namespace and name to patch, and calls the best
-// resource-specific patch method.
-type patchFunc func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error
-
-// findPatcher returns a groupVersion string and a patch function for the
-// specified kind.  This is needed because, at least in theory, the schema of a
-// resource could change dramatically, and we should use statically versioned
-// types everywhere.  In practice, it's unlikely that the bits we care about
-// would change (since we PATCH).  Alas, there's not a great way to dynamically
-// use whatever is "latest".  The fallout of this is that we will need to update
-// this program when new API group-versions are introduced.
-func (k *kubernetesPatcher) findPatcher(kind string) (schema.GroupVersion, patchFunc, error) {
-	switch strings.ToLower(kind) {
-	case "deployment":
-		return findDeploymentPatcher(k.groupVersions)
-	case "daemonset":
-		return findDaemonSetPatcher(k.groupVersions)
-	case "replicaset":
-		return findReplicaSetPatcher(k.groupVersions)
+// readObject reads the object with the selected API as unstructured JSON, returning errors unwrapped
+func (k *kubernetesPatcher) readObject(api *BuiltinAPI, namespace, name string) (map[string]interface{}, error) {
+	client, err := resourceClient(k.clientPool, api.GroupVersion, api.APIResource(), namespace)
+	if err != nil {
+		return nil, err
 	}
-	return schema.GroupVersion{}, nil, fmt.Errorf("unknown target kind: %s", kind)
+	return readObject(client, name)
 }
 
-func findDeploymentPatcher(groupVersions map[string]bool) (schema.GroupVersion, patchFunc, error) {
-	// Find the best API to use - newest API first.
-	if groupVersions["apps/v1beta2/Deployment"] {
-		fn := func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error {
-			_, err := client.AppsV1beta2().Deployments(namespace).Patch(name, pt, data)
+// ReadPodSpec reads the PodSpec from the object, using the API version selected by discovery
+func (k *kubernetesPatcher) ReadPodSpec(kind, namespace, name string) (*corev1.PodSpec, error) {
+	var podSpec *corev1.PodSpec
+	err := k.versions.Do(kind, func(api *BuiltinAPI) error {
+		obj, err := k.readObject(api, namespace, name)
+		if err != nil {
 			return err
 		}
-		return schema.GroupVersion{Group: "apps", Version: "v1beta2"}, patchFunc(fn), nil
+		podSpec, err = findPodSpec(obj, splitPodSpecPath(api.PodSpecPath))
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-	if groupVersions["apps/v1beta1/Deployment"] {
-		fn := func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error {
-			_, err := client.AppsV1beta1().Deployments(namespace).Patch(name, pt, data)
-			return err
-		}
-		return schema.GroupVersion{Group: "apps", Version: "v1beta1"}, patchFunc(fn), nil
+	return podSpec, nil
+}
+
+func (k *kubernetesPatcher) UpdateResources(kind, namespace, name string, update *corev1.PodSpec, dryRun bool) error {
+	// Both lists are merged by container name
+	podSpec := map[string]interface{}{
+		"containers": buildContainerPatches(update.Containers),
 	}
-	if groupVersions["extensions/v1beta1/Deployment"] {
-		fn := func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error {
-			_, err := client.ExtensionsV1beta1().Deployments(namespace).Patch(name, pt, data)
-			return err
-		}
-		return schema.GroupVersion{Group: "extensions", Version: "v1beta1"}, patchFunc(fn), nil
+	if len(update.InitContainers) != 0 {
+		podSpec["initContainers"] = buildContainerPatches(update.InitContainers)
 	}
-	return schema.GroupVersion{}, nil, fmt.Errorf("no supported API group for Deployment: %v", groupVersions)
-}
 
-func findDaemonSetPatcher(groupVersions map[string]bool) (schema.GroupVersion, patchFunc, error) {
-	// Find the best API to use - newest API first.
-	if groupVersions["apps/v1beta2/DaemonSet"] {
-		fn := func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error {
-			_, err := client.AppsV1beta2().DaemonSets(namespace).Patch(name, pt, data)
-			return err
+	err := k.versions.Do(kind, func(api *BuiltinAPI) error {
+		// Build the patch object from the PodSpecPath (spec.template.spec)
+		var body interface{} = podSpec
+		fields := splitPodSpecPath(api.PodSpecPath)
+		for i := len(fields) - 1; i >= 0; i-- {
+			body = map[string]interface{}{
+				fields[i]: body,
+			}
 		}
-		return schema.GroupVersion{Group: "apps", Version: "v1beta2"}, patchFunc(fn), nil
-	}
-	if groupVersions["extensions/v1beta1/DaemonSet"] {
-		fn := func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error {
-			_, err := client.ExtensionsV1beta1().DaemonSets(namespace).Patch(name, pt, data)
-			return err
+		patch := body.(map[string]interface{})
+		patch["apiVersion"] = api.GroupVersion.String()
+		patch["kind"] = api.Kind
+		patch["metadata"] = map[string]interface{}{
+			"name": name,
 		}
-		return schema.GroupVersion{Group: "extensions", Version: "v1beta1"}, patchFunc(fn), nil
-	}
-	return schema.GroupVersion{}, nil, fmt.Errorf("no supported API group for DaemonSet: %v", groupVersions)
-}
 
-func findReplicaSetPatcher(groupVersions map[string]bool) (schema.GroupVersion, patchFunc, error) {
-	// Find the best API to use - newest API first.
-	if groupVersions["apps/v1beta2/ReplicaSet"] {
-		fn := func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error {
-			_, err := client.AppsV1beta2().ReplicaSets(namespace).Patch(name, pt, data)
-			return err
+		jb, err := json.Marshal(patch)
+		if err != nil {
+			return fmt.Errorf("can't marshal patch to JSON: %v", err)
 		}
-		return schema.GroupVersion{Group: "extensions", Version: "v1beta2"}, patchFunc(fn), nil
-	}
-	if groupVersions["extensions/v1beta1/ReplicaSet"] {
-		fn := func(client kubernetes.Interface, namespace, name string, pt types.PatchType, data []byte) error {
-			_, err := client.ExtensionsV1beta1().ReplicaSets(namespace).Patch(name, pt, data)
+
+		if dryRun {
+			glog.Infof("Performing dry-run, only printing updates:")
+			glog.Infof("patch: %s", string(jb))
+			return nil
+		}
+		glog.Infof("patching %s %s/%s: %s", kind, namespace, name, string(jb))
+		client, err := resourceClient(k.clientPool, api.GroupVersion, api.APIResource(), namespace)
+		if err != nil {
 			return err
 		}
-		return schema.GroupVersion{Group: "extensions", Version: "v1beta1"}, patchFunc(fn), nil
-	}
-	return schema.GroupVersion{}, nil, fmt.Errorf("no supported API group for ReplicaSet: %v", groupVersions)
-}
-
-func (k *kubernetesPatcher) UpdateResources(kind, namespace, name string, update *corev1.PodSpec, dryRun bool) error {
-	gv, patcher, err := k.findPatcher(kind)
-	if err != nil {
+		_, err = client.Patch(name, types.StrategicMergePatchType, jb)
 		return err
+	})
+	if err != nil {
+		return fmt.Errorf("patch failed: %v", err)
 	}
+	return nil
+}
 
+// buildContainerPatches builds the strategic merge patch for the resources of each container
+func buildContainerPatches(containers []corev1.Container) []interface{} {
 	ctrs := []interface{}{}
-	for i := range update.Containers {
-		container := &update.Containers[i]
+	for i := range containers {
+		container := &containers[i]
 		ctrs = append(ctrs, map[string]interface{}{
 			"name":      container.Name,
 			"resources": container.Resources,
 		})
 	}
-	podSpec := map[string]interface{}{
-		"containers": ctrs,
-	}
-
-	spec := make(map[string]interface{})
-
-	switch strings.ToLower(kind) {
-	case "replicaset", "deployment", "daemonset":
-		spec["template"] = map[string]interface{}{
-			"spec": podSpec,
-		}
-
-	default:
-		return fmt.Errorf("unhandled type: %s", kind)
-	}
-
-	patch := map[string]interface{}{
-		"apiVersion": gv.String(),
-		"kind":       kind,
-		"metadata": map[string]interface{}{
-			"name": name,
-		},
-		"spec": spec,
-	}
-
-	jb, err := json.Marshal(patch)
-	if err != nil {
-		return fmt.Errorf("can't marshal patch to JSON: %v", err)
-	}
-
-	if dryRun {
-		glog.Infof("Performing dry-run, only printing updates:")
-		glog.Infof("patch: %s", string(jb))
-		return nil
-	}
-	glog.Infof("patching %s %s/%s: %s", kind, namespace, name, string(jb))
-	if err := patcher(k.client, namespace, name, types.StrategicMergePatchType, jb); err != nil {
-		return fmt.Errorf("patch failed: %v", err)
-	}
-
-	return nil
+	return ctrs
 }
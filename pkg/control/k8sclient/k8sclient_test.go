@@ -0,0 +1,298 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/pkg/control/k8sclient/fakecluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestUpdateResources(t *testing.T) {
+	grid := []struct {
+		Name          string
+		Kind          string
+		GroupVersions []string
+		Resource      string
+		ResourceKind  string
+		ExpectedPath  string
+		ExpectedGV    string
+	}{
+		{
+			Name:          "StatefulSet apps/v1",
+			Kind:          "statefulset",
+			GroupVersions: []string{"apps/v1beta1", "apps/v1beta2", "apps/v1"},
+			Resource:      "statefulsets",
+			ResourceKind:  "StatefulSet",
+			ExpectedPath:  "/apis/apps/v1/namespaces/ns1/statefulsets/name1",
+			ExpectedGV:    "apps/v1",
+		},
+		{
+			Name:          "StatefulSet apps/v1beta2",
+			Kind:          "StatefulSet",
+			GroupVersions: []string{"apps/v1beta1", "apps/v1beta2"},
+			Resource:      "statefulsets",
+			ResourceKind:  "StatefulSet",
+			ExpectedPath:  "/apis/apps/v1beta2/namespaces/ns1/statefulsets/name1",
+			ExpectedGV:    "apps/v1beta2",
+		},
+		{
+			Name:          "StatefulSet apps/v1beta1",
+			Kind:          "StatefulSet",
+			GroupVersions: []string{"apps/v1beta1"},
+			Resource:      "statefulsets",
+			ResourceKind:  "StatefulSet",
+			ExpectedPath:  "/apis/apps/v1beta1/namespaces/ns1/statefulsets/name1",
+			ExpectedGV:    "apps/v1beta1",
+		},
+		{
+			Name:          "Deployment apps/v1",
+			Kind:          "Deployment",
+			GroupVersions: []string{"extensions/v1beta1", "apps/v1beta1", "apps/v1beta2", "apps/v1"},
+			Resource:      "deployments",
+			ResourceKind:  "Deployment",
+			ExpectedPath:  "/apis/apps/v1/namespaces/ns1/deployments/name1",
+			ExpectedGV:    "apps/v1",
+		},
+		{
+			Name:          "Deployment extensions/v1beta1",
+			Kind:          "deployment",
+			GroupVersions: []string{"extensions/v1beta1"},
+			Resource:      "deployments",
+			ResourceKind:  "Deployment",
+			ExpectedPath:  "/apis/extensions/v1beta1/namespaces/ns1/deployments/name1",
+			ExpectedGV:    "extensions/v1beta1",
+		},
+		{
+			Name:          "ReplicaSet apps/v1beta2",
+			Kind:          "ReplicaSet",
+			GroupVersions: []string{"extensions/v1beta1", "apps/v1beta2"},
+			Resource:      "replicasets",
+			ResourceKind:  "ReplicaSet",
+			ExpectedPath:  "/apis/apps/v1beta2/namespaces/ns1/replicasets/name1",
+			ExpectedGV:    "apps/v1beta2",
+		},
+		{
+			Name:          "DaemonSet apps/v1",
+			Kind:          "DaemonSet",
+			GroupVersions: []string{"extensions/v1beta1", "apps/v1beta2", "apps/v1"},
+			Resource:      "daemonsets",
+			ResourceKind:  "DaemonSet",
+			ExpectedPath:  "/apis/apps/v1/namespaces/ns1/daemonsets/name1",
+			ExpectedGV:    "apps/v1",
+		},
+		{
+			Name:          "ReplicationController",
+			Kind:          "ReplicationController",
+			GroupVersions: []string{"v1"},
+			Resource:      "replicationcontrollers",
+			ResourceKind:  "ReplicationController",
+			ExpectedPath:  "/api/v1/namespaces/ns1/replicationcontrollers/name1",
+			ExpectedGV:    "v1",
+		},
+	}
+
+	for _, g := range grid {
+		cluster := fakecluster.NewCluster()
+
+		for _, gv := range g.GroupVersions {
+			cluster.AddResource(gv, g.Resource, g.ResourceKind)
+			obj := map[string]interface{}{
+				"apiVersion": gv,
+				"kind":       g.ResourceKind,
+				"metadata":   &metav1.ObjectMeta{Namespace: "ns1", Name: "name1"},
+			}
+			if err := cluster.AddObject(gv, g.Resource, "ns1", "name1", obj); err != nil {
+				t.Fatalf("error adding object: %v", err)
+			}
+		}
+
+		versions, err := NewAPIVersions(cluster.Discovery)
+		if err != nil {
+			t.Fatalf("error querying API versions: %v", err)
+		}
+		patcher := NewKubernetesPatcher(cluster.ClientPool, versions)
+
+		update := &corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{
+					Name: "init1",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("50Mi"),
+						},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name: "container1",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceMemory: resource.MustParse("100Mi"),
+						},
+					},
+				},
+			},
+		}
+		if err := patcher.UpdateResources(g.Kind, "ns1", "name1", update, false); err != nil {
+			t.Errorf("%s: unexpected error from UpdateResources: %v", g.Name, err)
+			continue
+		}
+
+		if len(cluster.Patches) != 1 {
+			t.Errorf("%s: expected exactly one patch, got %d", g.Name, len(cluster.Patches))
+			continue
+		}
+		patch := cluster.Patches[0]
+		if patch.Path != g.ExpectedPath {
+			t.Errorf("%s: unexpected patch path: actual=%s expected=%s", g.Name, patch.Path, g.ExpectedPath)
+		}
+
+		body := &struct {
+			APIVersion string `json:"apiVersion"`
+			Spec       struct {
+				Template struct {
+					Spec corev1.PodSpec `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		}{}
+		if err := json.Unmarshal(patch.Body, body); err != nil {
+			t.Errorf("%s: error parsing patch: %v", g.Name, err)
+			continue
+		}
+		if body.APIVersion != g.ExpectedGV {
+			t.Errorf("%s: unexpected patch apiVersion: actual=%s expected=%s", g.Name, body.APIVersion, g.ExpectedGV)
+		}
+		containers := body.Spec.Template.Spec.Containers
+		if len(containers) != 1 || containers[0].Name != "container1" {
+			t.Errorf("%s: unexpected patch body: %s", g.Name, string(patch.Body))
+		}
+		initContainers := body.Spec.Template.Spec.InitContainers
+		if len(initContainers) != 1 || initContainers[0].Name != "init1" {
+			t.Errorf("%s: unexpected patch body: %s", g.Name, string(patch.Body))
+		}
+	}
+}
+
+func TestAPIVersionsRefreshWhenGroupRemoved(t *testing.T) {
+	cluster := fakecluster.NewCluster()
+
+	for _, gv := range []string{"apps/v1beta2", "apps/v1"} {
+		cluster.AddResource(gv, "deployments", "Deployment")
+		obj := map[string]interface{}{
+			"apiVersion": gv,
+			"kind":       "Deployment",
+			"metadata":   &metav1.ObjectMeta{Namespace: "ns1", Name: "name1"},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "container1"},
+						},
+					},
+				},
+			},
+		}
+		if err := cluster.AddObject(gv, "deployments", "ns1", "name1", obj); err != nil {
+			t.Fatalf("error adding object: %v", err)
+		}
+	}
+
+	versions, err := NewAPIVersions(cluster.Discovery)
+	if err != nil {
+		t.Fatalf("error querying API versions: %v", err)
+	}
+	clock := clock.NewFakeClock(time.Now())
+	versions.clock = clock
+	reader := NewKubernetesReader(cluster.ClientPool, versions)
+
+	api, err := versions.Select("deployment")
+	if err != nil {
+		t.Fatalf("unexpected error from Select: %v", err)
+	}
+	if api.GroupVersion.String() != "apps/v1" {
+		t.Errorf("expected apps/v1 to be selected, got %s", api.GroupVersion)
+	}
+
+	// Simulate a downgrade, where apps/v1 disappears but our cached discovery information still has it
+	cluster.RemoveGroupVersion("apps/v1")
+	clock.Step(minRefreshInterval)
+
+	podSpec, err := reader.ReadPodSpec("Deployment", "ns1", "name1")
+	if err != nil {
+		t.Fatalf("unexpected error from ReadPodSpec: %v", err)
+	}
+	if len(podSpec.Containers) != 1 || podSpec.Containers[0].Name != "container1" {
+		t.Errorf("unexpected PodSpec: %v", podSpec)
+	}
+
+	api, err = versions.Select("deployment")
+	if err != nil {
+		t.Fatalf("unexpected error from Select: %v", err)
+	}
+	if api.GroupVersion.String() != "apps/v1beta2" {
+		t.Errorf("expected apps/v1beta2 to be selected after refresh, got %s", api.GroupVersion)
+	}
+
+	// A missing object is still an error
+	if _, err := reader.ReadPodSpec("Deployment", "ns1", "doesnotexist"); err == nil {
+		t.Errorf("expected error reading non-existent object")
+	}
+}
+
+func TestAPIVersionsNotFoundObject(t *testing.T) {
+	cluster := fakecluster.NewCluster()
+	cluster.AddResource("apps/v1", "deployments", "Deployment")
+
+	versions, err := NewAPIVersions(cluster.Discovery)
+	if err != nil {
+		t.Fatalf("error querying API versions: %v", err)
+	}
+	clock := clock.NewFakeClock(time.Now())
+	versions.clock = clock
+	reader := NewKubernetesReader(cluster.ClientPool, versions)
+
+	readMissing := func() {
+		for i := 0; i < 3; i++ {
+			if _, err := reader.ReadPodSpec("Deployment", "ns1", "doesnotexist"); !errors.IsNotFound(err) {
+				t.Fatalf("expected NotFound reading non-existent object, got %v", err)
+			}
+		}
+	}
+
+	// We queried discovery recently, so we don't query it again
+	discoveryCalls := cluster.DiscoveryCalls()
+	readMissing()
+	if cluster.DiscoveryCalls() != discoveryCalls {
+		t.Errorf("unexpected discovery queries; expected %d, actual %d", discoveryCalls, cluster.DiscoveryCalls())
+	}
+
+	// Once the interval has passed we check the group version once; it is still served, so we don't refresh
+	clock.Step(minRefreshInterval)
+	readMissing()
+	if cluster.DiscoveryCalls() != discoveryCalls+1 {
+		t.Errorf("unexpected discovery queries; expected %d, actual %d", discoveryCalls+1, cluster.DiscoveryCalls())
+	}
+}
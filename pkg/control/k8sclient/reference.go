@@ -0,0 +1,80 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ReadObjectReference reads the object, returning a reference for recording events, using the API version selected by discovery
+func (k *kubernetesPatcher) ReadObjectReference(kind, namespace, name string) (*corev1.ObjectReference, error) {
+	var ref *corev1.ObjectReference
+	err := k.versions.Do(kind, func(api *BuiltinAPI) error {
+		obj, err := k.readObject(api, namespace, name)
+		if err != nil {
+			return err
+		}
+		ref = objectReference(obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// ReadObjectReference reads the object, returning a reference for recording events
+func (g *GenericClient) ReadObjectReference(apiVersion, kind, namespace, name string) (*corev1.ObjectReference, error) {
+	client, err := g.resourceClient(apiVersion, kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := readObject(client, name)
+	if err != nil {
+		return nil, err
+	}
+	return objectReference(obj), nil
+}
+
+// objectReference builds a reference to the (unstructured) object.
+// The UID is included so that kubectl describe finds events recorded against the reference.
+func objectReference(obj map[string]interface{}) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion:      nestedString(obj, "apiVersion"),
+		Kind:            nestedString(obj, "kind"),
+		Namespace:       nestedString(obj, "metadata", "namespace"),
+		Name:            nestedString(obj, "metadata", "name"),
+		UID:             types.UID(nestedString(obj, "metadata", "uid")),
+		ResourceVersion: nestedString(obj, "metadata", "resourceVersion"),
+	}
+}
+
+// nestedString returns the string value at the path in the object, or "" if it is not found
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	var current interface{} = obj
+	for _, field := range fields {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		current = m[field]
+	}
+	s, _ := current.(string)
+	return s
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	"testing"
+
+	"github.com/justinsb/scaler/pkg/control/k8sclient/fakecluster"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestReadObjectReference(t *testing.T) {
+	cluster := fakecluster.NewCluster()
+	cluster.AddResource("apps/v1", "deployments", "Deployment")
+	cluster.AddResource("argoproj.io/v1alpha1", "rollouts", "Rollout")
+
+	deployment := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "ns1", "name": "name1", "uid": "uid1", "resourceVersion": "12"},
+	}
+	if err := cluster.AddObject("apps/v1", "deployments", "ns1", "name1", deployment); err != nil {
+		t.Fatalf("error adding object: %v", err)
+	}
+	rollout := map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]interface{}{"namespace": "ns1", "name": "name2", "uid": "uid2"},
+	}
+	if err := cluster.AddObject("argoproj.io/v1alpha1", "rollouts", "ns1", "name2", rollout); err != nil {
+		t.Fatalf("error adding object: %v", err)
+	}
+
+	versions, err := NewAPIVersions(cluster.Discovery)
+	if err != nil {
+		t.Fatalf("error querying API versions: %v", err)
+	}
+	reader := NewKubernetesReader(cluster.ClientPool, versions)
+	generic := NewGenericClient(cluster.ClientPool, versions)
+
+	ref, err := reader.ReadObjectReference("Deployment", "ns1", "name1")
+	if err != nil {
+		t.Fatalf("unexpected error from ReadObjectReference: %v", err)
+	}
+	expected := corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "ns1", Name: "name1", UID: "uid1", ResourceVersion: "12"}
+	if *ref != expected {
+		t.Errorf("unexpected reference %+v", ref)
+	}
+
+	ref, err = generic.ReadObjectReference("argoproj.io/v1alpha1", "Rollout", "ns1", "name2")
+	if err != nil {
+		t.Fatalf("unexpected error from ReadObjectReference: %v", err)
+	}
+	expected = corev1.ObjectReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Namespace: "ns1", Name: "name2", UID: "uid2"}
+	if *ref != expected {
+		t.Errorf("unexpected reference %+v", ref)
+	}
+
+	// Callers rely on NotFound errors being returned unwrapped
+	if _, err := reader.ReadPodSpec("Deployment", "ns1", "missing"); !errors.IsNotFound(err) {
+		t.Errorf("expected NotFound reading missing deployment, got %v", err)
+	}
+	if _, err := generic.ReadPodSpec("argoproj.io/v1alpha1", "Rollout", "ns1", "missing", ""); !errors.IsNotFound(err) {
+		t.Errorf("expected NotFound reading missing rollout, got %v", err)
+	}
+}
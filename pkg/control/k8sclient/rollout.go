@@ -0,0 +1,137 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RolloutStatus describes the progress of rolling out the latest spec of an object to its pods
+type RolloutStatus struct {
+	// Converged is true when the controller has observed the latest spec, and all the replicas are updated and available
+	Converged bool
+
+	// Message describes the progress of the rollout, for humans
+	Message string
+}
+
+// ReadRolloutStatus reads the rollout status of the object, using the API version selected by discovery.
+// It returns nil if we don't track the rollout of the kind (e.g. a ReplicaSet, which doesn't roll out changes to its pods).
+func (k *kubernetesPatcher) ReadRolloutStatus(kind, namespace, name string) (*RolloutStatus, error) {
+	switch strings.ToLower(kind) {
+	case "deployment", "daemonset", "statefulset":
+	default:
+		return nil, nil
+	}
+
+	var status *RolloutStatus
+	err := k.versions.Do(kind, func(api *BuiltinAPI) error {
+		obj, err := k.readObject(api, namespace, name)
+		if err != nil {
+			return err
+		}
+		status = rolloutStatusFromObject(api.Kind, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// rolloutStatusFromObject computes the rollout status from the (unstructured) object.
+// We only use fields that are common to all the API versions of the kind.
+func rolloutStatusFromObject(kind string, obj map[string]interface{}) *RolloutStatus {
+	generation, _ := nestedInt64(obj, "metadata", "generation")
+	observedGeneration, _ := nestedInt64(obj, "status", "observedGeneration")
+	if observedGeneration < generation {
+		return &RolloutStatus{
+			Message: fmt.Sprintf("waiting for the controller to observe generation %d (observed %d)", generation, observedGeneration),
+		}
+	}
+
+	var desired, updated, available, total int64
+	switch strings.ToLower(kind) {
+	case "deployment":
+		desired = replicas(obj)
+		updated, _ = nestedInt64(obj, "status", "updatedReplicas")
+		available, _ = nestedInt64(obj, "status", "availableReplicas")
+		total, _ = nestedInt64(obj, "status", "replicas")
+
+	case "statefulset":
+		// StatefulSets don't report availableReplicas in all versions, so we use readyReplicas
+		desired = replicas(obj)
+		updated, _ = nestedInt64(obj, "status", "updatedReplicas")
+		available, _ = nestedInt64(obj, "status", "readyReplicas")
+		total, _ = nestedInt64(obj, "status", "replicas")
+
+	case "daemonset":
+		desired, _ = nestedInt64(obj, "status", "desiredNumberScheduled")
+		updated, _ = nestedInt64(obj, "status", "updatedNumberScheduled")
+		available, _ = nestedInt64(obj, "status", "numberAvailable")
+		total, _ = nestedInt64(obj, "status", "currentNumberScheduled")
+
+	default:
+		return nil
+	}
+
+	if updated < desired {
+		return &RolloutStatus{Message: fmt.Sprintf("%d of %d replicas updated", updated, desired)}
+	}
+	if total > updated {
+		return &RolloutStatus{Message: fmt.Sprintf("%d old replicas pending termination", total-updated)}
+	}
+	if available < desired {
+		return &RolloutStatus{Message: fmt.Sprintf("%d of %d updated replicas available", available, desired)}
+	}
+	return &RolloutStatus{Converged: true, Message: fmt.Sprintf("%d replicas updated and available", desired)}
+}
+
+// replicas returns spec.replicas, which defaults to 1
+func replicas(obj map[string]interface{}) int64 {
+	if v, found := nestedInt64(obj, "spec", "replicas"); found {
+		return v
+	}
+	return 1
+}
+
+// nestedInt64 returns the integer value at the path in the object
+func nestedInt64(obj map[string]interface{}, fields ...string) (int64, bool) {
+	var current interface{} = obj
+	for _, field := range fields {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return 0, false
+		}
+		current, ok = m[field]
+		if !ok {
+			return 0, false
+		}
+	}
+
+	switch v := current.(type) {
+	case float64:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
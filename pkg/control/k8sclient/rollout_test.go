@@ -0,0 +1,157 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/justinsb/scaler/pkg/control/k8sclient/fakecluster"
+)
+
+func TestRolloutStatusFromObject(t *testing.T) {
+	grid := []struct {
+		Name      string
+		Kind      string
+		Object    string
+		Converged bool
+		Message   string
+	}{
+		{
+			Name:      "Deployment converged",
+			Kind:      "Deployment",
+			Object:    `{"metadata": {"generation": 2}, "spec": {"replicas": 3}, "status": {"observedGeneration": 2, "replicas": 3, "updatedReplicas": 3, "availableReplicas": 3}}`,
+			Converged: true,
+			Message:   "3 replicas updated and available",
+		},
+		{
+			Name:    "Deployment generation not observed",
+			Kind:    "Deployment",
+			Object:  `{"metadata": {"generation": 3}, "spec": {"replicas": 3}, "status": {"observedGeneration": 2, "replicas": 3, "updatedReplicas": 3, "availableReplicas": 3}}`,
+			Message: "waiting for the controller to observe generation 3 (observed 2)",
+		},
+		{
+			Name:    "Deployment replicas not updated",
+			Kind:    "Deployment",
+			Object:  `{"metadata": {"generation": 2}, "spec": {"replicas": 3}, "status": {"observedGeneration": 2, "replicas": 4, "updatedReplicas": 1, "availableReplicas": 3}}`,
+			Message: "1 of 3 replicas updated",
+		},
+		{
+			Name:    "Deployment old replicas terminating",
+			Kind:    "Deployment",
+			Object:  `{"metadata": {"generation": 2}, "spec": {"replicas": 3}, "status": {"observedGeneration": 2, "replicas": 4, "updatedReplicas": 3, "availableReplicas": 3}}`,
+			Message: "1 old replicas pending termination",
+		},
+		{
+			Name:    "Deployment replicas not available",
+			Kind:    "Deployment",
+			Object:  `{"metadata": {"generation": 2}, "spec": {"replicas": 3}, "status": {"observedGeneration": 2, "replicas": 3, "updatedReplicas": 3, "availableReplicas": 2}}`,
+			Message: "2 of 3 updated replicas available",
+		},
+		{
+			Name:      "Deployment replicas defaults to 1",
+			Kind:      "Deployment",
+			Object:    `{"metadata": {"generation": 1}, "status": {"observedGeneration": 1, "replicas": 1, "updatedReplicas": 1, "availableReplicas": 1}}`,
+			Converged: true,
+			Message:   "1 replicas updated and available",
+		},
+		{
+			Name:      "Deployment scaled to zero",
+			Kind:      "Deployment",
+			Object:    `{"metadata": {"generation": 1}, "spec": {"replicas": 0}, "status": {"observedGeneration": 1}}`,
+			Converged: true,
+			Message:   "0 replicas updated and available",
+		},
+		{
+			Name:      "DaemonSet converged",
+			Kind:      "DaemonSet",
+			Object:    `{"metadata": {"generation": 5}, "status": {"observedGeneration": 5, "desiredNumberScheduled": 10, "currentNumberScheduled": 10, "updatedNumberScheduled": 10, "numberAvailable": 10}}`,
+			Converged: true,
+			Message:   "10 replicas updated and available",
+		},
+		{
+			Name:    "DaemonSet rolling",
+			Kind:    "DaemonSet",
+			Object:  `{"metadata": {"generation": 5}, "status": {"observedGeneration": 5, "desiredNumberScheduled": 10, "currentNumberScheduled": 10, "updatedNumberScheduled": 4, "numberAvailable": 9}}`,
+			Message: "4 of 10 replicas updated",
+		},
+		{
+			Name:      "StatefulSet converged",
+			Kind:      "StatefulSet",
+			Object:    `{"metadata": {"generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "replicas": 2, "updatedReplicas": 2, "readyReplicas": 2}}`,
+			Converged: true,
+			Message:   "2 replicas updated and available",
+		},
+		{
+			Name:    "StatefulSet not ready",
+			Kind:    "StatefulSet",
+			Object:  `{"metadata": {"generation": 2}, "spec": {"replicas": 2}, "status": {"observedGeneration": 2, "replicas": 2, "updatedReplicas": 2, "readyReplicas": 1}}`,
+			Message: "1 of 2 updated replicas available",
+		},
+	}
+
+	for _, g := range grid {
+		obj := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(g.Object), &obj); err != nil {
+			t.Fatalf("error parsing object for %s: %v", g.Name, err)
+		}
+		status := rolloutStatusFromObject(g.Kind, obj)
+		if status == nil || status.Converged != g.Converged || status.Message != g.Message {
+			t.Errorf("test failure\nname=%s\n  actual=%+v\nexpected converged=%v message=%q", g.Name, status, g.Converged, g.Message)
+		}
+	}
+}
+
+func TestReadRolloutStatus(t *testing.T) {
+	cluster := fakecluster.NewCluster()
+	cluster.AddResource("apps/v1", "deployments", "Deployment")
+	cluster.AddResource("apps/v1", "replicasets", "ReplicaSet")
+
+	obj := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "ns1", "name": "name1", "generation": 2},
+		"spec":       map[string]interface{}{"replicas": 2},
+		"status":     map[string]interface{}{"observedGeneration": 2, "replicas": 3, "updatedReplicas": 2, "availableReplicas": 2},
+	}
+	if err := cluster.AddObject("apps/v1", "deployments", "ns1", "name1", obj); err != nil {
+		t.Fatalf("error adding object: %v", err)
+	}
+
+	versions, err := NewAPIVersions(cluster.Discovery)
+	if err != nil {
+		t.Fatalf("error querying API versions: %v", err)
+	}
+	reader := NewKubernetesReader(cluster.ClientPool, versions)
+
+	status, err := reader.ReadRolloutStatus("Deployment", "ns1", "name1")
+	if err != nil {
+		t.Fatalf("unexpected error from ReadRolloutStatus: %v", err)
+	}
+	if status == nil || status.Converged || status.Message != "1 old replicas pending termination" {
+		t.Errorf("unexpected rollout status %+v", status)
+	}
+
+	// We don't track the rollout of ReplicaSets
+	status, err = reader.ReadRolloutStatus("ReplicaSet", "ns1", "name1")
+	if err != nil {
+		t.Fatalf("unexpected error from ReadRolloutStatus: %v", err)
+	}
+	if status != nil {
+		t.Errorf("unexpected rollout status for ReplicaSet: %+v", status)
+	}
+}
@@ -0,0 +1,290 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8sclient
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/discovery"
+)
+
+// builtinKind describes a built-in kind that we know how to read & patch
+type builtinKind struct {
+	Kind     string
+	Resource string
+
+	// GroupVersions lists the group versions which serve the kind, newest (preferred) first
+	GroupVersions []string
+
+	// PodSpecPath is the path to the PodSpec in the object
+	PodSpecPath string
+}
+
+// builtinKinds holds the built-in kinds, keyed by the lower-cased kind.
+// We will need to update this list when new API group-versions are introduced,
+// but because we access the objects as JSON, we don't need code for each version.
+var builtinKinds = map[string]*builtinKind{
+	"deployment": {
+		Kind:          "Deployment",
+		Resource:      "deployments",
+		GroupVersions: []string{"apps/v1", "apps/v1beta2", "apps/v1beta1", "extensions/v1beta1"},
+		PodSpecPath:   DefaultPodSpecPath,
+	},
+	"daemonset": {
+		Kind:          "DaemonSet",
+		Resource:      "daemonsets",
+		GroupVersions: []string{"apps/v1", "apps/v1beta2", "extensions/v1beta1"},
+		PodSpecPath:   DefaultPodSpecPath,
+	},
+	"replicaset": {
+		Kind:          "ReplicaSet",
+		Resource:      "replicasets",
+		GroupVersions: []string{"apps/v1", "apps/v1beta2", "extensions/v1beta1"},
+		PodSpecPath:   DefaultPodSpecPath,
+	},
+	"statefulset": {
+		Kind:          "StatefulSet",
+		Resource:      "statefulsets",
+		GroupVersions: []string{"apps/v1", "apps/v1beta2", "apps/v1beta1"},
+		PodSpecPath:   DefaultPodSpecPath,
+	},
+	"replicationcontroller": {
+		Kind:          "ReplicationController",
+		Resource:      "replicationcontrollers",
+		GroupVersions: []string{"v1"},
+		PodSpecPath:   DefaultPodSpecPath,
+	},
+}
+
+// IsBuiltinKind returns true if the kind (in the apiVersion, if specified) is one of the built-in kinds
+func IsBuiltinKind(apiVersion string, kind string) bool {
+	if apiVersion != "" {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return false
+		}
+		switch gv.Group {
+		case "", "apps", "extensions":
+		default:
+			return false
+		}
+	}
+	return builtinKinds[strings.ToLower(kind)] != nil
+}
+
+// BuiltinAPI is the API we have selected to access a built-in kind
+type BuiltinAPI struct {
+	GroupVersion schema.GroupVersion
+	Kind         string
+	Resource     string
+	PodSpecPath  string
+}
+
+// APIResource returns the resource for the dynamic client
+func (a *BuiltinAPI) APIResource() *metav1.APIResource {
+	return &metav1.APIResource{
+		Name:       a.Resource,
+		Namespaced: true,
+		Kind:       a.Kind,
+	}
+}
+
+// minRefreshInterval is the minimum interval between discovery queries after NotFound errors, so that
+// repeatedly reading an object which doesn't exist doesn't repeatedly query discovery
+const minRefreshInterval = time.Minute
+
+// APIVersions selects the API group version to use for each built-in kind, so that reads & patches
+// use the same version, and maps the kinds of other objects to their resources.
+// The discovery information is cached, and refreshed when a group version disappears.
+type APIVersions struct {
+	discovery discovery.DiscoveryInterface
+	clock     clock.Clock
+
+	mutex sync.Mutex
+	// resources holds the resources served by the server, keyed by groupVersion and then by lower-cased kind
+	resources map[string]map[string]metav1.APIResource
+	// lastChecked is the time we last queried discovery for the group versions we have selected
+	lastChecked time.Time
+}
+
+func NewAPIVersions(discoveryClient discovery.DiscoveryInterface) (*APIVersions, error) {
+	v := &APIVersions{
+		discovery: discoveryClient,
+		clock:     clock.RealClock{},
+	}
+	if err := v.Refresh(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Refresh queries discovery for the kinds the server supports
+func (v *APIVersions) Refresh() error {
+	resourceLists, err := v.discovery.ServerResources()
+	if err != nil {
+		return fmt.Errorf("failed to query server resources: %v", err)
+	}
+
+	resources := make(map[string]map[string]metav1.APIResource)
+	for _, resourceList := range resourceLists {
+		resources[resourceList.GroupVersion] = resourcesByKind(resourceList)
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.resources = resources
+	v.lastChecked = v.clock.Now()
+	return nil
+}
+
+// resourcesByKind indexes the resources in the list by lower-cased kind, ignoring subresources
+func resourcesByKind(resourceList *metav1.APIResourceList) map[string]metav1.APIResource {
+	resources := make(map[string]metav1.APIResource)
+	for _, res := range resourceList.APIResources {
+		if strings.Contains(res.Name, "/") {
+			continue
+		}
+		resources[strings.ToLower(res.Kind)] = res
+	}
+	return resources
+}
+
+// Find returns the resource for the kind in the group version, for kinds that are not built-in.
+// Group versions which were not served when we last queried discovery (e.g. CRDs created since) are queried directly.
+func (v *APIVersions) Find(apiVersion string, kind string) (*metav1.APIResource, error) {
+	if apiVersion == "" {
+		return nil, fmt.Errorf("apiVersion must be specified for kind %q", kind)
+	}
+
+	v.mutex.Lock()
+	resources, found := v.resources[apiVersion]
+	v.mutex.Unlock()
+
+	if !found {
+		resourceList, err := v.discovery.ServerResourcesForGroupVersion(apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error querying resources for %q: %v", apiVersion, err)
+		}
+		resources = resourcesByKind(resourceList)
+
+		v.mutex.Lock()
+		v.resources[apiVersion] = resources
+		v.mutex.Unlock()
+	}
+
+	res, found := resources[strings.ToLower(kind)]
+	if !found {
+		return nil, fmt.Errorf("kind %q not found in %q", kind, apiVersion)
+	}
+	if !res.Namespaced {
+		return nil, fmt.Errorf("kind %q in %q is not namespaced", kind, apiVersion)
+	}
+	return &res, nil
+}
+
+// Select returns the newest API which the server supports for the built-in kind
+func (v *APIVersions) Select(kind string) (*BuiltinAPI, error) {
+	k := builtinKinds[strings.ToLower(kind)]
+	if k == nil {
+		return nil, fmt.Errorf("unknown target kind: %s", kind)
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	for _, groupVersion := range k.GroupVersions {
+		if _, found := v.resources[groupVersion][strings.ToLower(k.Kind)]; !found {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(groupVersion)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing groupVersion %q: %v", groupVersion, err)
+		}
+		return &BuiltinAPI{
+			GroupVersion: gv,
+			Kind:         k.Kind,
+			Resource:     k.Resource,
+			PodSpecPath:  k.PodSpecPath,
+		}, nil
+	}
+	return nil, fmt.Errorf("no supported API group for %s (supported: %s)", k.Kind, strings.Join(k.GroupVersions, ","))
+}
+
+// Do calls fn with the API selected for the kind.  If fn fails with a NotFound error, that may be
+// because the group version has been removed from the server (e.g. on upgrade).  If discovery confirms
+// that the group version no longer serves the kind, we refresh discovery and retry with the newly selected
+// group version.  Usually the object simply doesn't exist, so we query discovery at most once per minRefreshInterval.
+func (v *APIVersions) Do(kind string, fn func(api *BuiltinAPI) error) error {
+	api, err := v.Select(kind)
+	if err != nil {
+		return err
+	}
+
+	err = fn(api)
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+
+	if !v.groupVersionRemoved(api) {
+		return err
+	}
+
+	if refreshErr := v.Refresh(); refreshErr != nil {
+		glog.Warningf("error refreshing API versions: %v", refreshErr)
+		return err
+	}
+	latest, selectErr := v.Select(kind)
+	if selectErr != nil {
+		return selectErr
+	}
+	if latest.GroupVersion == api.GroupVersion {
+		return err
+	}
+
+	glog.Infof("API for %s changed from %s to %s; retrying", api.Kind, api.GroupVersion, latest.GroupVersion)
+	return fn(latest)
+}
+
+// groupVersionRemoved returns true if the group version of the API no longer serves the kind.
+// It returns false without querying discovery if we queried it within minRefreshInterval.
+func (v *APIVersions) groupVersionRemoved(api *BuiltinAPI) bool {
+	v.mutex.Lock()
+	now := v.clock.Now()
+	if now.Sub(v.lastChecked) < minRefreshInterval {
+		v.mutex.Unlock()
+		return false
+	}
+	v.lastChecked = now
+	v.mutex.Unlock()
+
+	resourceList, err := v.discovery.ServerResourcesForGroupVersion(api.GroupVersion.String())
+	if err != nil {
+		// The server returns NotFound for a group version it no longer serves
+		glog.V(2).Infof("error querying resources for %s: %v", api.GroupVersion, err)
+		return true
+	}
+	_, found := resourcesByKind(resourceList)[strings.ToLower(api.Kind)]
+	return !found
+}
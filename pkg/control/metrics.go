@@ -0,0 +1,162 @@
+package control
+
+import (
+	"sort"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Results of patching a target, used as the result label on scaler_patches_total
+const (
+	patchResultApplied = "applied"
+	patchResultFailed  = "failed"
+	patchResultDryRun  = "dry_run"
+)
+
+// resourceLabels are the labels of the gauges for each container resource
+var resourceLabels = []string{"namespace", "policy", "container", "type", "resource"}
+
+var (
+	inputDesc = prometheus.NewDesc("scaler_input",
+		"Latest observed value of each input used by a policy",
+		[]string{"namespace", "policy", "input"}, nil)
+	targetDesc = prometheus.NewDesc("scaler_target",
+		"Computed target value for each container resource",
+		resourceLabels, nil)
+	scaleDownThresholdDesc = prometheus.NewDesc("scaler_scale_down_threshold",
+		"Computed value above which we scale down each container resource",
+		resourceLabels, nil)
+	scaleUpThresholdDesc = prometheus.NewDesc("scaler_scale_up_threshold",
+		"Computed value below which we scale up each container resource, when scale-up is delayed",
+		resourceLabels, nil)
+	actualDesc = prometheus.NewDesc("scaler_actual",
+		"Value of each container resource most recently read from the target",
+		resourceLabels, nil)
+)
+
+// stateMetrics holds the prometheus metrics for a State
+type stateMetrics struct {
+	state *State
+
+	patches       *prometheus.CounterVec
+	patchesDenied *prometheus.CounterVec
+	pollDuration  prometheus.Histogram
+	applyDuration prometheus.Histogram
+}
+
+var _ prometheus.Collector = &stateMetrics{}
+
+func newStateMetrics(state *State) *stateMetrics {
+	return &stateMetrics{
+		state: state,
+		patches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scaler_patches_total",
+			Help: "Number of patches to targets, by result (applied, failed or dry_run)",
+		}, []string{"namespace", "policy", "result"}),
+		patchesDenied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "scaler_patches_denied_total",
+			Help: "Number of patches to targets that were denied by the rate limits, by reason",
+		}, []string{"namespace", "policy", "reason"}),
+		pollDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "scaler_poll_duration_seconds",
+			Help: "Time taken to observe the inputs for all policies",
+		}),
+		applyDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "scaler_apply_duration_seconds",
+			Help: "Time taken to compute and apply the resources for all policies",
+		}),
+	}
+}
+
+// MetricsCollector returns the prometheus collector for the metrics of the State
+func (c *State) MetricsCollector() prometheus.Collector {
+	return c.metrics
+}
+
+func (m *stateMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.patches.Describe(ch)
+	m.patchesDenied.Describe(ch)
+	m.pollDuration.Describe(ch)
+	m.applyDuration.Describe(ch)
+
+	ch <- inputDesc
+	ch <- targetDesc
+	ch <- scaleDownThresholdDesc
+	ch <- scaleUpThresholdDesc
+	ch <- actualDesc
+}
+
+func (m *stateMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.patches.Collect(ch)
+	m.patchesDenied.Collect(ch)
+	m.pollDuration.Collect(ch)
+	m.applyDuration.Collect(ch)
+
+	// The per-policy gauges are computed when scraped, so we don't report policies that have been removed
+	m.state.collectPolicyMetrics(ch)
+}
+
+// collectPolicyMetrics builds the gauges for the inputs and resources of each policy
+func (c *State) collectPolicyMetrics(ch chan<- prometheus.Metric) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, p := range c.policies {
+		p.collectMetrics(ch)
+	}
+}
+
+// collectMetrics builds the gauges for the policy
+func (s *PolicyState) collectMetrics(ch chan<- prometheus.Metric) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	namespace := s.policy.Namespace
+	name := s.policy.Name
+
+	for _, in := range s.status.Inputs {
+		ch <- prometheus.MustNewConstMetric(inputDesc, prometheus.GaugeValue, quantityToFloat(in.Value), namespace, name, in.Name)
+	}
+
+	info := s.evaluator.Query()
+	collectPodSpecMetrics(ch, targetDesc, namespace, name, info.LatestTarget)
+	collectPodSpecMetrics(ch, scaleDownThresholdDesc, namespace, name, info.ScaleDownThreshold)
+	collectPodSpecMetrics(ch, scaleUpThresholdDesc, namespace, name, info.ScaleUpThreshold)
+	collectPodSpecMetrics(ch, actualDesc, namespace, name, s.actual)
+}
+
+// collectPodSpecMetrics builds a gauge for each resource of each container & init container in the PodSpec
+func collectPodSpecMetrics(ch chan<- prometheus.Metric, desc *prometheus.Desc, namespace, policy string, podSpec *corev1.PodSpec) {
+	if podSpec == nil {
+		return
+	}
+	for _, containerType := range scalingpolicy.ContainerTypes {
+		containers := *containerType.ContainersIn(podSpec)
+		for i := range containers {
+			container := &containers[i]
+			collectResourceListMetrics(ch, desc, namespace, policy, container.Name, "limits", container.Resources.Limits)
+			collectResourceListMetrics(ch, desc, namespace, policy, container.Name, "requests", container.Resources.Requests)
+		}
+	}
+}
+
+func collectResourceListMetrics(ch chan<- prometheus.Metric, desc *prometheus.Desc, namespace, policy, container, resourceType string, resources corev1.ResourceList) {
+	var names []string
+	for k := range resources {
+		names = append(names, string(k))
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		v := quantityToFloat(resources[corev1.ResourceName(k)])
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, namespace, policy, container, resourceType, k)
+	}
+}
+
+// quantityToFloat converts the quantity to a float in base units (e.g. cores or bytes)
+func quantityToFloat(q resource.Quantity) float64 {
+	return float64(q.MilliValue()) / 1000.0
+}
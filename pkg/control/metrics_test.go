@@ -0,0 +1,90 @@
+package control
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/cmd/scaler/options"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// gatherMetrics returns the metrics of the state in the prometheus text format
+func gatherMetrics(t *testing.T, state *State) string {
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(state.MetricsCollector()); err != nil {
+		t.Fatalf("error registering metrics: %v", err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("error gathering metrics: %v", err)
+	}
+
+	var b bytes.Buffer
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(&b, family); err != nil {
+			t.Fatalf("error writing metrics: %v", err)
+		}
+	}
+	return b.String()
+}
+
+func TestMetrics(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	universe := target.NewSimulationTarget()
+	universe.Current = &v1.PodSpec{
+		Containers: []v1.Container{{Name: "container1"}},
+	}
+
+	state, err := NewState(fakeClock, universe, static.NewStaticFactors(fakeClock, map[string]float64{"nodes": 10}), options.NewAutoScalerConfig())
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+
+	policy := buildPolicyUsingInput("nodes")
+	policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	rule := &policy.Spec.Containers[0].Resources.Limits[0]
+	rule.Function.Base = resource.MustParse("100Mi")
+	rule.Function.Slope = resource.MustParse("1Mi")
+	state.upsert(policy, nil)
+
+	if err := state.makeObservation(); err != nil {
+		t.Fatalf("error observing: %v", err)
+	}
+	if err := state.applyPolicies(); err != nil {
+		t.Fatalf("error applying: %v", err)
+	}
+	if err := state.applyPolicies(); err != nil {
+		t.Fatalf("error applying: %v", err)
+	}
+
+	text := gatherMetrics(t, state)
+
+	for _, expected := range []string{
+		`scaler_input{input="nodes",namespace="ns1",policy="policy1"} 10`,
+		`scaler_target{container="container1",namespace="ns1",policy="policy1",resource="memory",type="limits"} 1.1534336e+08`,
+		`scaler_actual{container="container1",namespace="ns1",policy="policy1",resource="memory",type="limits"} 1.1534336e+08`,
+		`scaler_patches_total{namespace="ns1",policy="policy1",result="applied"} 1`,
+		`scaler_poll_duration_seconds_count 1`,
+		`scaler_apply_duration_seconds_count 2`,
+	} {
+		if !strings.Contains(text, expected+"\n") {
+			t.Errorf("expected metrics to contain %q; got:\n%s", expected, text)
+		}
+	}
+
+	// Metrics for removed policies should no longer be reported
+	state.remove("ns1", "policy1")
+	if text := gatherMetrics(t, state); strings.Contains(text, "scaler_target{") {
+		t.Errorf("unexpected target metrics for removed policy:\n%s", text)
+	}
+}
@@ -2,7 +2,9 @@ package control
 
 import (
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/justinsb/scaler/cmd/scaler/options"
@@ -10,6 +12,10 @@ import (
 	"github.com/justinsb/scaler/pkg/control/target"
 	"github.com/justinsb/scaler/pkg/factors"
 	"github.com/justinsb/scaler/pkg/scaling"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 // PolicyState is the state around a single scaling policy
@@ -22,6 +28,28 @@ type PolicyState struct {
 	policy *scalingpolicy.ScalingPolicy
 
 	evaluator *scaling.ScalingPolicyEvaluator
+
+	// inputs holds the named inputs defined by the policy, or inputsError if they are not valid
+	inputs      map[string]*factors.Input
+	inputsError error
+
+	// validationErrors holds the errors from validating the policy; we don't evaluate or apply an invalid policy
+	validationErrors field.ErrorList
+
+	// status holds the observations we report in the ScalingPolicy status
+	status scalingpolicy.ScalingPolicyStatus
+
+	// actual holds the PodSpec we most recently read from the target
+	actual *corev1.PodSpec
+
+	// rolloutStarted is the time at which we patched the target, while we are waiting for the rollout to converge.
+	// It is only held in memory: after a restart (or a change of leader) we don't wait for a rollout started
+	// by the previous process, because we can't tell whether it was our patch or another change that started it.
+	rolloutStarted time.Time
+
+	// missingContainers and deferredScaleDowns record what we have already reported in events, so we only report changes
+	missingContainers  map[string]bool
+	deferredScaleDowns map[string]bool
 }
 
 func NewPolicyState(parent *State, policy *scalingpolicy.ScalingPolicy) *PolicyState {
@@ -33,16 +61,73 @@ func NewPolicyState(parent *State, policy *scalingpolicy.ScalingPolicy) *PolicyS
 	}
 
 	s.evaluator = scaling.NewScalingPolicyEvaluator(parent.clock, policy)
+	s.inputs, s.inputsError = buildInputs(policy)
+
+	// Restore the windows from before a restart, so that we don't wait for the delays again
+	if parent.checkpoints != nil {
+		checkpoint, err := parent.checkpoints.Load(policy)
+		if err != nil {
+			glog.Warningf("error loading checkpoint for %s/%s: %v", policy.Namespace, policy.Name, err)
+		} else if checkpoint != nil {
+			s.evaluator.Restore(checkpoint)
+		}
+	}
 
 	return s
 }
 
+// checkpoint returns the policy and the evaluator state, or a nil checkpoint if there is nothing to persist
+func (s *PolicyState) checkpoint() (*scalingpolicy.ScalingPolicy, *scaling.Checkpoint) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	checkpoint := s.evaluator.Checkpoint()
+	if len(checkpoint.Rules) == 0 {
+		return s.policy, nil
+	}
+	return s.policy, checkpoint
+}
+
 func (s *PolicyState) updatePolicy(o *scalingpolicy.ScalingPolicy) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	s.policy = o
 	s.evaluator.UpdatePolicy(o)
+	s.inputs, s.inputsError = buildInputs(o)
+}
+
+// setValidationErrors records the result of validating the policy, updating the Valid condition
+func (s *PolicyState) setValidationErrors(errs field.ErrorList) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := metav1.NewTime(s.parent.clock.Now())
+	s.validationErrors = errs
+	if len(errs) != 0 {
+		setCondition(&s.status.Conditions, now, scalingpolicy.Valid, corev1.ConditionFalse, "Invalid", errs.ToAggregate().Error())
+	} else {
+		setCondition(&s.status.Conditions, now, scalingpolicy.Valid, corev1.ConditionTrue, "Validated", "")
+	}
+}
+
+// buildTargetRef returns the reference to the target of the policy
+func buildTargetRef(policy *scalingpolicy.ScalingPolicy) *target.Ref {
+	return &target.Ref{
+		APIVersion:  policy.Spec.ScaleTargetRef.APIVersion,
+		Kind:        policy.Spec.ScaleTargetRef.Kind,
+		Namespace:   policy.Namespace,
+		Name:        policy.Spec.ScaleTargetRef.Name,
+		PodSpecPath: policy.Spec.PodSpecPath,
+	}
+}
+
+// targetKey returns the key identifying the target, for the patch limiter
+func (s *PolicyState) targetKey() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return buildTargetRef(s.policy).String()
 }
 
 // addObservation is called whenever we observe a set of input values
@@ -60,41 +145,272 @@ func (s *PolicyState) addObservation(snapshot factors.Snapshot) {
 
 	glog.V(4).Infof("adding observation for %s", path)
 
+	if len(s.validationErrors) != 0 {
+		glog.V(4).Infof("not observing inputs for invalid policy %s", path)
+		return
+	}
+
+	now := metav1.NewTime(s.parent.clock.Now())
+	if s.inputsError != nil {
+		glog.Warningf("invalid inputs for %s: %v", path, s.inputsError)
+		setCondition(&s.status.Conditions, now, scalingpolicy.InputsAvailable, corev1.ConditionFalse, "InvalidInputs", s.inputsError.Error())
+		return
+	}
+	snapshot = factors.WithInputs(snapshot, s.inputs)
+
+	inputs, missing, err := readInputValues(snapshot, policyInputs(policy))
+	if err != nil {
+		if factors.IsNotSynced(err) {
+			// Informers for object inputs are started when a policy first uses them; we skip
+			// the sample until they have synced, rather than reporting an error
+			glog.V(2).Infof("skipping observation for %s: %v", path, err)
+			return
+		}
+		glog.Warningf("error reading inputs for %s: %v", path, err)
+		setCondition(&s.status.Conditions, now, scalingpolicy.InputsAvailable, corev1.ConditionFalse, "ReadFailed", err.Error())
+		return
+	}
+
 	s.evaluator.AddObservation(snapshot)
+
+	timestamp := metav1.NewTime(snapshot.Timestamp())
+	s.status.Inputs = inputs
+	s.status.InputsTime = &timestamp
+	if len(missing) != 0 {
+		setCondition(&s.status.Conditions, now, scalingpolicy.InputsAvailable, corev1.ConditionFalse, "InputsNotFound", fmt.Sprintf("inputs not found: %s", strings.Join(missing, ",")))
+	} else {
+		setCondition(&s.status.Conditions, now, scalingpolicy.InputsAvailable, corev1.ConditionTrue, "InputsObserved", "")
+	}
 }
 
 func (s *PolicyState) updateValues() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	policy := s.policy
+	ref := buildTargetRef(s.policy)
+	path := ref.String()
 
-	kind := policy.Spec.ScaleTargetRef.Kind
-	namespace := policy.Namespace
-	name := policy.Spec.ScaleTargetRef.Name
+	now := metav1.NewTime(s.parent.clock.Now())
 
-	path := fmt.Sprintf("%s/%s/%s", kind, namespace, name)
+	if len(s.validationErrors) != 0 {
+		glog.V(4).Infof("not applying invalid policy for %s", path)
+		setCondition(&s.status.Conditions, now, scalingpolicy.Applied, corev1.ConditionFalse, "Invalid", "the policy is not valid")
+		return nil
+	}
 
-	actual, err := s.target.Read(kind, namespace, name)
+	actual, err := s.target.Read(ref)
 	if err != nil {
-		// TODO: Emit event?
+		if errors.IsNotFound(err) {
+			if !hasCondition(s.status.Conditions, scalingpolicy.TargetFound, corev1.ConditionFalse, TargetNotFound) {
+				s.recordEvent(nil, corev1.EventTypeWarning, TargetNotFound, fmt.Sprintf("Target %s not found: %v", path, err))
+			}
+			setCondition(&s.status.Conditions, now, scalingpolicy.TargetFound, corev1.ConditionFalse, TargetNotFound, err.Error())
+		} else {
+			setCondition(&s.status.Conditions, now, scalingpolicy.TargetFound, corev1.ConditionFalse, "ReadFailed", err.Error())
+		}
 		return err
 	}
+	setCondition(&s.status.Conditions, now, scalingpolicy.TargetFound, corev1.ConditionTrue, "TargetRead", "")
+	s.actual = actual
+
+	s.checkContainers(ref, actual)
+
+	rolloutInProgress := s.checkRollout(ref, now)
 
 	changes, err := s.evaluator.ComputeResources(path, actual)
 	if err != nil {
 		return err
 	}
+	changes = s.withoutMissingContainers(changes)
+
+	s.checkDeferredScaleDowns(ref, actual, changes)
+
+	if changes != nil && rolloutInProgress {
+		// Overlapping rollouts can leave the target with partially-available replicas
+		glog.Infof("deferring update to %s until the previous rollout has converged", path)
+		setCondition(&s.status.Conditions, now, scalingpolicy.Applied, corev1.ConditionFalse, "RolloutInProgress", "waiting for the previous rollout to converge")
+		return nil
+	}
+
+	if changes != nil && !s.options.DryRun {
+		if ok, reason, message := s.parent.limiter.allow(now.Time, path); !ok {
+			glog.Infof("deferring update to %s: %s", path, message)
+			s.parent.metrics.patchesDenied.WithLabelValues(s.policy.Namespace, s.policy.Name, reason).Inc()
+			if !hasCondition(s.status.Conditions, scalingpolicy.Applied, corev1.ConditionFalse, reason) {
+				s.parent.eventf(s.policy, corev1.EventTypeNormal, "RateLimited", "Deferred update to %s: %s", path, message)
+			}
+			setCondition(&s.status.Conditions, now, scalingpolicy.Applied, corev1.ConditionFalse, reason, message)
+			return nil
+		}
+	}
 
 	if changes != nil {
-		if err := s.target.UpdateResources(kind, namespace, name, changes, s.options.DryRun); err != nil {
-			glog.Warningf("failed to update %q: %v", kind, err)
+		if err := s.target.UpdateResources(ref, changes, s.options.DryRun); err != nil {
+			glog.Warningf("failed to update %s: %v", path, err)
+			s.parent.metrics.patches.WithLabelValues(s.policy.Namespace, s.policy.Name, patchResultFailed).Inc()
+			if !hasCondition(s.status.Conditions, scalingpolicy.Applied, corev1.ConditionFalse, PatchFailed) {
+				s.recordEvent(ref, corev1.EventTypeWarning, PatchFailed, fmt.Sprintf("Failed to patch %s: %v", path, err))
+			}
+			setCondition(&s.status.Conditions, now, scalingpolicy.Applied, corev1.ConditionFalse, PatchFailed, err.Error())
 		} else {
 			glog.V(4).Infof("applied update to %s", path)
+			if s.options.DryRun {
+				s.parent.metrics.patches.WithLabelValues(s.policy.Namespace, s.policy.Name, patchResultDryRun).Inc()
+				setCondition(&s.status.Conditions, now, scalingpolicy.Applied, corev1.ConditionFalse, "DryRun", "changes computed but not applied in dry-run mode")
+			} else {
+				s.parent.metrics.patches.WithLabelValues(s.policy.Namespace, s.policy.Name, patchResultApplied).Inc()
+				s.parent.limiter.recordPatch(now.Time, path)
+				s.rolloutStarted = now.Time
+				s.status.LastAppliedTime = &now
+				s.evaluator.RecordApplied(actual, changes)
+				setCondition(&s.status.Conditions, now, scalingpolicy.Applied, corev1.ConditionTrue, "Patched", "")
+				s.recordScalingEvents(ref, actual, changes)
+			}
 		}
 	} else {
 		glog.V(4).Infof("no change needed for %s", path)
+		setCondition(&s.status.Conditions, now, scalingpolicy.Applied, corev1.ConditionTrue, "UpToDate", "")
 	}
 
 	return nil
 }
+
+// recordScalingEvents records ScaledUp and ScaledDown events for the changes we applied to the target
+func (s *PolicyState) recordScalingEvents(ref *target.Ref, actual *corev1.PodSpec, changes *corev1.PodSpec) {
+	var up, down []resourceChange
+	for _, change := range buildResourceChanges(actual, changes, false) {
+		if change.isIncrease() {
+			up = append(up, change)
+		} else {
+			down = append(down, change)
+		}
+	}
+
+	inputs := formatInputs(s.status.Inputs)
+	if len(up) != 0 {
+		s.recordEvent(ref, corev1.EventTypeNormal, ScaledUp, fmt.Sprintf("Scaled up %s (%s)", formatChanges(up), inputs))
+	}
+	if len(down) != 0 {
+		s.recordEvent(ref, corev1.EventTypeNormal, ScaledDown, fmt.Sprintf("Scaled down %s (%s)", formatChanges(down), inputs))
+	}
+}
+
+// checkContainers records a ContainerNotFound event for each container in the policy that is newly missing from the target
+func (s *PolicyState) checkContainers(ref *target.Ref, actual *corev1.PodSpec) {
+	missing := make(map[string]bool)
+	for i := range s.policy.Spec.Containers {
+		rule := &s.policy.Spec.Containers[i]
+		name := rule.Name
+		if findContainer(actual, rule.ContainerType, name) != nil {
+			continue
+		}
+		missing[name] = true
+		if !s.missingContainers[name] {
+			glog.Warningf("container %q not found in %s", name, ref)
+			s.recordEvent(ref, corev1.EventTypeWarning, ContainerNotFound, fmt.Sprintf("Container %q not found in %s", name, ref))
+		}
+	}
+	s.missingContainers = missing
+}
+
+// withoutMissingContainers removes the containers that are missing from the target, so that we can still patch the others
+func (s *PolicyState) withoutMissingContainers(changes *corev1.PodSpec) *corev1.PodSpec {
+	if changes == nil || len(s.missingContainers) == 0 {
+		return changes
+	}
+
+	for _, containerType := range scalingpolicy.ContainerTypes {
+		list := containerType.ContainersIn(changes)
+		var containers []corev1.Container
+		for _, c := range *list {
+			if !s.missingContainers[c.Name] {
+				containers = append(containers, c)
+			}
+		}
+		*list = containers
+	}
+	if len(changes.Containers) == 0 && len(changes.InitContainers) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// checkDeferredScaleDowns records a ScaleDownDeferred event when the target has newly gone over the computed value,
+// but we are not scaling down, because of delayScaleDown.
+func (s *PolicyState) checkDeferredScaleDowns(ref *target.Ref, actual *corev1.PodSpec, changes *corev1.PodSpec) {
+	changing := make(map[string]bool)
+	for _, change := range buildResourceChanges(actual, changes, false) {
+		changing[change.key()] = true
+	}
+
+	var newlyDeferred []resourceChange
+	deferred := make(map[string]bool)
+	for _, change := range buildResourceChanges(actual, s.evaluator.Query().LatestTarget, true) {
+		if change.from == nil || changing[change.key()] {
+			continue
+		}
+		deferred[change.key()] = true
+		if !s.deferredScaleDowns[change.key()] {
+			newlyDeferred = append(newlyDeferred, change)
+		}
+	}
+	s.deferredScaleDowns = deferred
+
+	if len(newlyDeferred) != 0 {
+		s.recordEvent(ref, corev1.EventTypeNormal, ScaleDownDeferred, fmt.Sprintf("Deferred scaling down %s until the scale-down delay has passed (%s)", formatChanges(newlyDeferred), formatInputs(s.status.Inputs)))
+	}
+}
+
+// checkRollout checks the progress of the rollout of our last patch to the target, updating the RolloutComplete condition.
+// It returns true if the rollout is still in progress, and we should not apply another patch.
+// If the rollout has not converged after the rollout timeout, we consider it stalled, and no longer wait for it
+// (or count it against the concurrent rollouts), but we keep reporting its progress until it converges or we patch again.
+func (s *PolicyState) checkRollout(ref *target.Ref, now metav1.Time) bool {
+	if s.rolloutStarted.IsZero() {
+		return false
+	}
+	path := ref.String()
+
+	status, err := s.target.ReadRolloutStatus(ref)
+	if err != nil {
+		glog.Warningf("error reading rollout status of %s: %v", path, err)
+		status = &target.RolloutStatus{Message: fmt.Sprintf("error reading rollout status: %v", err)}
+	}
+
+	if status == nil || status.Converged {
+		glog.V(4).Infof("rollout of %s has converged", path)
+		s.rolloutStarted = time.Time{}
+		s.parent.limiter.finishRollout(path)
+		setCondition(&s.status.Conditions, now, scalingpolicy.RolloutComplete, corev1.ConditionTrue, "RolloutComplete", "")
+		return false
+	}
+
+	elapsed := now.Sub(s.rolloutStarted)
+	if elapsed < s.options.RolloutTimeout {
+		setCondition(&s.status.Conditions, now, scalingpolicy.RolloutComplete, corev1.ConditionFalse, "RolloutInProgress", status.Message)
+		return true
+	}
+
+	s.parent.limiter.finishRollout(path)
+	message := fmt.Sprintf("rollout has not converged after %s: %s", elapsed, status.Message)
+	if !hasCondition(s.status.Conditions, scalingpolicy.RolloutComplete, corev1.ConditionFalse, "RolloutStalled") {
+		glog.Warningf("rollout of %s has stalled: %s", path, message)
+		s.parent.eventf(s.policy, corev1.EventTypeWarning, "RolloutStalled", "Rollout of %s stalled: %s", path, message)
+	}
+	setCondition(&s.status.Conditions, now, scalingpolicy.RolloutComplete, corev1.ConditionFalse, "RolloutStalled", message)
+	return false
+}
+
+// buildStatus returns the status we should report for the policy
+func (s *PolicyState) buildStatus() *scalingpolicy.ScalingPolicyStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status := s.status.DeepCopy()
+	status.ObservedGeneration = s.policy.Generation
+
+	info := s.evaluator.Query()
+	status.Containers = buildContainerStatuses(info.LatestTarget, info.ScaleDownThreshold, info.ScaleUpThreshold)
+
+	return status
+}
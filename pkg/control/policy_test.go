@@ -0,0 +1,429 @@
+package control
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/cmd/scaler/options"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/apis/scalingpolicy/validation"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"github.com/justinsb/scaler/pkg/factors"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestPatchWaitsForRollout(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	universe := target.NewSimulationTarget()
+	universe.Current = &v1.PodSpec{
+		Containers: []v1.Container{{Name: "container1"}},
+	}
+
+	config := options.NewAutoScalerConfig()
+	config.RolloutTimeout = 10 * time.Minute
+
+	inputs := map[string]float64{"nodes": 10}
+	state, err := NewState(fakeClock, universe, static.NewStaticFactors(fakeClock, inputs), config)
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	state.recorder = recorder
+
+	policy := buildPolicyUsingInput("nodes")
+	policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	rule := &policy.Spec.Containers[0].Resources.Limits[0]
+	rule.Function.Slope = resource.MustParse("1Mi")
+	state.upsert(policy, nil)
+
+	key := types.NamespacedName{Namespace: "ns1", Name: "policy1"}
+	apply := func(expectedUpdates int, conditionType scalingpolicy.ScalingPolicyConditionType, status v1.ConditionStatus, reason string) {
+		if err := state.makeObservation(); err != nil {
+			t.Fatalf("error observing: %v", err)
+		}
+		if err := state.applyPolicies(); err != nil {
+			t.Fatalf("error applying: %v", err)
+		}
+		if universe.UpdateCount != expectedUpdates {
+			t.Fatalf("expected %d updates, got %d", expectedUpdates, universe.UpdateCount)
+		}
+		conditions := state.statuses()[key].Conditions
+		if !hasCondition(conditions, conditionType, status, reason) {
+			t.Fatalf("expected %s condition with status %s and reason %s, got %v", conditionType, status, reason, conditions)
+		}
+	}
+
+	apply(1, scalingpolicy.Applied, v1.ConditionTrue, "Patched")
+	drainEvents(recorder)
+
+	// While the rollout is in progress, we defer the next patch
+	universe.Rollout = &target.RolloutStatus{Message: "1 of 3 replicas updated"}
+	inputs["nodes"] = 20
+	fakeClock.Step(time.Minute)
+	apply(1, scalingpolicy.Applied, v1.ConditionFalse, "RolloutInProgress")
+	apply(1, scalingpolicy.RolloutComplete, v1.ConditionFalse, "RolloutInProgress")
+
+	// Once the rollout has converged, we apply the patch
+	universe.Rollout = &target.RolloutStatus{Converged: true}
+	fakeClock.Step(time.Minute)
+	apply(2, scalingpolicy.RolloutComplete, v1.ConditionTrue, "RolloutComplete")
+	drainEvents(recorder)
+
+	// If a rollout stalls, we report it and stop waiting after the timeout
+	universe.Rollout = &target.RolloutStatus{Message: "2 of 3 updated replicas available"}
+	inputs["nodes"] = 30
+	fakeClock.Step(time.Minute)
+	apply(2, scalingpolicy.Applied, v1.ConditionFalse, "RolloutInProgress")
+	if len(recorder.Events) != 0 {
+		t.Errorf("unexpected event %q", <-recorder.Events)
+	}
+
+	fakeClock.Step(10 * time.Minute)
+	apply(3, scalingpolicy.Applied, v1.ConditionTrue, "Patched")
+	events := drainEvents(recorder)
+	if len(events) == 0 || !strings.HasPrefix(events[0], "Warning RolloutStalled ") {
+		t.Errorf("expected RolloutStalled event, got %q", events)
+	}
+
+	// A stalled rollout no longer counts against the concurrent rollouts, even if we have nothing to patch
+	fakeClock.Step(11 * time.Minute)
+	apply(3, scalingpolicy.RolloutComplete, v1.ConditionFalse, "RolloutStalled")
+	if len(state.limiter.rollouts) != 0 {
+		t.Errorf("expected stalled rollout to be finished, got %v", state.limiter.rollouts)
+	}
+}
+
+func TestScalingEvents(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	universe := target.NewSimulationTarget()
+	universe.Current = &v1.PodSpec{
+		Containers: []v1.Container{
+			{
+				Name: "container1",
+				Resources: v1.ResourceRequirements{
+					Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("5Mi")},
+				},
+			},
+		},
+	}
+
+	inputs := map[string]float64{"nodes": 10}
+	state, err := NewState(fakeClock, universe, static.NewStaticFactors(fakeClock, inputs), options.NewAutoScalerConfig())
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	state.recorder = recorder
+
+	policy := buildPolicyUsingInput("nodes")
+	policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	policy.Spec.ScaleTargetRef.Name = "deployment1"
+	rule := &policy.Spec.Containers[0].Resources.Limits[0]
+	rule.Function.Slope = resource.MustParse("1Mi")
+	rule.Function.DelayScaleDown = &scalingpolicy.DelayScaling{DelaySeconds: 600}
+	missing := *policy.Spec.Containers[0].DeepCopy()
+	missing.Name = "container2"
+	policy.Spec.Containers = append(policy.Spec.Containers, missing)
+	state.upsert(policy, nil)
+
+	grid := []struct {
+		Step   time.Duration
+		Nodes  float64
+		Events []string
+	}{
+		{
+			Nodes: 10,
+			Events: []string{
+				`Warning ContainerNotFound Container "container2" not found in Deployment/ns1/deployment1`,
+				`Warning ContainerNotFound Container "container2" not found in Deployment/ns1/deployment1 (ScalingPolicy policy1)`,
+				"Normal ScaledUp Scaled up container1 limits.memory 5Mi -> 10Mi (inputs nodes=10)",
+				"Normal ScaledUp Scaled up container1 limits.memory 5Mi -> 10Mi (inputs nodes=10) (ScalingPolicy policy1)",
+			},
+		},
+		{
+			Step:  time.Minute,
+			Nodes: 5,
+			Events: []string{
+				"Normal ScaleDownDeferred Deferred scaling down container1 limits.memory 10Mi -> 5Mi until the scale-down delay has passed (inputs nodes=5)",
+				"Normal ScaleDownDeferred Deferred scaling down container1 limits.memory 10Mi -> 5Mi until the scale-down delay has passed (inputs nodes=5) (ScalingPolicy policy1)",
+			},
+		},
+		{
+			// We only report changes
+			Step:  time.Minute,
+			Nodes: 5,
+		},
+		{
+			Step:  10 * time.Minute,
+			Nodes: 5,
+			Events: []string{
+				"Normal ScaledDown Scaled down container1 limits.memory 10Mi -> 5Mi (inputs nodes=5)",
+				"Normal ScaledDown Scaled down container1 limits.memory 10Mi -> 5Mi (inputs nodes=5) (ScalingPolicy policy1)",
+			},
+		},
+	}
+
+	for i, g := range grid {
+		fakeClock.Step(g.Step)
+		inputs["nodes"] = g.Nodes
+		if err := state.makeObservation(); err != nil {
+			t.Fatalf("error observing: %v", err)
+		}
+		if err := state.applyPolicies(); err != nil {
+			t.Fatalf("error applying: %v", err)
+		}
+
+		events := drainEvents(recorder)
+		if strings.Join(events, "\n") != strings.Join(g.Events, "\n") {
+			t.Errorf("test failure\nstep=%d\n  actual=%q\nexpected=%q", i, events, g.Events)
+		}
+	}
+
+	// When the target is deleted, we can only record the event on the policy
+	universe.Current = nil
+	for i := 0; i < 2; i++ {
+		fakeClock.Step(time.Minute)
+		if err := state.applyPolicies(); err != nil {
+			t.Fatalf("error applying: %v", err)
+		}
+	}
+	events := drainEvents(recorder)
+	if len(events) != 1 || !strings.HasPrefix(events[0], "Warning TargetNotFound Target Deployment/ns1/deployment1 not found: ") {
+		t.Errorf("unexpected events %q", events)
+	}
+}
+
+func TestInvalidPolicyIsNotApplied(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	universe := target.NewSimulationTarget()
+	universe.Current = &v1.PodSpec{
+		Containers: []v1.Container{{Name: "container1"}},
+	}
+
+	inputs := map[string]float64{"nodes": 10}
+	state, err := NewState(fakeClock, universe, static.NewStaticFactors(fakeClock, inputs), options.NewAutoScalerConfig())
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+
+	policy := buildPolicyUsingInput("nodes")
+	policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	policy.Spec.ScaleTargetRef.Name = "deployment1"
+	rule := &policy.Spec.Containers[0].Resources.Limits[0]
+	rule.Function.Slope = resource.MustParse("1Mi")
+	rule.Function.Segments = []scalingpolicy.ResourceScalingSegment{{Every: 0}}
+	state.upsert(policy, validation.ValidateScalingPolicy(policy))
+
+	key := types.NamespacedName{Namespace: "ns1", Name: "policy1"}
+	apply := func() {
+		fakeClock.Step(time.Minute)
+		if err := state.makeObservation(); err != nil {
+			t.Fatalf("error observing: %v", err)
+		}
+		if err := state.applyPolicies(); err != nil {
+			t.Fatalf("error applying: %v", err)
+		}
+	}
+
+	apply()
+	if universe.UpdateCount != 0 {
+		t.Errorf("expected invalid policy not to be applied, got %d updates", universe.UpdateCount)
+	}
+	conditions := state.statuses()[key].Conditions
+	if !hasCondition(conditions, scalingpolicy.Valid, v1.ConditionFalse, "Invalid") {
+		t.Errorf("expected Valid condition to be false, got %v", conditions)
+	}
+
+	// Once the policy is fixed, it is applied
+	policy = policy.DeepCopy()
+	policy.Spec.Containers[0].Resources.Limits[0].Function.Segments[0].Every = 1
+	state.upsert(policy, validation.ValidateScalingPolicy(policy))
+	apply()
+	if universe.UpdateCount != 1 {
+		t.Errorf("expected valid policy to be applied, got %d updates", universe.UpdateCount)
+	}
+	conditions = state.statuses()[key].Conditions
+	if !hasCondition(conditions, scalingpolicy.Valid, v1.ConditionTrue, "Validated") {
+		t.Errorf("expected Valid condition to be true, got %v", conditions)
+	}
+}
+
+// notSyncedFactors reports every input as not yet synced, until synced is set
+type notSyncedFactors struct {
+	factors.Interface
+	synced bool
+}
+
+func (f *notSyncedFactors) Snapshot() (factors.Snapshot, error) {
+	snapshot, err := f.Interface.Snapshot()
+	if err != nil || f.synced {
+		return snapshot, err
+	}
+	return &notSyncedSnapshot{snapshot}, nil
+}
+
+type notSyncedSnapshot struct {
+	factors.Snapshot
+}
+
+func (s *notSyncedSnapshot) Get(key string) (float64, bool, error) {
+	return 0, true, &factors.NotSyncedError{Input: key}
+}
+
+func TestInputsNotSyncedAreSkipped(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	universe := target.NewSimulationTarget()
+	universe.Current = &v1.PodSpec{
+		Containers: []v1.Container{{Name: "container1"}},
+	}
+
+	inputs := &notSyncedFactors{Interface: static.NewStaticFactors(fakeClock, map[string]float64{"nodes": 10})}
+	state, err := NewState(fakeClock, universe, inputs, options.NewAutoScalerConfig())
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+
+	policy := buildPolicyUsingInput("nodes")
+	policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	rule := &policy.Spec.Containers[0].Resources.Limits[0]
+	rule.Function.Slope = resource.MustParse("1Mi")
+	state.upsert(policy, nil)
+
+	key := types.NamespacedName{Namespace: "ns1", Name: "policy1"}
+	apply := func() {
+		fakeClock.Step(time.Minute)
+		if err := state.makeObservation(); err != nil {
+			t.Fatalf("error observing: %v", err)
+		}
+		if err := state.applyPolicies(); err != nil {
+			t.Fatalf("error applying: %v", err)
+		}
+	}
+
+	// Until the informer has synced, we skip the sample without reporting an error
+	apply()
+	if universe.UpdateCount != 0 {
+		t.Errorf("expected no updates before inputs have synced, got %d", universe.UpdateCount)
+	}
+	conditions := state.statuses()[key].Conditions
+	if hasCondition(conditions, scalingpolicy.InputsAvailable, v1.ConditionFalse, "ReadFailed") {
+		t.Errorf("unexpected ReadFailed condition before inputs have synced: %v", conditions)
+	}
+
+	inputs.synced = true
+	apply()
+	if universe.UpdateCount != 1 {
+		t.Errorf("expected update once inputs have synced, got %d", universe.UpdateCount)
+	}
+	conditions = state.statuses()[key].Conditions
+	if !hasCondition(conditions, scalingpolicy.InputsAvailable, v1.ConditionTrue, "InputsObserved") {
+		t.Errorf("expected InputsAvailable condition to be true, got %v", conditions)
+	}
+}
+
+// drainEvents returns and removes all the events recorded so far
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for len(recorder.Events) != 0 {
+		events = append(events, <-recorder.Events)
+	}
+	return events
+}
+
+func TestCooldownAfterAppliedChange(t *testing.T) {
+	grid := []struct {
+		Name           string
+		DelayScaleDown *scalingpolicy.DelayScaling
+		DelayScaleUp   *scalingpolicy.DelayScaling
+		// Nodes returns the number of nodes at each step; steps are 10 seconds apart
+		Nodes func(step int) float64
+		// FailAt is the step at which patching the target fails, if any
+		FailAt int
+		// Expected holds the values we apply, by step
+		Expected map[int]string
+	}{
+		{
+			Name:           "scale-down waits for the delay after each scale-down",
+			DelayScaleDown: &scalingpolicy.DelayScaling{DelaySeconds: 60},
+			Nodes:          func(step int) float64 { return float64(20 - step) },
+			Expected:       map[int]string{0: "200Mi", 7: "190Mi", 14: "120Mi"},
+		},
+		{
+			Name:           "a failed patch does not start the cooldown",
+			DelayScaleDown: &scalingpolicy.DelayScaling{DelaySeconds: 60},
+			Nodes:          func(step int) float64 { return float64(20 - step) },
+			FailAt:         7,
+			Expected:       map[int]string{0: "200Mi", 8: "180Mi", 15: "110Mi"},
+		},
+		{
+			Name:         "scale-up waits for the delay after each scale-up",
+			DelayScaleUp: &scalingpolicy.DelayScaling{DelaySeconds: 60},
+			Nodes:        func(step int) float64 { return float64(10 + step) },
+			Expected:     map[int]string{0: "100Mi", 7: "110Mi", 14: "180Mi"},
+		},
+	}
+
+	for _, g := range grid {
+		fakeClock := clock.NewFakeClock(time.Now())
+		universe := target.NewSimulationTarget()
+		universe.Current = &v1.PodSpec{
+			Containers: []v1.Container{{Name: "container1"}},
+		}
+
+		inputs := map[string]float64{}
+		state, err := NewState(fakeClock, universe, static.NewStaticFactors(fakeClock, inputs), options.NewAutoScalerConfig())
+		if err != nil {
+			t.Fatalf("error building state: %v", err)
+		}
+
+		policy := buildPolicyUsingInput("nodes")
+		policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"}
+		policy.Spec.ScaleTargetRef.Kind = "Deployment"
+		rule := &policy.Spec.Containers[0].Resources.Limits[0]
+		rule.Function.Slope = resource.MustParse("10Mi")
+		rule.Function.DelayScaleDown = g.DelayScaleDown
+		rule.Function.DelayScaleUp = g.DelayScaleUp
+		state.upsert(policy, nil)
+
+		actual := make(map[int]string)
+		for step := 0; step <= 16; step++ {
+			if step != 0 {
+				fakeClock.Step(10 * time.Second)
+			}
+			inputs["nodes"] = g.Nodes(step)
+			universe.UpdateError = nil
+			if step == g.FailAt && g.FailAt != 0 {
+				universe.UpdateError = fmt.Errorf("simulated failure")
+			}
+
+			updateCount := universe.UpdateCount
+			if err := state.makeObservation(); err != nil {
+				t.Fatalf("error observing: %v", err)
+			}
+			if err := state.applyPolicies(); err != nil {
+				t.Fatalf("error applying: %v", err)
+			}
+			if universe.UpdateCount != updateCount {
+				q := universe.Current.Containers[0].Resources.Limits[v1.ResourceMemory]
+				actual[step] = q.String()
+			}
+		}
+
+		if !reflect.DeepEqual(actual, g.Expected) {
+			t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, actual, g.Expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package control
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/justinsb/scaler/cmd/scaler/options"
+)
+
+// Reasons a patch was denied by the patchLimiter, used in the status condition, metrics and events
+const (
+	denyReasonMinInterval        = "MinPatchInterval"
+	denyReasonPatchesPerHour     = "MaxPatchesPerHour"
+	denyReasonConcurrentRollouts = "MaxConcurrentRollouts"
+)
+
+// patchLimiter enforces the budget for patches to targets.  Every patch restarts pods, so we limit
+// how often we patch each target, and how many targets are rolling out at the same time.
+type patchLimiter struct {
+	// maxPatchesPerHour is the maximum number of patches to a single target in any hour; 0 is unlimited
+	maxPatchesPerHour int
+	// maxConcurrentRollouts is the maximum number of targets that can be rolling out at once; 0 is unlimited
+	maxConcurrentRollouts int
+	// minInterval is the minimum time between patches to a single target
+	minInterval time.Duration
+	// rolloutTimeout is how long we consider a rollout to be in progress, unless we see it finish
+	rolloutTimeout time.Duration
+
+	mutex sync.Mutex
+
+	// patches holds the times of the patches to each target in the last hour
+	patches map[string][]time.Time
+
+	// rollouts holds the time at which each rollout that is in progress was started
+	rollouts map[string]time.Time
+}
+
+func newPatchLimiter(options *options.AutoScalerConfig) *patchLimiter {
+	return &patchLimiter{
+		maxPatchesPerHour:     options.MaxPatchesPerHour,
+		maxConcurrentRollouts: options.MaxConcurrentRollouts,
+		minInterval:           options.MinPatchInterval,
+		rolloutTimeout:        options.RolloutTimeout,
+		patches:               make(map[string][]time.Time),
+		rollouts:              make(map[string]time.Time),
+	}
+}
+
+// allow checks whether we can patch the target now.  If not, it returns the reason and a message for humans.
+func (l *patchLimiter) allow(now time.Time, key string) (bool, string, string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.expire(now)
+
+	patches := l.patches[key]
+	if l.minInterval != 0 && len(patches) != 0 {
+		last := patches[len(patches)-1]
+		if now.Sub(last) < l.minInterval {
+			return false, denyReasonMinInterval, fmt.Sprintf("last patched %s ago; minimum interval between patches is %s", now.Sub(last), l.minInterval)
+		}
+	}
+
+	if l.maxPatchesPerHour != 0 && len(patches) >= l.maxPatchesPerHour {
+		return false, denyReasonPatchesPerHour, fmt.Sprintf("patched %d times in the last hour; limit is %d", len(patches), l.maxPatchesPerHour)
+	}
+
+	if l.maxConcurrentRollouts != 0 {
+		if _, found := l.rollouts[key]; !found && len(l.rollouts) >= l.maxConcurrentRollouts {
+			return false, denyReasonConcurrentRollouts, fmt.Sprintf("%d rollouts in progress; limit is %d", len(l.rollouts), l.maxConcurrentRollouts)
+		}
+	}
+
+	return true, "", ""
+}
+
+// recordPatch records that we patched the target, starting a rollout
+func (l *patchLimiter) recordPatch(now time.Time, key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.patches[key] = append(l.patches[key], now)
+	l.rollouts[key] = now
+}
+
+// finishRollout records that the rollout of the target has finished (or that the target was removed)
+func (l *patchLimiter) finishRollout(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.rollouts, key)
+}
+
+// expire removes the patches older than an hour, and the rollouts that have timed out.
+// It is called with the mutex held.
+func (l *patchLimiter) expire(now time.Time) {
+	for key, patches := range l.patches {
+		i := 0
+		for i < len(patches) && now.Sub(patches[i]) >= time.Hour {
+			i++
+		}
+		if i == len(patches) {
+			delete(l.patches, key)
+		} else {
+			l.patches[key] = patches[i:]
+		}
+	}
+
+	for key, started := range l.rollouts {
+		if now.Sub(started) >= l.rolloutTimeout {
+			delete(l.rollouts, key)
+		}
+	}
+}
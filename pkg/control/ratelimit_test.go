@@ -0,0 +1,186 @@
+package control
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/cmd/scaler/options"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestPatchLimiter(t *testing.T) {
+	type step struct {
+		// Offset is the time of the step, relative to the start
+		Offset time.Duration
+		Key    string
+		// Finish marks the rollout of the key as finished, rather than attempting a patch
+		Finish bool
+		// Expected is the reason the patch is denied, or "" if it is allowed
+		Expected string
+	}
+
+	grid := []struct {
+		Name    string
+		Options options.AutoScalerConfig
+		Steps   []step
+	}{
+		{
+			Name:    "Unlimited",
+			Options: options.AutoScalerConfig{RolloutTimeout: 10 * time.Minute},
+			Steps: []step{
+				{Offset: 0, Key: "a"},
+				{Offset: time.Second, Key: "a"},
+				{Offset: 2 * time.Second, Key: "b"},
+			},
+		},
+		{
+			Name:    "Min interval",
+			Options: options.AutoScalerConfig{MinPatchInterval: 5 * time.Minute, RolloutTimeout: 10 * time.Minute},
+			Steps: []step{
+				{Offset: 0, Key: "a"},
+				{Offset: 4 * time.Minute, Key: "a", Expected: denyReasonMinInterval},
+				// Other targets are not affected
+				{Offset: 4 * time.Minute, Key: "b"},
+				{Offset: 5 * time.Minute, Key: "a"},
+			},
+		},
+		{
+			Name:    "Patches per hour",
+			Options: options.AutoScalerConfig{MaxPatchesPerHour: 2, RolloutTimeout: 10 * time.Minute},
+			Steps: []step{
+				{Offset: 0, Key: "a"},
+				{Offset: 10 * time.Minute, Key: "a"},
+				{Offset: 20 * time.Minute, Key: "a", Expected: denyReasonPatchesPerHour},
+				{Offset: 59 * time.Minute, Key: "a", Expected: denyReasonPatchesPerHour},
+				// The first patch is now more than an hour ago
+				{Offset: 60 * time.Minute, Key: "a"},
+				{Offset: 61 * time.Minute, Key: "a", Expected: denyReasonPatchesPerHour},
+			},
+		},
+		{
+			Name:    "Concurrent rollouts",
+			Options: options.AutoScalerConfig{MaxConcurrentRollouts: 2, RolloutTimeout: 10 * time.Minute},
+			Steps: []step{
+				{Offset: 0, Key: "a"},
+				{Offset: time.Minute, Key: "b"},
+				{Offset: 2 * time.Minute, Key: "c", Expected: denyReasonConcurrentRollouts},
+				// A target that is already rolling out can be patched again
+				{Offset: 2 * time.Minute, Key: "a"},
+				{Offset: 3 * time.Minute, Key: "b", Finish: true},
+				{Offset: 3 * time.Minute, Key: "c"},
+				{Offset: 4 * time.Minute, Key: "d", Expected: denyReasonConcurrentRollouts},
+				// The rollout of a (patched at 2m) times out
+				{Offset: 12 * time.Minute, Key: "d"},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		baseTime := time.Now()
+		limiter := newPatchLimiter(&g.Options)
+		for i, step := range g.Steps {
+			now := baseTime.Add(step.Offset)
+			if step.Finish {
+				limiter.finishRollout(step.Key)
+				continue
+			}
+			ok, reason, message := limiter.allow(now, step.Key)
+			if reason != step.Expected || ok != (step.Expected == "") {
+				t.Errorf("test failure\nname=%s\nstep=%d\n  actual=%v %q (%s)\nexpected=%q", g.Name, i, ok, reason, message, step.Expected)
+				break
+			}
+			if ok {
+				limiter.recordPatch(now, step.Key)
+			}
+		}
+	}
+}
+
+func TestRateLimitedPatchIsReported(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	universe := target.NewSimulationTarget()
+	universe.Current = &v1.PodSpec{
+		Containers: []v1.Container{{Name: "container1"}},
+	}
+
+	config := options.NewAutoScalerConfig()
+	config.MinPatchInterval = 5 * time.Minute
+
+	inputs := map[string]float64{"nodes": 10}
+	state, err := NewState(fakeClock, universe, static.NewStaticFactors(fakeClock, inputs), config)
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+	recorder := record.NewFakeRecorder(10)
+	state.recorder = recorder
+
+	policy := buildPolicyUsingInput("nodes")
+	policy.ObjectMeta = metav1.ObjectMeta{Namespace: "ns1", Name: "policy1"}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	rule := &policy.Spec.Containers[0].Resources.Limits[0]
+	rule.Function.Slope = resource.MustParse("1Mi")
+	state.upsert(policy, nil)
+
+	apply := func() {
+		if err := state.makeObservation(); err != nil {
+			t.Fatalf("error observing: %v", err)
+		}
+		if err := state.applyPolicies(); err != nil {
+			t.Fatalf("error applying: %v", err)
+		}
+	}
+
+	apply()
+	if universe.UpdateCount != 1 {
+		t.Fatalf("expected initial patch, got %d", universe.UpdateCount)
+	}
+	// Ignore the ScaledUp events from the initial patch
+	drainEvents(recorder)
+
+	// A second change within the minimum interval is denied
+	inputs["nodes"] = 20
+	fakeClock.Step(time.Minute)
+	apply()
+	apply()
+	if universe.UpdateCount != 1 {
+		t.Fatalf("expected patch to be denied, got %d", universe.UpdateCount)
+	}
+
+	status := state.statuses()[types.NamespacedName{Namespace: "ns1", Name: "policy1"}]
+	if !hasCondition(status.Conditions, scalingpolicy.Applied, v1.ConditionFalse, denyReasonMinInterval) {
+		t.Errorf("expected %s condition, got %v", denyReasonMinInterval, status.Conditions)
+	}
+
+	text := gatherMetrics(t, state)
+	expected := `scaler_patches_denied_total{namespace="ns1",policy="policy1",reason="MinPatchInterval"} 2`
+	if !strings.Contains(text, expected+"\n") {
+		t.Errorf("expected metrics to contain %q; got:\n%s", expected, text)
+	}
+
+	// We only record an event when the patch is first denied
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected 1 event, got %d", len(recorder.Events))
+	} else if event := <-recorder.Events; !strings.HasPrefix(event, "Normal RateLimited ") {
+		t.Errorf("unexpected event %q", event)
+	}
+
+	// After the interval, the patch is applied
+	fakeClock.Step(5 * time.Minute)
+	apply()
+	if universe.UpdateCount != 2 {
+		t.Fatalf("expected patch after interval, got %d", universe.UpdateCount)
+	}
+	status = state.statuses()[types.NamespacedName{Namespace: "ns1", Name: "policy1"}]
+	if !hasCondition(status.Conditions, scalingpolicy.Applied, v1.ConditionTrue, "Patched") {
+		t.Errorf("expected Patched condition, got %v", status.Conditions)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/justinsb/scaler/cmd/scaler/options"
 	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
 	"github.com/justinsb/scaler/pkg/control/target"
+	k8sfactors "github.com/justinsb/scaler/pkg/factors/kubernetes"
 	"github.com/justinsb/scaler/pkg/simulate"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -25,13 +26,13 @@ func RunSimulation(policy *scalingpolicy.ScalingPolicy, options *options.AutoSca
 
 	baseTime := time.Now()
 	fakeClock := clock.NewFakeClock(baseTime)
-	state, err := NewState(fakeClock, universe, options)
+	state, err := NewState(fakeClock, universe, k8sfactors.NewPollingKubernetesFactors(fakeClock, universe), options)
 	if err != nil {
 		return nil, err
 	}
 
 	policy = policy.DeepCopy()
-	state.upsert(policy)
+	state.upsert(policy, nil)
 
 	pollPeriod := int(options.PollPeriod.Seconds())
 	updatePeriod := int(options.UpdatePeriod.Seconds())
@@ -101,7 +102,8 @@ func buildMockPodSpec(policy *scalingpolicy.ScalingPolicy) *v1.PodSpec {
 		mc := v1.Container{
 			Name: c.Name,
 		}
-		ps.Containers = append(ps.Containers, mc)
+		containers := c.ContainerType.ContainersIn(ps)
+		*containers = append(*containers, mc)
 	}
 	return ps
 }
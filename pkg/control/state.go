@@ -2,16 +2,22 @@ package control
 
 import (
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/justinsb/scaler/cmd/scaler/options"
 	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
 	"github.com/justinsb/scaler/pkg/control/target"
 	"github.com/justinsb/scaler/pkg/factors"
-	k8sfactors "github.com/justinsb/scaler/pkg/factors/kubernetes"
+	"github.com/justinsb/scaler/pkg/http"
+	"github.com/justinsb/scaler/pkg/scaling"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/clock"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 )
 
 // State holds the current parent and state around applying them
@@ -20,24 +26,46 @@ type State struct {
 	target  target.Interface
 	options *options.AutoScalerConfig
 	factors factors.Interface
+	metrics *stateMetrics
+
+	// limiter enforces the budget for patches to targets
+	limiter *patchLimiter
+
+	// recorder records events; it is nil when we are not running in a controller (e.g. in simulations)
+	recorder record.EventRecorder
+
+	// checkpoints persists the evaluator state; it is nil when we are not running in a controller, or checkpoints are disabled
+	checkpoints CheckpointStore
 
 	mutex    sync.Mutex
 	policies map[types.NamespacedName]*PolicyState
+
+	// leaderMutex guards leader; it is separate from mutex so that we can report the leader while applying policies
+	leaderMutex sync.Mutex
+	leader      http.LeaderInfo
+
+	// applying tracks the loops started while we are leading, so that we can wait for them to stop when we stop leading
+	applying sync.WaitGroup
+	// stoppingLeading is true while StopLeading waits for applying; it is guarded by leaderMutex
+	stoppingLeading bool
 }
 
-func NewState(clock clock.Clock, target target.Interface, options *options.AutoScalerConfig) (*State, error) {
+func NewState(clock clock.Clock, target target.Interface, factors factors.Interface, options *options.AutoScalerConfig) (*State, error) {
 	p := &State{
 		clock:    clock,
 		target:   target,
+		factors:  factors,
 		options:  options,
 		policies: make(map[types.NamespacedName]*PolicyState),
 	}
-
-	p.factors = k8sfactors.NewPollingKubernetesFactors(clock, target)
+	p.metrics = newStateMetrics(p)
+	p.limiter = newPatchLimiter(options)
 
 	return p, nil
 }
 
+// Run starts observing the cluster.  Every replica observes, so that followers can
+// report their own view; only the leader applies policies, see RunApply.
 func (c *State) Run(stopCh <-chan struct{}) {
 	go wait.Until(func() {
 		err := c.makeObservation()
@@ -46,14 +74,115 @@ func (c *State) Run(stopCh <-chan struct{}) {
 			glog.Warningf("error observing cluster values: %v", err)
 		}
 	}, c.options.PollPeriod, stopCh)
+}
 
-	go wait.Until(func() {
+// RunApply starts applying policies to the targets, until stopCh is closed; it should only be called on the leader.
+func (c *State) RunApply(stopCh <-chan struct{}) {
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	if !c.canStartLeading(stopCh) {
+		// We lost the election before we started
+		return
+	}
+
+	c.leader.IsLeader = true
+	c.leader.Leader = c.leader.Identity
+
+	c.runLeading(func() {
 		err := c.applyPolicies()
 		if err != nil {
 			// TODO: Report as event
 			glog.Warningf("error applying policy values: %v", err)
 		}
 	}, c.options.UpdatePeriod, stopCh)
+
+	if c.checkpoints != nil && c.options.CheckpointPeriod != 0 {
+		c.runLeading(func() {
+			err := c.saveCheckpoints()
+			if err != nil {
+				glog.Warningf("error saving checkpoints: %v", err)
+			}
+		}, c.options.CheckpointPeriod, stopCh)
+	}
+}
+
+// RunWhileLeading calls fn every period until stopCh is closed, as RunApply does for applying policies;
+// StopLeading waits for it to finish.  It does nothing if we are no longer leading.
+func (c *State) RunWhileLeading(fn func(), period time.Duration, stopCh <-chan struct{}) {
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	if !c.canStartLeading(stopCh) {
+		return
+	}
+	c.runLeading(fn, period, stopCh)
+}
+
+// canStartLeading returns false if stopCh is closed or StopLeading has been called, in which case we must not
+// start any more loops; it must be called with leaderMutex held.
+func (c *State) canStartLeading(stopCh <-chan struct{}) bool {
+	if c.stoppingLeading {
+		return false
+	}
+	select {
+	case <-stopCh:
+		return false
+	default:
+		return true
+	}
+}
+
+// runLeading calls fn every period until stopCh is closed, tracked by applying; it must be called with leaderMutex
+// held, after canStartLeading, so that we never add to applying while StopLeading is waiting.
+func (c *State) runLeading(fn func(), period time.Duration, stopCh <-chan struct{}) {
+	c.applying.Add(1)
+	go func() {
+		defer c.applying.Done()
+		wait.Until(fn, period, stopCh)
+	}()
+}
+
+// StopLeading waits for the loops started while we were leading to finish, including any patch in flight,
+// after their stopCh has been closed.  We then only observe the cluster, until we are elected again.
+func (c *State) StopLeading() {
+	c.leaderMutex.Lock()
+	c.stoppingLeading = true
+	c.leaderMutex.Unlock()
+
+	c.applying.Wait()
+
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	c.stoppingLeading = false
+	c.leader.IsLeader = false
+}
+
+// SetIdentity sets the identity of this replica, as used for leader election
+func (c *State) SetIdentity(identity string) {
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	c.leader.Identity = identity
+}
+
+// OnNewLeader records the identity of the current leader
+func (c *State) OnNewLeader(identity string) {
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	glog.Infof("new leader elected: %s", identity)
+	c.leader.Leader = identity
+}
+
+// LeaderInfo returns the leader election state, as seen by this replica
+func (c *State) LeaderInfo() *http.LeaderInfo {
+	c.leaderMutex.Lock()
+	defer c.leaderMutex.Unlock()
+
+	info := c.leader
+	return &info
 }
 
 func (c *State) remove(namespace, name string) {
@@ -64,10 +193,21 @@ func (c *State) remove(namespace, name string) {
 	policyState := c.policies[key]
 	if policyState != nil {
 		delete(c.policies, key)
+		c.limiter.finishRollout(policyState.targetKey())
+	}
+}
+
+// eventf records an event, if we have a recorder
+func (c *State) eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if c.recorder == nil {
+		return
 	}
+	c.recorder.Eventf(object, eventType, reason, messageFmt, args...)
 }
 
-func (c *State) upsert(o *scalingpolicy.ScalingPolicy) {
+// upsert adds or updates the policy.  validationErrors holds the errors from validating the policy;
+// if there are errors we report them in the status, and don't apply the policy until it is fixed.
+func (c *State) upsert(o *scalingpolicy.ScalingPolicy, validationErrors field.ErrorList) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -81,9 +221,15 @@ func (c *State) upsert(o *scalingpolicy.ScalingPolicy) {
 	} else {
 		policyState.updatePolicy(o)
 	}
+	policyState.setValidationErrors(validationErrors)
 }
 
 func (c *State) makeObservation() error {
+	start := time.Now()
+	defer func() {
+		c.metrics.pollDuration.Observe(time.Since(start).Seconds())
+	}()
+
 	snapshot, err := c.factors.Snapshot()
 	if err != nil {
 		return err
@@ -100,6 +246,11 @@ func (c *State) makeObservation() error {
 }
 
 func (c *State) applyPolicies() error {
+	start := time.Now()
+	defer func() {
+		c.metrics.applyDuration.Observe(time.Since(start).Seconds())
+	}()
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -112,3 +263,43 @@ func (c *State) applyPolicies() error {
 
 	return nil
 }
+
+// saveCheckpoints persists the evaluator state for each policy, so that it can be restored after a restart
+func (c *State) saveCheckpoints() error {
+	type pending struct {
+		policy     *scalingpolicy.ScalingPolicy
+		checkpoint *scaling.Checkpoint
+	}
+
+	// We take the checkpoints under the lock, but save them without holding it
+	var checkpoints []pending
+	c.mutex.Lock()
+	for _, p := range c.policies {
+		policy, checkpoint := p.checkpoint()
+		if checkpoint == nil {
+			continue
+		}
+		checkpoints = append(checkpoints, pending{policy: policy, checkpoint: checkpoint})
+	}
+	c.mutex.Unlock()
+
+	var errs []error
+	for _, p := range checkpoints {
+		if err := c.checkpoints.Save(p.policy, p.checkpoint); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// statuses returns the status we should report for each policy
+func (c *State) statuses() map[types.NamespacedName]*scalingpolicy.ScalingPolicyStatus {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	statuses := make(map[types.NamespacedName]*scalingpolicy.ScalingPolicyStatus)
+	for k, p := range c.policies {
+		statuses[k] = p.buildStatus()
+	}
+	return statuses
+}
@@ -0,0 +1,172 @@
+package control
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/cmd/scaler/options"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"github.com/justinsb/scaler/pkg/http"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestLeaderInfo(t *testing.T) {
+	grid := []struct {
+		Name      string
+		NewLeader string
+		Leading   bool
+		Stopped   bool
+		Expected  http.LeaderInfo
+	}{
+		{
+			Name:     "leader not yet known",
+			Expected: http.LeaderInfo{Identity: "replica1"},
+		},
+		{
+			Name:      "following",
+			NewLeader: "replica2",
+			Expected:  http.LeaderInfo{Identity: "replica1", Leader: "replica2"},
+		},
+		{
+			Name:      "leading",
+			NewLeader: "replica2",
+			Leading:   true,
+			Expected:  http.LeaderInfo{Identity: "replica1", Leader: "replica1", IsLeader: true},
+		},
+		{
+			Name:      "stopped leading",
+			NewLeader: "replica2",
+			Leading:   true,
+			Stopped:   true,
+			Expected:  http.LeaderInfo{Identity: "replica1", Leader: "replica1"},
+		},
+	}
+
+	for _, g := range grid {
+		fakeClock := clock.NewFakeClock(time.Now())
+		state, err := NewState(fakeClock, target.NewSimulationTarget(), static.NewStaticFactors(fakeClock, nil), options.NewAutoScalerConfig())
+		if err != nil {
+			t.Fatalf("error building state: %v", err)
+		}
+		state.SetIdentity("replica1")
+		if g.NewLeader != "" {
+			state.OnNewLeader(g.NewLeader)
+		}
+		stopCh := make(chan struct{})
+		if g.Leading {
+			state.RunApply(stopCh)
+		}
+		if g.Stopped {
+			close(stopCh)
+			state.StopLeading()
+		}
+
+		actual := state.Query().(*StateInfo).Leader
+		if *actual != g.Expected {
+			t.Errorf("test failure\nname=%s\n  actual=%+v\nexpected=%+v", g.Name, *actual, g.Expected)
+		}
+		if !g.Stopped {
+			close(stopCh)
+		}
+	}
+
+	// If we lose the election before we start applying, we never report that we are the leader
+	fakeClock := clock.NewFakeClock(time.Now())
+	state, err := NewState(fakeClock, target.NewSimulationTarget(), static.NewStaticFactors(fakeClock, nil), options.NewAutoScalerConfig())
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+	stopCh := make(chan struct{})
+	close(stopCh)
+	state.RunApply(stopCh)
+	state.StopLeading()
+	if actual := state.Query().(*StateInfo).Leader; actual.IsLeader {
+		t.Errorf("unexpected leader %+v", *actual)
+	}
+}
+
+func TestStopLeadingWaitsForLoops(t *testing.T) {
+	grid := []struct {
+		Name string
+		// Closed is true if stopCh is closed before the loop is started
+		Closed   bool
+		Expected bool
+	}{
+		{
+			Name:     "leading",
+			Expected: true,
+		},
+		{
+			Name:     "lost the election before starting",
+			Closed:   true,
+			Expected: false,
+		},
+	}
+
+	for _, g := range grid {
+		fakeClock := clock.NewFakeClock(time.Now())
+		state, err := NewState(fakeClock, target.NewSimulationTarget(), static.NewStaticFactors(fakeClock, nil), options.NewAutoScalerConfig())
+		if err != nil {
+			t.Fatalf("error building state: %v", err)
+		}
+
+		var calls int32
+		started := make(chan struct{}, 1)
+		stopCh := make(chan struct{})
+		if g.Closed {
+			close(stopCh)
+		}
+		state.RunWhileLeading(func() {
+			atomic.AddInt32(&calls, 1)
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+		}, time.Millisecond, stopCh)
+
+		if !g.Closed {
+			<-started
+			close(stopCh)
+		}
+		state.StopLeading()
+
+		// Once StopLeading returns, the loop must not run again
+		afterStop := atomic.LoadInt32(&calls)
+		time.Sleep(20 * time.Millisecond)
+		if actual := atomic.LoadInt32(&calls); actual != afterStop {
+			t.Errorf("test failure\nname=%s\nloop ran %d times after StopLeading", g.Name, actual-afterStop)
+		}
+		if actual := afterStop != 0; actual != g.Expected {
+			t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, actual, g.Expected)
+		}
+	}
+}
+
+// TestStopLeadingConcurrentWithRunApply checks that we can lose the election while we are starting to apply,
+// which is a misuse of the WaitGroup unless RunApply and StopLeading are serialized (run with -race)
+func TestStopLeadingConcurrentWithRunApply(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	state, err := NewState(fakeClock, target.NewSimulationTarget(), static.NewStaticFactors(fakeClock, nil), options.NewAutoScalerConfig())
+	if err != nil {
+		t.Fatalf("error building state: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		stopCh := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			state.RunApply(stopCh)
+		}()
+		close(stopCh)
+		state.StopLeading()
+		<-done
+		state.StopLeading()
+
+		if actual := state.Query().(*StateInfo).Leader; actual.IsLeader {
+			t.Fatalf("unexpected leader after iteration %d: %+v", i, *actual)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package control
+
+import (
+	"math"
+	"sort"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/factors"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// policyInputs returns the sorted names of the inputs referenced by the policy
+func policyInputs(policy *scalingpolicy.ScalingPolicy) []string {
+	inputs := make(map[string]bool)
+	for _, c := range policy.Spec.Containers {
+		for _, r := range c.Resources.Limits {
+			if r.Function.Input != "" {
+				inputs[r.Function.Input] = true
+			}
+		}
+		for _, r := range c.Resources.Requests {
+			if r.Function.Input != "" {
+				inputs[r.Function.Input] = true
+			}
+		}
+	}
+
+	var names []string
+	for k := range inputs {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readInputValues reads the named inputs from the snapshot, returning the names of any inputs that were not found
+func readInputValues(snapshot factors.Snapshot, names []string) ([]scalingpolicy.InputValue, []string, error) {
+	var values []scalingpolicy.InputValue
+	var missing []string
+	for _, name := range names {
+		v, found, err := snapshot.Get(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !found {
+			missing = append(missing, name)
+			continue
+		}
+		values = append(values, scalingpolicy.InputValue{
+			Name:  name,
+			Value: floatToQuantity(v),
+		})
+	}
+	return values, missing, nil
+}
+
+// floatToQuantity converts an input value to a Quantity, using milli-units only where needed
+func floatToQuantity(v float64) resource.Quantity {
+	if v == math.Trunc(v) {
+		return *resource.NewQuantity(int64(v), resource.DecimalSI)
+	}
+	return *resource.NewMilliQuantity(int64(v*1000), resource.DecimalSI)
+}
+
+// buildContainerStatuses merges the target & threshold PodSpecs into per-container status, for containers & init containers
+func buildContainerStatuses(target *v1.PodSpec, scaleDownThreshold *v1.PodSpec, scaleUpThreshold *v1.PodSpec) []scalingpolicy.ContainerScalingStatus {
+	var statuses []scalingpolicy.ContainerScalingStatus
+	if target != nil {
+		for _, containerType := range scalingpolicy.ContainerTypes {
+			containers := *containerType.ContainersIn(target)
+			for i := range containers {
+				c := &containers[i]
+				statuses = append(statuses, scalingpolicy.ContainerScalingStatus{
+					Name:   c.Name,
+					Target: c.Resources,
+				})
+			}
+		}
+	}
+	// Container names are unique across the containers & init containers of a pod
+	if scaleDownThreshold != nil {
+		for _, containerType := range scalingpolicy.ContainerTypes {
+			containers := *containerType.ContainersIn(scaleDownThreshold)
+			for i := range containers {
+				c := &containers[i]
+				for j := range statuses {
+					if statuses[j].Name == c.Name {
+						statuses[j].ScaleDownThreshold = c.Resources
+					}
+				}
+			}
+		}
+	}
+	if scaleUpThreshold != nil {
+		for _, containerType := range scalingpolicy.ContainerTypes {
+			containers := *containerType.ContainersIn(scaleUpThreshold)
+			for i := range containers {
+				c := &containers[i]
+				for j := range statuses {
+					if statuses[j].Name == c.Name {
+						statuses[j].ScaleUpThreshold = c.Resources
+					}
+				}
+			}
+		}
+	}
+	return statuses
+}
+
+// hasCondition returns true if the condition is present, with the specified status and reason
+func hasCondition(conditions []scalingpolicy.ScalingPolicyCondition, conditionType scalingpolicy.ScalingPolicyConditionType, status v1.ConditionStatus, reason string) bool {
+	for i := range conditions {
+		c := &conditions[i]
+		if c.Type == conditionType {
+			return c.Status == status && c.Reason == reason
+		}
+	}
+	return false
+}
+
+// setCondition adds or updates the condition, preserving LastTransitionTime if the status has not changed
+func setCondition(conditions *[]scalingpolicy.ScalingPolicyCondition, now metav1.Time, conditionType scalingpolicy.ScalingPolicyConditionType, status v1.ConditionStatus, reason, message string) {
+	condition := scalingpolicy.ScalingPolicyCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	}
+
+	for i := range *conditions {
+		existing := &(*conditions)[i]
+		if existing.Type != conditionType {
+			continue
+		}
+		if existing.Status == status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	*conditions = append(*conditions, condition)
+}
@@ -4,10 +4,17 @@ import "k8s.io/api/core/v1"
 
 type Interface interface {
 	// Read gets the current state of the target
-	Read(kind, namespace, name string) (*v1.PodSpec, error)
+	Read(ref *Ref) (*v1.PodSpec, error)
 
 	// UpdateResources updates the target with new resource limits/requests
-	UpdateResources(kind, namespace, name string, updated *v1.PodSpec, dryrun bool) error
+	UpdateResources(ref *Ref, updated *v1.PodSpec, dryrun bool) error
+
+	// ReadRolloutStatus gets the progress of rolling out the latest spec of the target to its pods.
+	// It returns nil if we can't track the rollout of the target.
+	ReadRolloutStatus(ref *Ref) (*RolloutStatus, error)
+
+	// ReadObjectReference gets a reference to the target, for recording events
+	ReadObjectReference(ref *Ref) (*v1.ObjectReference, error)
 
 	// ReadClusterState gets the current state of the cluster (summary statistics)
 	ReadClusterState() (*ClusterStats, error)
@@ -17,3 +24,28 @@ type ClusterStats struct {
 	NodeCount          int
 	NodeSumAllocatable v1.ResourceList
 }
+
+// RolloutStatus describes the progress of rolling out the latest spec of the target
+type RolloutStatus struct {
+	// Converged is true when all the replicas are updated to the latest spec and available
+	Converged bool
+
+	// Message describes the progress of the rollout, for humans
+	Message string
+}
+
+// Ref identifies the target object
+type Ref struct {
+	// APIVersion is the apiVersion of the target; it is required for kinds that are not built-in
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+
+	// PodSpecPath is the path to the PodSpec in kinds that are not built-in, defaulting to spec.template.spec
+	PodSpecPath string
+}
+
+func (r *Ref) String() string {
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
@@ -2,82 +2,73 @@ package target
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/golang/glog"
 	"github.com/justinsb/scaler/pkg/control/k8sclient"
 	"k8s.io/api/core/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
 type KubernetesTarget struct {
 	kubeClient kubernetes.Interface
+	reader     k8sclient.ResourceReader
 	patcher    k8sclient.ResourcePatcher
+	generic    *k8sclient.GenericClient
 }
 
 var _ Interface = &KubernetesTarget{}
 
-func NewKubernetesTarget(kubeClient kubernetes.Interface) (Interface, error) {
-	patcher, err := k8sclient.NewKubernetesPatcher(kubeClient)
+func NewKubernetesTarget(kubeClient kubernetes.Interface, clientPool dynamic.ClientPool) (Interface, error) {
+	// Reads and patches share the API version selection, so we read & patch the same version
+	versions, err := k8sclient.NewAPIVersions(kubeClient.Discovery())
 	if err != nil {
 		return nil, err
 	}
+	return newKubernetesTarget(kubeClient, clientPool, versions), nil
+}
 
-	t := &KubernetesTarget{
+func newKubernetesTarget(kubeClient kubernetes.Interface, clientPool dynamic.ClientPool, versions *k8sclient.APIVersions) *KubernetesTarget {
+	return &KubernetesTarget{
 		kubeClient: kubeClient,
-		patcher:    patcher,
+		reader:     k8sclient.NewKubernetesReader(clientPool, versions),
+		patcher:    k8sclient.NewKubernetesPatcher(clientPool, versions),
+		generic:    k8sclient.NewGenericClient(clientPool, versions),
 	}
-	return t, nil
 }
 
-func (s *KubernetesTarget) Read(kind, namespace, name string) (*v1.PodSpec, error) {
-	client := s.kubeClient
-
-	switch strings.ToLower(kind) {
-	case "replicaset":
-		{
-			kind = "ReplicaSet"
-			o, err := client.ExtensionsV1beta1().ReplicaSets(namespace).Get(name, meta_v1.GetOptions{})
-			if err != nil {
-				// TODO: Emit event?
-				return nil, err
-			}
-
-			return &o.Spec.Template.Spec, nil
-		}
-
-	case "daemonset":
-		{
-			kind = "DaemonSet"
-			o, err := client.ExtensionsV1beta1().DaemonSets(namespace).Get(name, meta_v1.GetOptions{})
-			if err != nil {
-				// TODO: Emit event?
-				return nil, err
-			}
-
-			return &o.Spec.Template.Spec, nil
-		}
-
-	case "deployment":
-		{
-			kind = "Deployment"
-			o, err := client.AppsV1beta1().Deployments(namespace).Get(name, meta_v1.GetOptions{})
-			if err != nil {
-				// TODO: Emit event?
-				return nil, err
-			}
+func (s *KubernetesTarget) Read(ref *Ref) (*v1.PodSpec, error) {
+	if !k8sclient.IsBuiltinKind(ref.APIVersion, ref.Kind) {
+		return s.generic.ReadPodSpec(ref.APIVersion, ref.Kind, ref.Namespace, ref.Name, ref.PodSpecPath)
+	}
+	return s.reader.ReadPodSpec(ref.Kind, ref.Namespace, ref.Name)
+}
 
-			return &o.Spec.Template.Spec, nil
-		}
+func (s *KubernetesTarget) UpdateResources(ref *Ref, updates *v1.PodSpec, dryrun bool) error {
+	if !k8sclient.IsBuiltinKind(ref.APIVersion, ref.Kind) {
+		return s.generic.UpdateResources(ref.APIVersion, ref.Kind, ref.Namespace, ref.Name, ref.PodSpecPath, updates, dryrun)
+	}
+	return s.patcher.UpdateResources(ref.Kind, ref.Namespace, ref.Name, updates, dryrun)
+}
 
-	default:
-		return nil, fmt.Errorf("unhandled kind: %q", kind)
+func (s *KubernetesTarget) ReadRolloutStatus(ref *Ref) (*RolloutStatus, error) {
+	if !k8sclient.IsBuiltinKind(ref.APIVersion, ref.Kind) {
+		// We don't know how the status of other kinds reports progress
+		return nil, nil
+	}
+	status, err := s.reader.ReadRolloutStatus(ref.Kind, ref.Namespace, ref.Name)
+	if err != nil || status == nil {
+		return nil, err
 	}
+	return &RolloutStatus{Converged: status.Converged, Message: status.Message}, nil
 }
 
-func (s *KubernetesTarget) UpdateResources(kind, namespace, name string, updates *v1.PodSpec, dryrun bool) error {
-	return s.patcher.UpdateResources(kind, namespace, name, updates, dryrun)
+func (s *KubernetesTarget) ReadObjectReference(ref *Ref) (*v1.ObjectReference, error) {
+	if !k8sclient.IsBuiltinKind(ref.APIVersion, ref.Kind) {
+		return s.generic.ReadObjectReference(ref.APIVersion, ref.Kind, ref.Namespace, ref.Name)
+	}
+	return s.reader.ReadObjectReference(ref.Kind, ref.Namespace, ref.Name)
 }
 
 func (s *KubernetesTarget) ReadClusterState() (*ClusterStats, error) {
@@ -0,0 +1,93 @@
+package target
+
+import (
+	"testing"
+
+	"github.com/justinsb/scaler/pkg/control/k8sclient"
+	"github.com/justinsb/scaler/pkg/control/k8sclient/fakecluster"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRead(t *testing.T) {
+	podSpec := v1.PodSpec{
+		Containers: []v1.Container{
+			{Name: "container1"},
+		},
+	}
+	objectMeta := metav1.ObjectMeta{Namespace: "ns1", Name: "name1"}
+
+	grid := []struct {
+		Kind         string
+		GroupVersion string
+		Resource     string
+		Object       interface{}
+	}{
+		{
+			Kind:         "StatefulSet",
+			GroupVersion: "apps/v1beta1",
+			Resource:     "statefulsets",
+			Object: &appsv1beta1.StatefulSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1beta1", Kind: "StatefulSet"},
+				ObjectMeta: objectMeta,
+				Spec: appsv1beta1.StatefulSetSpec{
+					Template: v1.PodTemplateSpec{Spec: podSpec},
+				},
+			},
+		},
+		{
+			Kind:         "Deployment",
+			GroupVersion: "apps/v1",
+			Resource:     "deployments",
+			Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   &objectMeta,
+				"spec": map[string]interface{}{
+					"template": &v1.PodTemplateSpec{Spec: podSpec},
+				},
+			},
+		},
+		{
+			Kind:         "ReplicationController",
+			GroupVersion: "v1",
+			Resource:     "replicationcontrollers",
+			Object: &v1.ReplicationController{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ReplicationController"},
+				ObjectMeta: objectMeta,
+				Spec: v1.ReplicationControllerSpec{
+					Template: &v1.PodTemplateSpec{Spec: podSpec},
+				},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		cluster := fakecluster.NewCluster()
+
+		cluster.AddResource(g.GroupVersion, g.Resource, g.Kind)
+		if err := cluster.AddObject(g.GroupVersion, g.Resource, "ns1", "name1", g.Object); err != nil {
+			t.Fatalf("error adding object: %v", err)
+		}
+
+		versions, err := k8sclient.NewAPIVersions(cluster.Discovery)
+		if err != nil {
+			t.Fatalf("error querying API versions: %v", err)
+		}
+		target := newKubernetesTarget(nil, cluster.ClientPool, versions)
+
+		actual, err := target.Read(&Ref{Kind: g.Kind, Namespace: "ns1", Name: "name1"})
+		if err != nil {
+			t.Errorf("%s: unexpected error from Read: %v", g.Kind, err)
+			continue
+		}
+		if len(actual.Containers) != 1 || actual.Containers[0].Name != "container1" {
+			t.Errorf("%s: unexpected PodSpec: %v", g.Kind, actual)
+		}
+
+		if _, err := target.Read(&Ref{Kind: g.Kind, Namespace: "ns1", Name: "doesnotexist"}); err == nil {
+			t.Errorf("%s: expected error reading non-existent object", g.Kind)
+		}
+	}
+}
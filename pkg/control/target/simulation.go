@@ -2,9 +2,12 @@ package target
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/golang/glog"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type SimulationTarget struct {
@@ -12,6 +15,12 @@ type SimulationTarget struct {
 
 	ClusterState *ClusterStats
 
+	// Rollout is the rollout status we report; if nil, rollouts complete immediately
+	Rollout *RolloutStatus
+
+	// UpdateError is returned by UpdateResources, if set, without applying the updates
+	UpdateError error
+
 	UpdateCount int
 }
 
@@ -21,16 +30,28 @@ func NewSimulationTarget() *SimulationTarget {
 	return &SimulationTarget{}
 }
 
-func (s *SimulationTarget) Read(kind, namespace, name string) (*v1.PodSpec, error) {
+func (s *SimulationTarget) Read(ref *Ref) (*v1.PodSpec, error) {
 	if s.Current == nil {
-		return nil, fmt.Errorf("simulated value not set")
+		return nil, errors.NewNotFound(schema.GroupResource{Resource: strings.ToLower(ref.Kind)}, ref.Name)
 	}
 	return s.Current.DeepCopy(), nil
 }
 
-func (s *SimulationTarget) UpdateResources(kind, namespace, name string, updates *v1.PodSpec, dryrun bool) error {
-	for _, c := range updates.Containers {
-		currentContainer := findContainerByName(s.Current.Containers, c.Name)
+func (s *SimulationTarget) UpdateResources(ref *Ref, updates *v1.PodSpec, dryrun bool) error {
+	if s.UpdateError != nil {
+		return s.UpdateError
+	}
+
+	updateContainers(s.Current.InitContainers, updates.InitContainers)
+	updateContainers(s.Current.Containers, updates.Containers)
+	s.UpdateCount++
+	return nil
+}
+
+// updateContainers applies the resources of each of the updated containers to the matching current container
+func updateContainers(current []v1.Container, updates []v1.Container) {
+	for _, c := range updates {
+		currentContainer := findContainerByName(current, c.Name)
 		if currentContainer == nil {
 			glog.Warningf("cannot find container %q", c.Name)
 			continue
@@ -50,8 +71,19 @@ func (s *SimulationTarget) UpdateResources(kind, namespace, name string, updates
 			currentContainer.Resources.Requests[k] = r
 		}
 	}
-	s.UpdateCount++
-	return nil
+}
+
+func (s *SimulationTarget) ReadRolloutStatus(ref *Ref) (*RolloutStatus, error) {
+	return s.Rollout, nil
+}
+
+func (s *SimulationTarget) ReadObjectReference(ref *Ref) (*v1.ObjectReference, error) {
+	return &v1.ObjectReference{
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Namespace:  ref.Namespace,
+		Name:       ref.Name,
+	}, nil
 }
 
 func (s *SimulationTarget) ReadClusterState() (*ClusterStats, error) {
@@ -0,0 +1,227 @@
+package factors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// The built-in inputs
+const (
+	// InputCores is the sum of the allocatable cpu of the nodes
+	InputCores = "cores"
+	// InputMemory is the sum of the allocatable memory of the nodes
+	InputMemory = "memory"
+	// InputNodes is the number of nodes
+	InputNodes = "nodes"
+
+	// InputPods is the number of pods
+	InputPods = "pods"
+	// InputContainers is the total number of containers in the pods
+	InputContainers = "containers"
+	// InputServices is the number of services
+	InputServices = "services"
+	// InputEndpoints is the number of ready endpoint addresses
+	InputEndpoints = "endpoints"
+	// InputNamespaces is the number of namespaces
+	InputNamespaces = "namespaces"
+)
+
+// IsNodeInput returns true if the input is computed from the nodes, and so can be filtered with a NodeFilter
+func IsNodeInput(key string) bool {
+	switch key {
+	case InputCores, InputMemory, InputNodes:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsObjectInput returns true if the input counts objects, and so can be filtered with an ObjectFilter
+func IsObjectInput(key string) bool {
+	switch key {
+	case InputPods, InputContainers, InputServices, InputEndpoints, InputNamespaces:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsBuiltinInput returns true if the input is one of the built-in inputs
+func IsBuiltinInput(key string) bool {
+	return IsNodeInput(key) || IsObjectInput(key)
+}
+
+// NodeFilter restricts the nodes which are included when computing the node-based inputs
+type NodeFilter struct {
+	// Selector is a label query over the nodes
+	Selector labels.Selector
+
+	// Tolerations are the taints we tolerate; nodes with NoSchedule or NoExecute taints
+	// are only included if all those taints are tolerated
+	Tolerations []v1.Toleration
+
+	// ReadyOnly includes only the nodes which are Ready
+	ReadyOnly bool
+}
+
+// Key returns a string which identifies the filter, so that we can share computations between equivalent filters
+func (f *NodeFilter) Key() string {
+	var tolerations []string
+	for _, t := range f.Tolerations {
+		tolerations = append(tolerations, fmt.Sprintf("%s:%s:%s:%s", t.Key, t.Operator, t.Value, t.Effect))
+	}
+	sort.Strings(tolerations)
+
+	selector := ""
+	if f.Selector != nil {
+		selector = f.Selector.String()
+	}
+	return fmt.Sprintf("selector=%s;tolerations=%s;readyOnly=%v", selector, strings.Join(tolerations, ","), f.ReadyOnly)
+}
+
+// Matches returns true if the node should be included
+func (f *NodeFilter) Matches(node *v1.Node) bool {
+	if f.Selector != nil && !f.Selector.Matches(labels.Set(node.Labels)) {
+		return false
+	}
+
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != v1.TaintEffectNoSchedule && taint.Effect != v1.TaintEffectNoExecute {
+			continue
+		}
+		if !f.toleratesTaint(taint) {
+			return false
+		}
+	}
+
+	if f.ReadyOnly && !isNodeReady(node) {
+		return false
+	}
+
+	return true
+}
+
+func (f *NodeFilter) toleratesTaint(taint *v1.Taint) bool {
+	for i := range f.Tolerations {
+		if f.Tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNodeReady(node *v1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == v1.NodeReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// ObjectFilter restricts the objects which are counted for the object inputs
+type ObjectFilter struct {
+	// Selector is a label query over the objects
+	Selector labels.Selector
+
+	// FieldSelector is a field query over the objects
+	FieldSelector fields.Selector
+}
+
+// Key returns a string which identifies the filter, so that we can share computations between equivalent filters
+func (f *ObjectFilter) Key() string {
+	selector := ""
+	if f.Selector != nil {
+		selector = f.Selector.String()
+	}
+	fieldSelector := ""
+	if f.FieldSelector != nil {
+		fieldSelector = f.FieldSelector.String()
+	}
+	return fmt.Sprintf("selector=%s;fieldSelector=%s", selector, fieldSelector)
+}
+
+// Matches returns true if an object with the labels & fields should be counted
+func (f *ObjectFilter) Matches(objectLabels labels.Set, objectFields fields.Set) bool {
+	if f.Selector != nil && !f.Selector.Matches(objectLabels) {
+		return false
+	}
+	if f.FieldSelector != nil && !f.FieldSelector.Matches(objectFields) {
+		return false
+	}
+	return true
+}
+
+// ObjectFilteredSnapshot is implemented by snapshots which can count a subset of the objects
+type ObjectFilteredSnapshot interface {
+	Snapshot
+
+	// GetForObjects is like Get, but counts only the objects matching the filter
+	GetForObjects(key string, filter *ObjectFilter) (float64, bool, error)
+}
+
+// NodeFilteredSnapshot is implemented by snapshots which can compute the node-based inputs over a subset of the nodes
+type NodeFilteredSnapshot interface {
+	Snapshot
+
+	// GetForNodes is like Get, but computes the value over the nodes matching the filter
+	GetForNodes(key string, filter *NodeFilter) (float64, bool, error)
+}
+
+// Input is a named input, computed from a built-in input (the Source) over a subset of the cluster
+type Input struct {
+	Source  string
+	Nodes   *NodeFilter
+	Objects *ObjectFilter
+}
+
+// WithInputs returns a Snapshot which resolves the named inputs, delegating all other keys to the snapshot
+func WithInputs(snapshot Snapshot, inputs map[string]*Input) Snapshot {
+	if len(inputs) == 0 {
+		return snapshot
+	}
+	return &inputsSnapshot{inner: snapshot, inputs: inputs}
+}
+
+type inputsSnapshot struct {
+	inner  Snapshot
+	inputs map[string]*Input
+}
+
+var _ Snapshot = &inputsSnapshot{}
+
+func (s *inputsSnapshot) Get(key string) (float64, bool, error) {
+	input := s.inputs[key]
+	if input == nil {
+		return s.inner.Get(key)
+	}
+
+	if input.Nodes != nil {
+		filtered, ok := s.inner.(NodeFilteredSnapshot)
+		if !ok {
+			return 0, true, fmt.Errorf("input %q filters nodes, which is not supported by %T", key, s.inner)
+		}
+		return filtered.GetForNodes(input.Source, input.Nodes)
+	}
+
+	if input.Objects != nil {
+		filtered, ok := s.inner.(ObjectFilteredSnapshot)
+		if !ok {
+			return 0, true, fmt.Errorf("input %q filters objects, which is not supported by %T", key, s.inner)
+		}
+		return filtered.GetForObjects(input.Source, input.Objects)
+	}
+
+	return s.inner.Get(input.Source)
+}
+
+func (s *inputsSnapshot) Timestamp() time.Time {
+	return s.inner.Timestamp()
+}
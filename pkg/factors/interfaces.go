@@ -1,6 +1,9 @@
 package factors
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Interface interface {
 	Snapshot() (Snapshot, error)
@@ -11,3 +14,20 @@ type Snapshot interface {
 	Get(key string) (float64, bool, error)
 	Timestamp() time.Time
 }
+
+// NotSyncedError is returned when an input is read before the informer it is computed from has synced.
+// This is expected for a short time after an informer is started, so callers should skip the sample
+// rather than report an error.
+type NotSyncedError struct {
+	Input string
+}
+
+func (e *NotSyncedError) Error() string {
+	return fmt.Sprintf("informer for %q has not yet synced", e.Input)
+}
+
+// IsNotSynced returns true if the error is a NotSyncedError
+func IsNotSynced(err error) bool {
+	_, ok := err.(*NotSyncedError)
+	return ok
+}
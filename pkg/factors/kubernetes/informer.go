@@ -0,0 +1,283 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"github.com/justinsb/scaler/pkg/factors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// informerKubernetesFactors computes the cluster statistics from shared informers.
+// The sums are maintained incrementally as objects are added, updated and deleted, so
+// taking a snapshot does not require any calls to the apiserver.
+type informerKubernetesFactors struct {
+	clock     clock.Clock
+	hasSynced cache.InformerSynced
+
+	// informerFactory and stopCh are used to start informers for the object inputs, when first used
+	informerFactory kubeinformers.SharedInformerFactory
+	stopCh          <-chan struct{}
+
+	countersMutex sync.Mutex
+	// counters holds the objectCounter for each kind of object, created when first used
+	counters map[string]*objectCounter
+
+	mutex sync.Mutex
+	// nodes holds the nodes we have counted, so we can subtract them on update or delete
+	nodes          map[string]*v1.Node
+	sumAllocatable v1.ResourceList
+
+	// filtered caches the ClusterStats for each NodeFilter, so policies using the same filter share the computation.
+	// It is cleared whenever a node changes.
+	filtered map[string]*target.ClusterStats
+}
+
+var _ factors.Interface = &informerKubernetesFactors{}
+
+// informerSnapshot is a snapshot of the cluster statistics.
+// Values for filtered subsets of the nodes are computed on demand, from the current state of the informer.
+type informerSnapshot struct {
+	parent    *informerKubernetesFactors
+	stats     *target.ClusterStats
+	timestamp time.Time
+}
+
+var _ factors.NodeFilteredSnapshot = &informerSnapshot{}
+var _ factors.ObjectFilteredSnapshot = &informerSnapshot{}
+
+// NewInformerKubernetesFactors builds a factors.Interface backed by informers from the shared informer factory.
+// The node informer must be started by the caller (typically by starting the factory).
+// Informers for the object inputs (e.g. pods) are only started when a policy first uses them.
+func NewInformerKubernetesFactors(clock clock.Clock, informerFactory kubeinformers.SharedInformerFactory, stopCh <-chan struct{}) factors.Interface {
+	k := newInformerKubernetesFactors(clock)
+	k.informerFactory = informerFactory
+	k.stopCh = stopCh
+
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	k.hasSynced = nodeInformer.Informer().HasSynced
+
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: k.onNodeUpsert,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			k.onNodeUpsert(newObj)
+		},
+		DeleteFunc: k.onNodeDelete,
+	})
+
+	return k
+}
+
+func newInformerKubernetesFactors(clock clock.Clock) *informerKubernetesFactors {
+	return &informerKubernetesFactors{
+		clock:          clock,
+		nodes:          make(map[string]*v1.Node),
+		sumAllocatable: make(v1.ResourceList),
+		filtered:       make(map[string]*target.ClusterStats),
+		counters:       make(map[string]*objectCounter),
+	}
+}
+
+// counter returns the objectCounter for the object input, starting the informer if this is the first use
+func (k *informerKubernetesFactors) counter(input string) (*objectCounter, error) {
+	kind := objectKinds[input]
+	if kind == "" {
+		return nil, fmt.Errorf("unknown object input %q", input)
+	}
+
+	k.countersMutex.Lock()
+	defer k.countersMutex.Unlock()
+
+	if c := k.counters[kind]; c != nil {
+		return c, nil
+	}
+	if k.informerFactory == nil {
+		return nil, fmt.Errorf("informer for %q not available", kind)
+	}
+
+	var c *objectCounter
+	var informer cache.SharedIndexInformer
+	switch kind {
+	case "pods":
+		c = newObjectCounter(podKind)
+		informer = k.informerFactory.Core().V1().Pods().Informer()
+	case "services":
+		c = newObjectCounter(serviceKind)
+		informer = k.informerFactory.Core().V1().Services().Informer()
+	case "endpoints":
+		c = newObjectCounter(endpointsKind)
+		informer = k.informerFactory.Core().V1().Endpoints().Informer()
+	case "namespaces":
+		c = newObjectCounter(namespaceKind)
+		informer = k.informerFactory.Core().V1().Namespaces().Informer()
+	default:
+		return nil, fmt.Errorf("unhandled kind %q", kind)
+	}
+
+	glog.Infof("starting informer for %s", kind)
+	c.addEventHandler(informer)
+	k.informerFactory.Start(k.stopCh)
+	k.counters[kind] = c
+	return c, nil
+}
+
+func (k *informerKubernetesFactors) onNodeUpsert(obj interface{}) {
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		glog.Warningf("unexpected object type in node informer: %T", obj)
+		return
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if previous, found := k.nodes[node.Name]; found {
+		if node.ResourceVersion != "" && previous.ResourceVersion == node.ResourceVersion {
+			// Unchanged (a resync)
+			return
+		}
+		subtractResourceList(k.sumAllocatable, previous.Status.Allocatable)
+	}
+	k.nodes[node.Name] = node
+	addResourceList(k.sumAllocatable, node.Status.Allocatable)
+	k.filtered = make(map[string]*target.ClusterStats)
+}
+
+func (k *informerKubernetesFactors) onNodeDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*v1.Node)
+	if !ok {
+		glog.Warningf("unexpected object type in node informer: %T", obj)
+		return
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if previous, found := k.nodes[node.Name]; found {
+		subtractResourceList(k.sumAllocatable, previous.Status.Allocatable)
+		delete(k.nodes, node.Name)
+		k.filtered = make(map[string]*target.ClusterStats)
+	}
+}
+
+// filteredStats returns the ClusterStats for the nodes matching the filter
+func (k *informerKubernetesFactors) filteredStats(filter *factors.NodeFilter) *target.ClusterStats {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	key := filter.Key()
+	if stats := k.filtered[key]; stats != nil {
+		return stats
+	}
+
+	stats := &target.ClusterStats{
+		NodeSumAllocatable: make(v1.ResourceList),
+	}
+	for _, node := range k.nodes {
+		if !filter.Matches(node) {
+			continue
+		}
+		stats.NodeCount++
+		addResourceList(stats.NodeSumAllocatable, node.Status.Allocatable)
+	}
+	glog.V(4).Infof("computed cluster state for nodes %s: %v", key, stats)
+
+	k.filtered[key] = stats
+	return stats
+}
+
+func (k *informerKubernetesFactors) Snapshot() (factors.Snapshot, error) {
+	if k.hasSynced != nil && !k.hasSynced() {
+		return nil, fmt.Errorf("node informer has not yet synced")
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	stats := &target.ClusterStats{
+		NodeCount:          len(k.nodes),
+		NodeSumAllocatable: copyResourceList(k.sumAllocatable),
+	}
+	return &informerSnapshot{
+		parent:    k,
+		stats:     stats,
+		timestamp: k.clock.Now(),
+	}, nil
+}
+
+func (s *informerSnapshot) Timestamp() time.Time {
+	return s.timestamp
+}
+
+func (s *informerSnapshot) Get(key string) (float64, bool, error) {
+	if factors.IsObjectInput(key) {
+		return s.GetForObjects(key, nil)
+	}
+	v, found := clusterStatsValue(s.stats, key)
+	return v, found, nil
+}
+
+// GetForObjects returns the count of objects matching the filter (or all objects, if the filter is nil).
+// Unlike the node inputs, the value is read from the informer when it is requested.
+func (s *informerSnapshot) GetForObjects(key string, filter *factors.ObjectFilter) (float64, bool, error) {
+	if !factors.IsObjectInput(key) {
+		return 0, false, nil
+	}
+	c, err := s.parent.counter(key)
+	if err != nil {
+		return 0, true, err
+	}
+	v, err := c.get(key, filter)
+	if err != nil {
+		return 0, true, err
+	}
+	return v, true, nil
+}
+
+func (s *informerSnapshot) GetForNodes(key string, filter *factors.NodeFilter) (float64, bool, error) {
+	if !isClusterStatsKey(key) {
+		return 0, false, nil
+	}
+	v, found := clusterStatsValue(s.parent.filteredStats(filter), key)
+	return v, found, nil
+}
+
+func copyResourceList(in v1.ResourceList) v1.ResourceList {
+	out := make(v1.ResourceList)
+	for k, v := range in {
+		out[k] = v.DeepCopy()
+	}
+	return out
+}
+
+func addResourceList(sum v1.ResourceList, inc v1.ResourceList) {
+	for k, v := range inc {
+		a, found := sum[k]
+		if !found {
+			sum[k] = v.DeepCopy()
+		} else {
+			a.Add(v)
+			sum[k] = a
+		}
+	}
+}
+
+func subtractResourceList(sum v1.ResourceList, dec v1.ResourceList) {
+	for k, v := range dec {
+		a, found := sum[k]
+		if !found {
+			continue
+		}
+		a.Sub(v)
+		sum[k] = a
+	}
+}
@@ -0,0 +1,253 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/pkg/factors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+)
+
+func buildNode(name string, cpu string, memory string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func TestInformerFactorsIncrementalSums(t *testing.T) {
+	k := newInformerKubernetesFactors(clock.NewFakeClock(time.Now()))
+
+	grid := []struct {
+		Name   string
+		Apply  func()
+		Cores  float64
+		Memory float64
+		Nodes  float64
+	}{
+		{
+			Name:  "empty",
+			Apply: func() {},
+		},
+		{
+			Name: "add nodes",
+			Apply: func() {
+				k.onNodeUpsert(buildNode("node1", "4", "1Ki"))
+				k.onNodeUpsert(buildNode("node2", "2", "2Ki"))
+			},
+			Cores:  6,
+			Memory: 3072,
+			Nodes:  2,
+		},
+		{
+			Name: "update node",
+			Apply: func() {
+				k.onNodeUpsert(buildNode("node1", "8", "1Ki"))
+			},
+			Cores:  10,
+			Memory: 3072,
+			Nodes:  2,
+		},
+		{
+			Name: "resync does not double count",
+			Apply: func() {
+				k.onNodeUpsert(buildNode("node1", "8", "1Ki"))
+				k.onNodeUpsert(buildNode("node2", "2", "2Ki"))
+			},
+			Cores:  10,
+			Memory: 3072,
+			Nodes:  2,
+		},
+		{
+			Name: "delete node",
+			Apply: func() {
+				k.onNodeDelete(buildNode("node1", "8", "1Ki"))
+			},
+			Cores:  2,
+			Memory: 2048,
+			Nodes:  1,
+		},
+		{
+			Name: "delete node from tombstone",
+			Apply: func() {
+				k.onNodeDelete(cache.DeletedFinalStateUnknown{Key: "node2", Obj: buildNode("node2", "2", "2Ki")})
+			},
+		},
+		{
+			Name: "fractional cores",
+			Apply: func() {
+				k.onNodeUpsert(buildNode("node3", "3500m", "1Gi"))
+			},
+			Cores:  3.5,
+			Memory: 1024 * 1024 * 1024,
+			Nodes:  1,
+		},
+	}
+
+	for _, g := range grid {
+		g.Apply()
+
+		snapshot, err := k.Snapshot()
+		if err != nil {
+			t.Fatalf("%s: unexpected error from Snapshot: %v", g.Name, err)
+		}
+
+		for key, expected := range map[string]float64{"cores": g.Cores, "memory": g.Memory, "nodes": g.Nodes} {
+			actual, found, err := snapshot.Get(key)
+			if err != nil || !found {
+				t.Errorf("%s: unexpected result getting %q: found=%v err=%v", g.Name, key, found, err)
+				continue
+			}
+			if actual != expected {
+				t.Errorf("%s: unexpected value for %q: actual=%v expected=%v", g.Name, key, actual, expected)
+			}
+		}
+	}
+}
+
+func TestInformerFactorsNotSynced(t *testing.T) {
+	k := newInformerKubernetesFactors(clock.NewFakeClock(time.Now()))
+	k.hasSynced = func() bool { return false }
+
+	if _, err := k.Snapshot(); err == nil {
+		t.Errorf("expected error from Snapshot before informer has synced")
+	}
+}
+
+func TestInformerFactorsNodeFilter(t *testing.T) {
+	k := newInformerKubernetesFactors(clock.NewFakeClock(time.Now()))
+
+	ready := v1.NodeCondition{Type: v1.NodeReady, Status: v1.ConditionTrue}
+	notReady := v1.NodeCondition{Type: v1.NodeReady, Status: v1.ConditionFalse}
+
+	master := buildNode("master", "2", "1Ki")
+	master.Spec.Taints = []v1.Taint{{Key: "node-role.kubernetes.io/master", Effect: v1.TaintEffectNoSchedule}}
+	master.Status.Conditions = []v1.NodeCondition{ready}
+	k.onNodeUpsert(master)
+
+	ingress1 := buildNode("ingress1", "4", "1Ki")
+	ingress1.Labels = map[string]string{"pool": "ingress"}
+	ingress1.Status.Conditions = []v1.NodeCondition{ready}
+	k.onNodeUpsert(ingress1)
+
+	ingress2 := buildNode("ingress2", "4", "1Ki")
+	ingress2.Labels = map[string]string{"pool": "ingress"}
+	ingress2.Status.Conditions = []v1.NodeCondition{notReady}
+	k.onNodeUpsert(ingress2)
+
+	gpu := buildNode("gpu", "8", "1Ki")
+	gpu.Labels = map[string]string{"pool": "gpu"}
+	gpu.Spec.Taints = []v1.Taint{
+		{Key: "gpu", Value: "true", Effect: v1.TaintEffectNoExecute},
+		{Key: "preferred", Effect: v1.TaintEffectPreferNoSchedule},
+	}
+	gpu.Status.Conditions = []v1.NodeCondition{ready}
+	k.onNodeUpsert(gpu)
+
+	grid := []struct {
+		Name   string
+		Filter *factors.NodeFilter
+		Cores  float64
+		Nodes  float64
+	}{
+		{
+			Name:   "untainted nodes",
+			Filter: &factors.NodeFilter{Selector: labels.Everything()},
+			Cores:  8,
+			Nodes:  2,
+		},
+		{
+			Name:   "selector",
+			Filter: &factors.NodeFilter{Selector: labels.SelectorFromSet(labels.Set{"pool": "ingress"})},
+			Cores:  8,
+			Nodes:  2,
+		},
+		{
+			Name:   "selector and ready",
+			Filter: &factors.NodeFilter{Selector: labels.SelectorFromSet(labels.Set{"pool": "ingress"}), ReadyOnly: true},
+			Cores:  4,
+			Nodes:  1,
+		},
+		{
+			Name: "tolerated taint",
+			Filter: &factors.NodeFilter{
+				Selector:    labels.SelectorFromSet(labels.Set{"pool": "gpu"}),
+				Tolerations: []v1.Toleration{{Key: "gpu", Operator: v1.TolerationOpEqual, Value: "true"}},
+			},
+			Cores: 8,
+			Nodes: 1,
+		},
+		{
+			Name: "tolerate everything",
+			Filter: &factors.NodeFilter{
+				Selector:    labels.Everything(),
+				Tolerations: []v1.Toleration{{Operator: v1.TolerationOpExists}},
+				ReadyOnly:   true,
+			},
+			Cores: 14,
+			Nodes: 3,
+		},
+	}
+
+	for _, g := range grid {
+		snapshot, err := k.Snapshot()
+		if err != nil {
+			t.Fatalf("%s: unexpected error from Snapshot: %v", g.Name, err)
+		}
+		filtered := snapshot.(factors.NodeFilteredSnapshot)
+
+		for key, expected := range map[string]float64{"cores": g.Cores, "nodes": g.Nodes} {
+			actual, found, err := filtered.GetForNodes(key, g.Filter)
+			if err != nil || !found {
+				t.Errorf("%s: unexpected result getting %q: found=%v err=%v", g.Name, key, found, err)
+				continue
+			}
+			if actual != expected {
+				t.Errorf("%s: unexpected value for %q: actual=%v expected=%v", g.Name, key, actual, expected)
+			}
+		}
+	}
+
+	// The cached values should be invalidated when a node changes
+	filter := &factors.NodeFilter{Selector: labels.SelectorFromSet(labels.Set{"pool": "ingress"}), ReadyOnly: true}
+	ingress2 = ingress2.DeepCopy()
+	ingress2.Status.Conditions = []v1.NodeCondition{ready}
+	k.onNodeUpsert(ingress2)
+
+	snapshot, err := k.Snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error from Snapshot: %v", err)
+	}
+	actual, _, err := snapshot.(factors.NodeFilteredSnapshot).GetForNodes("nodes", filter)
+	if err != nil {
+		t.Fatalf("unexpected error from GetForNodes: %v", err)
+	}
+	if actual != 2 {
+		t.Errorf("expected 2 nodes after node became ready, got %v", actual)
+	}
+
+	// The named inputs should resolve to the filtered values
+	withInputs := factors.WithInputs(snapshot, map[string]*factors.Input{
+		"ingressCores": {Source: "cores", Nodes: filter},
+		"allNodes":     {Source: "nodes"},
+	})
+	if v, found, err := withInputs.Get("ingressCores"); err != nil || !found || v != 8 {
+		t.Errorf("unexpected value for ingressCores: %v found=%v err=%v", v, found, err)
+	}
+	if v, found, err := withInputs.Get("allNodes"); err != nil || !found || v != 4 {
+		t.Errorf("unexpected value for allNodes: %v found=%v err=%v", v, found, err)
+	}
+	if v, found, err := withInputs.Get("cores"); err != nil || !found || v != 18 {
+		t.Errorf("unexpected value for cores: %v found=%v err=%v", v, found, err)
+	}
+}
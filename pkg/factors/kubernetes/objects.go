@@ -0,0 +1,222 @@
+package kubernetes
+
+import (
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/justinsb/scaler/pkg/factors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// objectKind describes how we count the objects of a kind
+type objectKind struct {
+	// values returns the amount the object contributes to each input
+	values func(obj interface{}) map[string]float64
+
+	// fields returns the fields which can be used in a field selector
+	fields func(obj interface{}) fields.Set
+}
+
+// objectKinds maps each object input to the kind of object we count
+var objectKinds = map[string]string{
+	factors.InputPods:       "pods",
+	factors.InputContainers: "pods",
+	factors.InputServices:   "services",
+	factors.InputEndpoints:  "endpoints",
+	factors.InputNamespaces: "namespaces",
+}
+
+var podKind = &objectKind{
+	values: func(obj interface{}) map[string]float64 {
+		pod := obj.(*v1.Pod)
+		return map[string]float64{
+			factors.InputPods:       1,
+			factors.InputContainers: float64(len(pod.Spec.Containers)),
+		}
+	},
+	fields: func(obj interface{}) fields.Set {
+		pod := obj.(*v1.Pod)
+		return fields.Set{
+			"metadata.name":      pod.Name,
+			"metadata.namespace": pod.Namespace,
+			"spec.nodeName":      pod.Spec.NodeName,
+			"status.phase":       string(pod.Status.Phase),
+		}
+	},
+}
+
+var serviceKind = &objectKind{
+	values: func(obj interface{}) map[string]float64 {
+		return map[string]float64{factors.InputServices: 1}
+	},
+	fields: func(obj interface{}) fields.Set {
+		service := obj.(*v1.Service)
+		return fields.Set{
+			"metadata.name":      service.Name,
+			"metadata.namespace": service.Namespace,
+			"spec.type":          string(service.Spec.Type),
+		}
+	},
+}
+
+var endpointsKind = &objectKind{
+	values: func(obj interface{}) map[string]float64 {
+		endpoints := obj.(*v1.Endpoints)
+		addresses := 0
+		for i := range endpoints.Subsets {
+			addresses += len(endpoints.Subsets[i].Addresses)
+		}
+		return map[string]float64{factors.InputEndpoints: float64(addresses)}
+	},
+	fields: func(obj interface{}) fields.Set {
+		endpoints := obj.(*v1.Endpoints)
+		return fields.Set{
+			"metadata.name":      endpoints.Name,
+			"metadata.namespace": endpoints.Namespace,
+		}
+	},
+}
+
+var namespaceKind = &objectKind{
+	values: func(obj interface{}) map[string]float64 {
+		return map[string]float64{factors.InputNamespaces: 1}
+	},
+	fields: func(obj interface{}) fields.Set {
+		namespace := obj.(*v1.Namespace)
+		return fields.Set{
+			"metadata.name": namespace.Name,
+			"status.phase":  string(namespace.Status.Phase),
+		}
+	},
+}
+
+// countedObject is an object we have counted, with its contribution to the inputs
+type countedObject struct {
+	labels labels.Set
+	fields fields.Set
+	values map[string]float64
+}
+
+// objectCounter maintains counts of the objects of a kind, from an informer.
+// The unfiltered sums are maintained incrementally; filtered sums are computed on demand and cached.
+type objectCounter struct {
+	kind      *objectKind
+	hasSynced cache.InformerSynced
+
+	mutex   sync.Mutex
+	objects map[string]*countedObject
+	sums    map[string]float64
+
+	// filtered caches the sums for each ObjectFilter; it is cleared whenever an object changes
+	filtered map[string]map[string]float64
+}
+
+func newObjectCounter(kind *objectKind) *objectCounter {
+	return &objectCounter{
+		kind:     kind,
+		objects:  make(map[string]*countedObject),
+		sums:     make(map[string]float64),
+		filtered: make(map[string]map[string]float64),
+	}
+}
+
+// addEventHandler registers the counter with the informer
+func (c *objectCounter) addEventHandler(informer cache.SharedIndexInformer) {
+	c.hasSynced = informer.HasSynced
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.onUpsert,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			c.onUpsert(newObj)
+		},
+		DeleteFunc: c.onDelete,
+	})
+}
+
+func (c *objectCounter) onUpsert(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Warningf("error getting key for object: %v", err)
+		return
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		glog.Warningf("error getting metadata for object: %v", err)
+		return
+	}
+
+	counted := &countedObject{
+		labels: labels.Set(accessor.GetLabels()),
+		fields: c.kind.fields(obj),
+		values: c.kind.values(obj),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if previous := c.objects[key]; previous != nil {
+		subtractValues(c.sums, previous.values)
+	}
+	c.objects[key] = counted
+	addValues(c.sums, counted.values)
+	c.filtered = make(map[string]map[string]float64)
+}
+
+func (c *objectCounter) onDelete(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		glog.Warningf("error getting key for object: %v", err)
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if previous := c.objects[key]; previous != nil {
+		subtractValues(c.sums, previous.values)
+		delete(c.objects, key)
+		c.filtered = make(map[string]map[string]float64)
+	}
+}
+
+// get returns the sum for the input, over all the objects or over the objects matching the filter
+func (c *objectCounter) get(input string, filter *factors.ObjectFilter) (float64, error) {
+	if c.hasSynced != nil && !c.hasSynced() {
+		return 0, &factors.NotSyncedError{Input: input}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if filter == nil {
+		return c.sums[input], nil
+	}
+
+	key := filter.Key()
+	sums := c.filtered[key]
+	if sums == nil {
+		sums = make(map[string]float64)
+		for _, o := range c.objects {
+			if filter.Matches(o.labels, o.fields) {
+				addValues(sums, o.values)
+			}
+		}
+		c.filtered[key] = sums
+	}
+	return sums[input], nil
+}
+
+func addValues(sums map[string]float64, values map[string]float64) {
+	for k, v := range values {
+		sums[k] += v
+	}
+}
+
+func subtractValues(sums map[string]float64, values map[string]float64) {
+	for k, v := range values {
+		sums[k] -= v
+	}
+}
@@ -0,0 +1,147 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/justinsb/scaler/pkg/factors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+)
+
+func buildPod(namespace, name string, containers int, phase v1.PodPhase, podLabels map[string]string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: podLabels},
+		Status:     v1.PodStatus{Phase: phase},
+	}
+	for i := 0; i < containers; i++ {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{})
+	}
+	return pod
+}
+
+func TestObjectCounterPods(t *testing.T) {
+	c := newObjectCounter(podKind)
+
+	c.onUpsert(buildPod("ns1", "pod1", 2, v1.PodRunning, map[string]string{"app": "web"}))
+	c.onUpsert(buildPod("ns1", "pod2", 1, v1.PodPending, map[string]string{"app": "web"}))
+	c.onUpsert(buildPod("ns2", "pod1", 3, v1.PodRunning, nil))
+	// An update replaces the previous contribution
+	c.onUpsert(buildPod("ns2", "pod1", 1, v1.PodRunning, nil))
+
+	grid := []struct {
+		Name       string
+		Filter     *factors.ObjectFilter
+		Pods       float64
+		Containers float64
+	}{
+		{
+			Name:       "all pods",
+			Pods:       3,
+			Containers: 4,
+		},
+		{
+			Name:       "label selector",
+			Filter:     &factors.ObjectFilter{Selector: labels.SelectorFromSet(labels.Set{"app": "web"})},
+			Pods:       2,
+			Containers: 3,
+		},
+		{
+			Name:       "field selector",
+			Filter:     &factors.ObjectFilter{FieldSelector: fields.OneTermEqualSelector("status.phase", "Running")},
+			Pods:       2,
+			Containers: 3,
+		},
+		{
+			Name: "label and field selector",
+			Filter: &factors.ObjectFilter{
+				Selector:      labels.SelectorFromSet(labels.Set{"app": "web"}),
+				FieldSelector: fields.OneTermEqualSelector("metadata.namespace", "ns1"),
+			},
+			Pods:       2,
+			Containers: 3,
+		},
+	}
+
+	for _, g := range grid {
+		for key, expected := range map[string]float64{factors.InputPods: g.Pods, factors.InputContainers: g.Containers} {
+			actual, err := c.get(key, g.Filter)
+			if err != nil {
+				t.Errorf("%s: unexpected error getting %q: %v", g.Name, key, err)
+				continue
+			}
+			if actual != expected {
+				t.Errorf("%s: unexpected value for %q: actual=%v expected=%v", g.Name, key, actual, expected)
+			}
+		}
+	}
+
+	// Deleting should update both the sums and the cached filtered values
+	filter := &factors.ObjectFilter{Selector: labels.SelectorFromSet(labels.Set{"app": "web"})}
+	c.onDelete(cache.DeletedFinalStateUnknown{Key: "ns1/pod1", Obj: buildPod("ns1", "pod1", 2, v1.PodRunning, nil)})
+	if v, _ := c.get(factors.InputPods, nil); v != 2 {
+		t.Errorf("unexpected pods after delete: %v", v)
+	}
+	if v, _ := c.get(factors.InputContainers, filter); v != 1 {
+		t.Errorf("unexpected filtered containers after delete: %v", v)
+	}
+}
+
+func TestObjectCounterEndpoints(t *testing.T) {
+	c := newObjectCounter(endpointsKind)
+
+	c.onUpsert(&v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "svc1"},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses:         []v1.EndpointAddress{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}},
+				NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.3"}},
+			},
+			{
+				Addresses: []v1.EndpointAddress{{IP: "10.0.0.4"}},
+			},
+		},
+	})
+	c.onUpsert(&v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "svc2"},
+	})
+
+	if v, err := c.get(factors.InputEndpoints, nil); err != nil || v != 3 {
+		t.Errorf("unexpected endpoints: %v err=%v", v, err)
+	}
+}
+
+func TestInformerSnapshotObjectInputs(t *testing.T) {
+	k := newInformerKubernetesFactors(clock.NewFakeClock(time.Now()))
+
+	services := newObjectCounter(serviceKind)
+	services.onUpsert(&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "svc1"}})
+	services.onUpsert(&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "svc1"}})
+	k.counters["services"] = services
+
+	snapshot, err := k.Snapshot()
+	if err != nil {
+		t.Fatalf("unexpected error from Snapshot: %v", err)
+	}
+	if v, found, err := snapshot.Get(factors.InputServices); err != nil || !found || v != 2 {
+		t.Errorf("unexpected services: %v found=%v err=%v", v, found, err)
+	}
+
+	// Without an informer factory, we can't start informers for other kinds
+	if _, found, err := snapshot.Get(factors.InputNamespaces); err == nil || !found {
+		t.Errorf("expected error for namespaces without informer: found=%v err=%v", found, err)
+	}
+	if _, found, _ := snapshot.Get("unknown"); found {
+		t.Errorf("expected unknown input not to be found")
+	}
+
+	// An informer which has not synced should be an error, not zero, which callers can recognize to skip the sample
+	services.hasSynced = func() bool { return false }
+	if _, _, err := snapshot.Get(factors.InputServices); !factors.IsNotSynced(err) {
+		t.Errorf("expected NotSynced error reading services before informer has synced, got %v", err)
+	}
+}
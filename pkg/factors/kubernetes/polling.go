@@ -57,46 +57,47 @@ func (s *pollingKubernetesSnapshot) Get(key string) (float64, bool, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if !isClusterStatsKey(key) {
+		return 0, false, nil
+	}
+	if err := s.ensureClusterStats(); err != nil {
+		return 0, true, err
+	}
+	v, found := clusterStatsValue(s.stats, key)
+	return v, found, nil
+}
+
+// isClusterStatsKey returns true if the key is computed from the ClusterStats
+func isClusterStatsKey(key string) bool {
+	return factors.IsNodeInput(key)
+}
+
+// clusterStatsValue returns the value of the key from the ClusterStats
+func clusterStatsValue(stats *target.ClusterStats, key string) (float64, bool) {
 	switch key {
 	// TODO: Syntax here is not very consistent e.g. sum(nodes.allocatable.cpu) or count(nodes)
-	case "cores":
-		{
-			if err := s.ensureClusterStats(); err != nil {
-				return 0, true, err
-			}
-			r, found := s.stats.NodeSumAllocatable[v1.ResourceCPU]
-			if found {
-				return float64(r.Value()), true, nil
-			} else {
-				// Return found=true: We recognized the value, even though we didn't have any statistics on it
-				// TODO: Is this correct?
-				return 0, true, nil
-			}
+	case factors.InputCores:
+		r, found := stats.NodeSumAllocatable[v1.ResourceCPU]
+		if found {
+			// We keep fractional cores, so that e.g. 3900m allocatable is not rounded up to 4 cores
+			return float64(r.MilliValue()) / 1000, true
 		}
-	case "memory":
-		{
-			if err := s.ensureClusterStats(); err != nil {
-				return 0, true, err
-			}
-			r, found := s.stats.NodeSumAllocatable[v1.ResourceMemory]
-			if found {
-				return float64(r.Value()), true, nil
-			} else {
-				// Return found=true: We recognized the value, even though we didn't have any statistics on it
-				// TODO: Is this correct?
-				return 0, true, nil
-			}
-		}
-	case "nodes":
-		{
-			if err := s.ensureClusterStats(); err != nil {
-				return 0, true, err
-			}
-			return float64(s.stats.NodeCount), true, nil
+		// Return found=true: We recognized the value, even though we didn't have any statistics on it
+		// TODO: Is this correct?
+		return 0, true
+	case factors.InputMemory:
+		r, found := stats.NodeSumAllocatable[v1.ResourceMemory]
+		if found {
+			return float64(r.Value()), true
 		}
+		// Return found=true: We recognized the value, even though we didn't have any statistics on it
+		// TODO: Is this correct?
+		return 0, true
+	case factors.InputNodes:
+		return float64(stats.NodeCount), true
 	default:
 		// unknown
-		return 0, false, nil
+		return 0, false
 	}
 }
 
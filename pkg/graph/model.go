@@ -65,8 +65,13 @@ type Value struct {
 }
 
 func AddPodDataPoints(graph *Model, prefix string, x float64, podSpec *v1.PodSpec, options *Series) {
-	for i := range podSpec.Containers {
-		container := &podSpec.Containers[i]
+	addContainerDataPoints(graph, prefix, x, podSpec.InitContainers, options)
+	addContainerDataPoints(graph, prefix, x, podSpec.Containers, options)
+}
+
+func addContainerDataPoints(graph *Model, prefix string, x float64, containers []v1.Container, options *Series) {
+	for i := range containers {
+		container := &containers[i]
 
 		for k, q := range container.Resources.Limits {
 			v, units := resourceToFloat(k, q)
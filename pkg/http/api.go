@@ -7,6 +7,7 @@ import (
 	"github.com/justinsb/scaler/cmd/scaler/options"
 	"github.com/justinsb/scaler/pkg/graph"
 	"github.com/justinsb/scaler/pkg/simulate"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type APIServer struct {
@@ -17,6 +18,11 @@ type HasState interface {
 	Query() interface{}
 }
 
+// HasLeader is implemented by a state which reports the leader election
+type HasLeader interface {
+	LeaderInfo() *LeaderInfo
+}
+
 func NewAPIServer(options *options.AutoScalerConfig, state HasState) (*APIServer, error) {
 	mux := http.NewServeMux()
 
@@ -28,10 +34,15 @@ func NewAPIServer(options *options.AutoScalerConfig, state HasState) (*APIServer
 
 	mux.Handle("/api/statz", &Targets{state: state})
 
+	mux.Handle("/metrics", promhttp.Handler())
+
 	ui := &UI{
 		simulatable: state.(simulate.Simulatable),
 		graphable:   state.(graph.Graphable),
 	}
+	if l, ok := state.(HasLeader); ok {
+		ui.leader = l
+	}
 	ui.AddHandlers(mux)
 
 	server := &http.Server{
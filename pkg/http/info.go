@@ -1,6 +1,9 @@
 package http
 
-import "k8s.io/api/core/v1"
+import (
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
 
 type Info struct {
 	LatestTarget       *v1.PodSpec `json:"latestTarget"`
@@ -10,6 +13,24 @@ type Info struct {
 	LatestActual *v1.PodSpec `json:"latestActual"`
 
 	Histograms map[string]*HistogramInfo `json:"histograms"`
+
+	// Clamped lists the resources where the target is currently limited by the min or max of the rule
+	Clamped []ClampedInfo `json:"clamped,omitempty"`
+}
+
+// ClampedInfo describes a resource target that was limited by a min or max bound
+type ClampedInfo struct {
+	Container string          `json:"container"`
+	Type      string          `json:"type"`
+	Resource  v1.ResourceName `json:"resource"`
+
+	// Bound is the bound that was applied: "min" or "max"
+	Bound string `json:"bound"`
+
+	// Computed is the value computed by the function, before the bound was applied
+	Computed resource.Quantity `json:"computed"`
+	// Value is the target value after the bound was applied
+	Value resource.Quantity `json:"value"`
 }
 
 type HistogramInfo struct {
@@ -20,3 +41,13 @@ type HistogramDataPoint struct {
 	Time  int64 `json:"time"`
 	Value int64 `json:"value"`
 }
+
+// LeaderInfo describes the leader election, as seen by this replica
+type LeaderInfo struct {
+	// Identity is the identity of this replica
+	Identity string `json:"identity,omitempty"`
+	// Leader is the identity of the replica currently applying policies, if known
+	Leader string `json:"leader,omitempty"`
+	// IsLeader is true if this replica is applying policies
+	IsLeader bool `json:"isLeader"`
+}
@@ -14,6 +14,9 @@ import (
 type UI struct {
 	simulatable simulate.Simulatable
 	graphable   graph.Graphable
+
+	// leader reports the leader election; it is nil if the state doesn't support it
+	leader HasLeader
 }
 
 func (u *UI) AddHandlers(mux *http.ServeMux) {
@@ -63,7 +66,7 @@ func (u *UI) ServeGraphPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	{
-		contents, err := templates.BuildGraphListPage(graphs)
+		contents, err := templates.BuildGraphListPage(graphs, u.describeLeader())
 		w.Header().Set("Content-Type", "text/html")
 		if err != nil {
 			internalError(w, r, err)
@@ -119,7 +122,7 @@ func (u *UI) ServeSimulatePage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	{
-		contents, err := templates.BuildSimulateListPage(simulations)
+		contents, err := templates.BuildSimulateListPage(simulations, u.describeLeader())
 		w.Header().Set("Content-Type", "text/html")
 		if err != nil {
 			internalError(w, r, err)
@@ -133,6 +136,26 @@ func (u *UI) ServeSimulatePage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// describeLeader returns a human-readable description of the leader, for the UI
+func (u *UI) describeLeader() string {
+	if u.leader == nil {
+		return ""
+	}
+	return describeLeader(u.leader.LeaderInfo())
+}
+
+// describeLeader returns a human-readable description of the leader
+func describeLeader(info *LeaderInfo) string {
+	switch {
+	case info.IsLeader:
+		return fmt.Sprintf("%s (this replica)", info.Identity)
+	case info.Leader != "":
+		return fmt.Sprintf("%s (this replica is %s, and is only observing)", info.Leader, info.Identity)
+	default:
+		return "not yet known"
+	}
+}
+
 func internalError(w http.ResponseWriter, r *http.Request, err error) {
 	http.Error(w, fmt.Sprintf("Internal error %v", err), 500)
 }
@@ -0,0 +1,233 @@
+package scaling
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Checkpoint holds the state of a ScalingPolicyEvaluator, so that the windows used for the
+// delays & smoothing survive a restart of the scaler.
+type Checkpoint struct {
+	// Timestamp is the time at which the checkpoint was taken
+	Timestamp time.Time `json:"timestamp"`
+
+	// Rules holds the state of each rule, keyed by the path of the rule e.g. container1.limits.memory[0]
+	Rules map[string]*RuleCheckpoint `json:"rules,omitempty"`
+}
+
+// RuleCheckpoint holds the state of a single ResourceScalingRule
+type RuleCheckpoint struct {
+	// Rule is the rule the state was computed for; we only restore the state if the rule is unchanged
+	Rule scalingpolicy.ResourceScalingRule `json:"rule"`
+
+	Target              SeriesCheckpoint `json:"target"`
+	ScaleDownThresholds SeriesCheckpoint `json:"scaleDownThresholds"`
+	ScaleUpThresholds   SeriesCheckpoint `json:"scaleUpThresholds"`
+
+	TargetSmoother             SmootherCheckpoint `json:"targetSmoother"`
+	ScaleDownThresholdSmoother SmootherCheckpoint `json:"scaleDownThresholdSmoother"`
+	ScaleUpThresholdSmoother   SmootherCheckpoint `json:"scaleUpThresholdSmoother"`
+
+	// LastScaleDown and LastScaleUp are the times at which we last applied a change
+	LastScaleDown time.Time `json:"lastScaleDown"`
+	LastScaleUp   time.Time `json:"lastScaleUp"`
+
+	// Held is the value the rule would have applied if it were the only rule, when there are multiple rules for a resource
+	Held *resource.Quantity `json:"held,omitempty"`
+}
+
+// SeriesCheckpoint holds the values of a timeSeries
+type SeriesCheckpoint struct {
+	Start   time.Time          `json:"start"`
+	Samples []SampleCheckpoint `json:"samples,omitempty"`
+}
+
+// SampleCheckpoint is a single value in a SeriesCheckpoint
+type SampleCheckpoint struct {
+	Time  time.Time `json:"t"`
+	Value float64   `json:"v"`
+}
+
+// SmootherCheckpoint holds the state of a smoother
+type SmootherCheckpoint struct {
+	Raw      SeriesCheckpoint `json:"raw"`
+	EWMA     float64          `json:"ewma,omitempty"`
+	EWMATime time.Time        `json:"ewmaTime"`
+	HasEWMA  bool             `json:"hasEWMA,omitempty"`
+}
+
+// Checkpoint returns the current state, so that it can be persisted and passed to Restore after a restart
+func (e *ScalingPolicyEvaluator) Checkpoint() *Checkpoint {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	checkpoint := &Checkpoint{
+		Timestamp: e.clock.Now(),
+		Rules:     make(map[string]*RuleCheckpoint),
+	}
+	for k, ce := range e.containers {
+		ce.checkpoint(k, checkpoint)
+	}
+	return checkpoint
+}
+
+// Restore restores the state from a checkpoint.  The state of a rule is only restored if the rule is unchanged,
+// and a window is only restored if the checkpoint is recent enough that the window would still hold values.
+func (e *ScalingPolicyEvaluator) Restore(checkpoint *Checkpoint) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := e.clock.Now()
+	for k, ce := range e.containers {
+		ce.restore(k, now, checkpoint)
+	}
+}
+
+func (e *containerScalingRuleEvaluator) checkpoint(parentPath string, checkpoint *Checkpoint) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for k, re := range e.limits {
+		re.checkpoint(parentPath+".limits."+string(k), checkpoint)
+	}
+	for k, re := range e.requests {
+		re.checkpoint(parentPath+".requests."+string(k), checkpoint)
+	}
+}
+
+func (e *containerScalingRuleEvaluator) restore(parentPath string, now time.Time, checkpoint *Checkpoint) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	restoreResourceMap(parentPath+".limits.", e.limits, now, checkpoint)
+	restoreResourceMap(parentPath+".requests.", e.requests, now, checkpoint)
+}
+
+func restoreResourceMap(prefix string, evaluators map[v1.ResourceName]*resourceRulesEvaluator, now time.Time, checkpoint *Checkpoint) {
+	for k, re := range evaluators {
+		re.restore(prefix+string(k), now, checkpoint)
+	}
+}
+
+func (e *resourceRulesEvaluator) checkpoint(parentPath string, checkpoint *Checkpoint) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i, term := range e.terms {
+		c := term.checkpoint()
+		if e.held[i] != nil {
+			held := e.held[i].DeepCopy()
+			c.Held = &held
+		}
+		checkpoint.Rules[fmt.Sprintf("%s[%d]", parentPath, i)] = c
+	}
+}
+
+func (e *resourceRulesEvaluator) restore(parentPath string, now time.Time, checkpoint *Checkpoint) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i, term := range e.terms {
+		termPath := fmt.Sprintf("%s[%d]", parentPath, i)
+		c := checkpoint.Rules[termPath]
+		if c == nil {
+			continue
+		}
+		if !term.restore(termPath, now, checkpoint.Timestamp, c) {
+			continue
+		}
+		if c.Held != nil {
+			held := c.Held.DeepCopy()
+			e.held[i] = &held
+		}
+	}
+}
+
+func (e *resourceScalingRuleEvaluator) checkpoint() *RuleCheckpoint {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return &RuleCheckpoint{
+		Rule:                       *e.policy.DeepCopy(),
+		Target:                     e.target.checkpoint(),
+		ScaleDownThresholds:        e.scaleDownThresholds.checkpoint(),
+		ScaleUpThresholds:          e.scaleUpThresholds.checkpoint(),
+		TargetSmoother:             e.targetSmoother.checkpoint(),
+		ScaleDownThresholdSmoother: e.scaleDownThresholdSmoother.checkpoint(),
+		ScaleUpThresholdSmoother:   e.scaleUpThresholdSmoother.checkpoint(),
+		LastScaleDown:              e.lastScaleDown,
+		LastScaleUp:                e.lastScaleUp,
+	}
+}
+
+// restore restores the state from the checkpoint, returning false if the rule has changed since the checkpoint was taken
+func (e *resourceScalingRuleEvaluator) restore(parentPath string, now time.Time, timestamp time.Time, c *RuleCheckpoint) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if !equality.Semantic.DeepEqual(&c.Rule, e.policy) {
+		glog.Infof("rule has changed since checkpoint; not restoring state for %s", parentPath)
+		return false
+	}
+
+	e.target.restore(now, timestamp, &c.Target)
+	e.scaleDownThresholds.restore(now, timestamp, &c.ScaleDownThresholds)
+	e.scaleUpThresholds.restore(now, timestamp, &c.ScaleUpThresholds)
+	e.targetSmoother.restore(now, timestamp, &c.TargetSmoother)
+	e.scaleDownThresholdSmoother.restore(now, timestamp, &c.ScaleDownThresholdSmoother)
+	e.scaleUpThresholdSmoother.restore(now, timestamp, &c.ScaleUpThresholdSmoother)
+	e.lastScaleDown = c.LastScaleDown
+	e.lastScaleUp = c.LastScaleUp
+
+	glog.V(2).Infof("restored state for %s from checkpoint taken at %s", parentPath, timestamp)
+	return true
+}
+
+func (s *timeSeries) checkpoint() SeriesCheckpoint {
+	c := SeriesCheckpoint{Start: s.Start}
+	for i := 0; i < s.count; i++ {
+		v := s.at(i)
+		c.Samples = append(c.Samples, SampleCheckpoint{Time: v.t, Value: v.value})
+	}
+	return c
+}
+
+// restore replaces the values with those from the checkpoint.  If the checkpoint is older than the retention period,
+// we would have expired all the values, and we don't know what happened while we weren't observing, so we start afresh.
+func (s *timeSeries) restore(now time.Time, timestamp time.Time, c *SeriesCheckpoint) {
+	if now.Sub(timestamp) > s.Retention {
+		return
+	}
+
+	s.Start = c.Start
+	s.samples = nil
+	s.head = 0
+	s.count = 0
+	for _, v := range c.Samples {
+		s.addObservation(v.Time, v.Value)
+	}
+}
+
+func (s *smoother) checkpoint() SmootherCheckpoint {
+	return SmootherCheckpoint{
+		Raw:      s.raw.checkpoint(),
+		EWMA:     s.ewma,
+		EWMATime: s.ewmaTime,
+		HasEWMA:  s.hasEWMA,
+	}
+}
+
+func (s *smoother) restore(now time.Time, timestamp time.Time, c *SmootherCheckpoint) {
+	s.raw.restore(now, timestamp, &c.Raw)
+
+	// The moving average decays with the time since it was last updated, so it remains valid after a gap
+	s.ewma = c.EWMA
+	s.ewmaTime = c.EWMATime
+	s.hasEWMA = c.HasEWMA
+}
@@ -0,0 +1,126 @@
+package scaling
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestCheckpointRestore(t *testing.T) {
+	grid := []struct {
+		Name string
+		// DelaySeconds is the scale-down delay of the policy after the restart
+		DelaySeconds int32
+		// Restart is how long after the checkpoint the scaler restarts
+		Restart time.Duration
+		// Expected is the value we scale down to 30 seconds after the restart, if any
+		Expected string
+	}{
+		{
+			Name:         "restored",
+			DelaySeconds: 60,
+			Restart:      5 * time.Second,
+			Expected:     "50Mi",
+		},
+		{
+			Name:         "rule changed",
+			DelaySeconds: 61,
+			Restart:      5 * time.Second,
+		},
+		{
+			Name:         "checkpoint older than the window",
+			DelaySeconds: 60,
+			Restart:      70 * time.Second,
+		},
+	}
+
+	buildPolicy := func(delaySeconds int32) *scalingpolicy.ScalingPolicy {
+		return &scalingpolicy.ScalingPolicy{
+			Spec: scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Slope: resource.MustParse("10Mi"),
+										DelayScaleDown: &scalingpolicy.DelayScaling{
+											DelaySeconds: delaySeconds,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	for _, g := range grid {
+		baseTime := time.Now()
+		inputs := map[string]float64{"pods": 10}
+		actual := &v1.PodSpec{Containers: []v1.Container{{Name: "container1"}}}
+
+		observe := func(clock *clock.FakeClock, evaluator *ScalingPolicyEvaluator) *v1.PodSpec {
+			snapshot, err := static.NewStaticFactors(clock, inputs).Snapshot()
+			if err != nil {
+				t.Fatalf("snapshot failed: %v", err)
+			}
+			evaluator.AddObservation(snapshot)
+			changes, err := evaluator.ComputeResources("", actual)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if changes != nil {
+				actual = changes
+			}
+			return changes
+		}
+
+		// Observe until just before the delay has passed
+		clock1 := clock.NewFakeClock(baseTime)
+		evaluator1 := NewScalingPolicyEvaluator(clock1, buildPolicy(60))
+		observe(clock1, evaluator1)
+		inputs["pods"] = 5
+		clock1.Step(10 * time.Second)
+		observe(clock1, evaluator1)
+		clock1.Step(30 * time.Second)
+		observe(clock1, evaluator1)
+
+		data, err := json.Marshal(evaluator1.Checkpoint())
+		if err != nil {
+			t.Fatalf("error serializing checkpoint: %v", err)
+		}
+		checkpoint := &Checkpoint{}
+		if err := json.Unmarshal(data, checkpoint); err != nil {
+			t.Fatalf("error parsing checkpoint: %v", err)
+		}
+
+		// Restart, and observe once the delay has passed (measured from before the restart)
+		clock2 := clock.NewFakeClock(clock1.Now().Add(g.Restart))
+		evaluator2 := NewScalingPolicyEvaluator(clock2, buildPolicy(g.DelaySeconds))
+		evaluator2.Restore(checkpoint)
+		observe(clock2, evaluator2)
+		clock2.Step(30 * time.Second)
+		changes := observe(clock2, evaluator2)
+
+		changed := ""
+		if changes != nil {
+			q := changes.Containers[0].Resources.Requests[v1.ResourceMemory]
+			changed = q.String()
+		}
+		if changed != g.Expected {
+			t.Errorf("test failure\nname=%s\n  actual=%q\nexpected=%q", g.Name, changed, g.Expected)
+		}
+	}
+}
@@ -3,10 +3,11 @@ package scaling
 import (
 	"fmt"
 	"math"
+	"math/big"
 
-	"github.com/golang/glog"
 	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
 	"github.com/justinsb/scaler/pkg/factors"
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -25,30 +26,97 @@ func computeValue(fn *scalingpolicy.ResourceScalingFunction, inputs factors.Snap
 		}
 
 		if !found {
-			glog.Warningf("value %q not found", fn.Input)
-			// We still continue, we just apply the base value
-		} else if !fn.Slope.IsZero() {
+			// Unknown inputs are rejected when the policy is loaded, so this is unexpected
+			return 0, fmt.Errorf("input %q not found", fn.Input)
+		}
+
+		if !fn.Slope.IsZero() {
 			input += shift
 
-			roundedInput := roundInput(fn, input)
+			roundedInput := roundInput(fn, toInternalScale(input))
 
-			increment := float64(fn.Slope.ScaledValue(internalScale)) * roundedInput
-			if fn.Per > 1 {
-				increment /= float64(fn.Per)
-			}
-			v += increment
+			// slope * input / per, computed exactly: the product overflows an int64 for inputs such as memory (in bytes).
+			// The slope, input & per are all in internalScale units, so the result is also in internalScale units.
+			increment := new(big.Int).Mul(big.NewInt(fn.Slope.ScaledValue(internalScale)), big.NewInt(roundedInput))
+			increment.Quo(increment, big.NewInt(perValue(fn)))
+			v += float64(increment.Int64())
 		}
 	}
 
 	return v, nil
 }
 
-// findSegment returns the segment of the rule, closest to the input value
-func findSegment(fn *scalingpolicy.ResourceScalingFunction, input float64) *scalingpolicy.ResourceScalingSegment {
+// toInternalScale converts an input value to internalScale units, so that we can use exact arithmetic
+func toInternalScale(input float64) int64 {
+	return int64(math.Floor(input*1000 + 0.5))
+}
+
+// perValue returns the divisor of the input, in internalScale units; per defaults to 1
+func perValue(fn *scalingpolicy.ResourceScalingFunction) int64 {
+	if fn.Per <= 0 {
+		return 1000
+	}
+	return fn.Per * 1000
+}
+
+// roundValue applies the output rounding of the rule to the value, if rounding is specified
+func roundValue(rule *scalingpolicy.ResourceScalingRule, v float64) float64 {
+	if rule.Rounding == nil || rule.Rounding.RoundTo.IsZero() {
+		return v
+	}
+
+	step := float64(rule.Rounding.RoundTo.ScaledValue(internalScale))
+	if step <= 0 {
+		return v
+	}
+
+	switch rule.Rounding.Mode {
+	case scalingpolicy.RoundDown:
+		return math.Floor(v/step) * step
+	case scalingpolicy.RoundNearest:
+		return math.Floor((v/step)+0.5) * step
+	default:
+		return math.Ceil(v/step) * step
+	}
+}
+
+// clampValue applies the min & max bounds of the rule to the value.
+// It returns the bounded value, and "min" or "max" if a bound was applied.
+// When min is not set, we still never compute a value below the smallest positive value of the resource.
+func clampValue(rule *scalingpolicy.ResourceScalingRule, v float64) (float64, string) {
+	if !rule.Max.IsZero() {
+		max := float64(rule.Max.ScaledValue(internalScale))
+		if v > max {
+			return max, "max"
+		}
+	}
+
+	min := minimumValue(rule.Resource)
+	if !rule.Min.IsZero() {
+		min = float64(rule.Min.ScaledValue(internalScale))
+	}
+	if v < min {
+		return min, "min"
+	}
+
+	return v, ""
+}
+
+// minimumValue returns the smallest positive value of the resource, in internalScale units:
+// 1m for cpu, which can be fractional, and 1 for other resources, which are whole numbers.
+func minimumValue(resourceName v1.ResourceName) float64 {
+	if resourceName == v1.ResourceCPU {
+		return 1
+	}
+	return 1000
+}
+
+// findSegment returns the segment of the rule, closest to the input value (in internalScale units)
+func findSegment(fn *scalingpolicy.ResourceScalingFunction, input int64) *scalingpolicy.ResourceScalingSegment {
 	var closest *scalingpolicy.ResourceScalingSegment
 	for i := range fn.Segments {
 		segment := &fn.Segments[i]
-		if float64(segment.At) > input {
+		if segment.At*1000 > input {
 			continue
 		}
 		if closest == nil || closest.At < segment.At {
@@ -58,11 +126,20 @@ func findSegment(fn *scalingpolicy.ResourceScalingFunction, input float64) *scal
 	return closest
 }
 
-// roundInput returns the input rounded based on the closest segment
-func roundInput(fn *scalingpolicy.ResourceScalingFunction, input float64) float64 {
+// roundInput returns the input rounded up to a multiple of every, based on the closest segment.
+// The input and the result are in internalScale units.
+func roundInput(fn *scalingpolicy.ResourceScalingFunction, input int64) int64 {
 	segment := findSegment(fn, input)
 	if segment == nil {
 		return input
 	}
-	return math.Ceil((input/float64(segment.Every))-0.001) * float64(segment.Every)
+	every := segment.Every * 1000
+	if every <= 0 {
+		return input
+	}
+	rounded := (input / every) * every
+	if rounded < input {
+		rounded += every
+	}
+	return rounded
 }
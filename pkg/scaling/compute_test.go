@@ -8,6 +8,7 @@ import (
 	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
 	"github.com/justinsb/scaler/pkg/debug"
 	"github.com/justinsb/scaler/pkg/factors/static"
+	"github.com/justinsb/scaler/pkg/http"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -132,6 +133,128 @@ func TestComputeResources(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "Max limits the computed value",
+			Inputs: map[string]float64{
+				"cores": 1000,
+			},
+			Policy: &scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceCPU,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "cores",
+										Base:  resource.MustParse("100m"),
+										Slope: resource.MustParse("10m"),
+									},
+									Max: resource.MustParse("4000m"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: &v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "container1",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceCPU: resource.MustParse("4000m"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Min limits a negative slope",
+			Inputs: map[string]float64{
+				"pods": 20,
+			},
+			Policy: &scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("-10Mi"),
+									},
+									Min: resource.MustParse("20Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: &v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "container1",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceMemory: resource.MustParse("20Mi"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Negative slope without min never reaches zero",
+			Inputs: map[string]float64{
+				"pods": 20,
+			},
+			Policy: &scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("-10Mi"),
+									},
+								},
+								{
+									Resource: v1.ResourceCPU,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100m"),
+										Slope: resource.MustParse("-10m"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: &v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "container1",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceMemory: resource.MustParse("1"),
+								v1.ResourceCPU:    resource.MustParse("1m"),
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			Name: "Mixed units work",
 			Inputs: map[string]float64{
@@ -169,7 +292,7 @@ func TestComputeResources(t *testing.T) {
 				},
 			},
 		},
-		/*{
+		{
 			Name: "Multiple rules on same resource",
 			Inputs: map[string]float64{
 				"nodes": 2,
@@ -214,7 +337,100 @@ func TestComputeResources(t *testing.T) {
 				},
 			},
 		},
-		*/
+		{
+			Name: "Max bounds the sum of multiple rules",
+			Inputs: map[string]float64{
+				"nodes": 2,
+				"pods":  4,
+			},
+			Policy: &scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("10Mi"),
+									},
+									Max: resource.MustParse("150Mi"),
+								},
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "nodes",
+										Slope: resource.MustParse("20Mi"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: &v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "container1",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								// 140Mi + 40Mi, bounded by the max of the first rule
+								v1.ResourceMemory: resource.MustParse("150Mi"),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Multiple rules on same resource with max combiner",
+			Inputs: map[string]float64{
+				"nodes": 2,
+				"pods":  4,
+			},
+			Policy: &scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("10Mi"),
+									},
+									Combiner: scalingpolicy.CombineMax,
+								},
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "nodes",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("30Mi"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: &v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "container1",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceMemory: resource.MustParse("160Mi"),
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			Name: "Multiple resources",
 			Inputs: map[string]float64{
@@ -317,6 +533,114 @@ func TestComputeResources(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "Memory input",
+			Inputs: map[string]float64{
+				"memory": 70 * 1024 * 1024 * 1024, // 70Gi, rounds up to 80Gi
+				"cores":  3.5,
+			},
+			Policy: &scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "memory",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("3Mi"),
+										Per:   1 << 30, // 1Gi
+										Segments: []scalingpolicy.ResourceScalingSegment{
+											{At: 64 << 30, Every: 16 << 30},
+										},
+									},
+								},
+								{
+									Resource: v1.ResourceCPU,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "cores",
+										Slope: resource.MustParse("10m"),
+										Per:   2,
+										Segments: []scalingpolicy.ResourceScalingSegment{
+											{Every: 2},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: &v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "container1",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceMemory: resource.MustParse("340Mi"), // 100Mi + (80Gi / 1Gi * 3Mi)
+								v1.ResourceCPU:    resource.MustParse("20m"),   // 4 cores / 2 * 10m
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "Rounding",
+			Inputs: map[string]float64{
+				"pods": 7,
+			},
+			Policy: &scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("1Mi"),
+									},
+									Rounding: &scalingpolicy.OutputRounding{
+										RoundTo: resource.MustParse("32Mi"),
+									},
+								},
+								{
+									Resource: v1.ResourceCPU,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100m"),
+										Slope: resource.MustParse("26m"),
+									},
+									Rounding: &scalingpolicy.OutputRounding{
+										RoundTo: resource.MustParse("100m"),
+										Mode:    scalingpolicy.RoundNearest,
+									},
+									Max: resource.MustParse("250m"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Expected: &v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: "container1",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceMemory: resource.MustParse("128Mi"), // 100Mi + (7 * 1Mi) = 107Mi, rounded up
+								v1.ResourceCPU:    resource.MustParse("250m"),  // 100m + (7 * 26m) = 282m, rounded to 300m, bounded by max
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, g := range grid {
@@ -376,10 +700,366 @@ func TestSegments(t *testing.T) {
 	}
 
 	for _, g := range grid {
-		actual := roundInput(fn, g.Input)
+		actual := float64(roundInput(fn, toInternalScale(g.Input))) / 1000
 		if actual != g.Expected {
 			t.Errorf("test failure\fn=%s\n  actual=%v\nexpected=%v", debug.Print(fn), actual, g.Expected)
 			continue
 		}
 	}
 }
+
+func TestClampValue(t *testing.T) {
+	grid := []struct {
+		Rule          scalingpolicy.ResourceScalingRule
+		Input         float64
+		Expected      float64
+		ExpectedBound string
+	}{
+		{Rule: scalingpolicy.ResourceScalingRule{Resource: v1.ResourceCPU}, Input: 100, Expected: 100},
+		// Without min, the value never drops below the smallest positive value of the resource
+		{Rule: scalingpolicy.ResourceScalingRule{Resource: v1.ResourceCPU}, Input: -100, Expected: 1, ExpectedBound: "min"},
+		{Rule: scalingpolicy.ResourceScalingRule{Resource: v1.ResourceCPU}, Input: 0, Expected: 1, ExpectedBound: "min"},
+		{Rule: scalingpolicy.ResourceScalingRule{Resource: v1.ResourceMemory}, Input: 500, Expected: 1000, ExpectedBound: "min"},
+		{Rule: scalingpolicy.ResourceScalingRule{Max: resource.MustParse("2")}, Input: 1000, Expected: 1000},
+		{Rule: scalingpolicy.ResourceScalingRule{Max: resource.MustParse("2")}, Input: 3000, Expected: 2000, ExpectedBound: "max"},
+		{Rule: scalingpolicy.ResourceScalingRule{Min: resource.MustParse("100m")}, Input: 50, Expected: 100, ExpectedBound: "min"},
+		{Rule: scalingpolicy.ResourceScalingRule{Min: resource.MustParse("100m")}, Input: 150, Expected: 150},
+	}
+
+	for _, g := range grid {
+		actual, bound := clampValue(&g.Rule, g.Input)
+		if actual != g.Expected || bound != g.ExpectedBound {
+			t.Errorf("test failure\nrule=%s\ninput=%v\n  actual=%v %q\nexpected=%v %q", debug.Print(g.Rule), g.Input, actual, bound, g.Expected, g.ExpectedBound)
+			continue
+		}
+	}
+}
+
+func TestRoundValue(t *testing.T) {
+	grid := []struct {
+		Rounding *scalingpolicy.OutputRounding
+		Input    float64
+		Expected float64
+	}{
+		{Rounding: nil, Input: 283, Expected: 283},
+		{Rounding: &scalingpolicy.OutputRounding{}, Input: 283, Expected: 283},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("100m")}, Input: 283, Expected: 300},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("100m")}, Input: 300, Expected: 300},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("100m"), Mode: scalingpolicy.RoundUp}, Input: 201, Expected: 300},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("100m"), Mode: scalingpolicy.RoundDown}, Input: 283, Expected: 200},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("100m"), Mode: scalingpolicy.RoundDown}, Input: 300, Expected: 300},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("100m"), Mode: scalingpolicy.RoundNearest}, Input: 249, Expected: 200},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("100m"), Mode: scalingpolicy.RoundNearest}, Input: 250, Expected: 300},
+		// 107Mi rounds to 128Mi
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("32Mi")}, Input: 107 * 1024 * 1024 * 1000, Expected: 128 * 1024 * 1024 * 1000},
+		{Rounding: &scalingpolicy.OutputRounding{RoundTo: resource.MustParse("32Mi"), Mode: scalingpolicy.RoundNearest}, Input: 107 * 1024 * 1024 * 1000, Expected: 96 * 1024 * 1024 * 1000},
+	}
+
+	for _, g := range grid {
+		rule := &scalingpolicy.ResourceScalingRule{Rounding: g.Rounding}
+		actual := roundValue(rule, g.Input)
+		if actual != g.Expected {
+			t.Errorf("test failure\nrounding=%s\ninput=%v\n  actual=%v\nexpected=%v", debug.Print(g.Rounding), g.Input, actual, g.Expected)
+			continue
+		}
+	}
+}
+
+func TestMultipleRulesKeepOwnDelays(t *testing.T) {
+	baseTime := time.Now()
+	clock := clock.NewFakeClock(baseTime)
+	inputs := map[string]float64{
+		"pods":  10,
+		"nodes": 4,
+	}
+	factors := static.NewStaticFactors(clock, inputs)
+
+	policy := &scalingpolicy.ScalingPolicy{
+		Spec: scalingpolicy.ScalingPolicySpec{
+			Containers: []scalingpolicy.ContainerScalingRule{
+				{
+					Name: "container1",
+					Resources: scalingpolicy.ResourceRequirements{
+						Requests: []scalingpolicy.ResourceScalingRule{
+							{
+								Resource: v1.ResourceMemory,
+								Function: scalingpolicy.ResourceScalingFunction{
+									Input: "pods",
+									Slope: resource.MustParse("10Mi"),
+									DelayScaleDown: &scalingpolicy.DelayScaling{
+										DelaySeconds: 60,
+									},
+								},
+							},
+							{
+								Resource: v1.ResourceMemory,
+								Function: scalingpolicy.ResourceScalingFunction{
+									Input: "nodes",
+									Slope: resource.MustParse("1Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	evaluator := NewScalingPolicyEvaluator(clock, policy)
+	actual := &v1.PodSpec{Containers: []v1.Container{{Name: "container1"}}}
+
+	// step computes the changes, and applies them unless the patch is deferred (e.g. by rate limiting)
+	step := func(expected string, apply bool) {
+		snapshot, err := factors.Snapshot()
+		if err != nil {
+			t.Fatalf("snapshot failed: %v", err)
+		}
+		evaluator.AddObservation(snapshot)
+		changes, err := evaluator.ComputeResources("", actual)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var changed *resource.Quantity
+		if changes != nil {
+			q := changes.Containers[0].Resources.Requests[v1.ResourceMemory]
+			changed = &q
+			if apply {
+				evaluator.RecordApplied(actual, changes)
+				actual = changes
+			}
+		}
+		if expected == "" {
+			if changed != nil {
+				t.Fatalf("unexpected change at %s: %s", clock.Now().Sub(baseTime), changed.String())
+			}
+			return
+		}
+		if changed == nil || changed.Cmp(resource.MustParse(expected)) != 0 {
+			t.Fatalf("unexpected value at %s: actual=%v expected=%s", clock.Now().Sub(baseTime), changed, expected)
+		}
+	}
+
+	step("104Mi", true)
+
+	// The nodes term scales down immediately, but the pods term delays its scale-down
+	inputs["pods"] = 5
+	inputs["nodes"] = 2
+	clock.Step(10 * time.Second)
+	step("102Mi", true)
+
+	// After the delay, the pods term scales down also
+	clock.Step(61 * time.Second)
+	step("52Mi", true)
+
+	// A scale-up which is not applied does not become the value the pods term holds ...
+	inputs["pods"] = 15
+	clock.Step(10 * time.Second)
+	step("152Mi", false)
+
+	// ... so when the input falls back, there is no change, rather than a delayed scale-down from the unapplied value
+	inputs["pods"] = 5
+	clock.Step(10 * time.Second)
+	step("", true)
+}
+
+func TestDelayScaleUp(t *testing.T) {
+	type step struct {
+		Step     time.Duration
+		Pods     float64
+		Expected string
+	}
+
+	grid := []struct {
+		Name  string
+		Delay *scalingpolicy.DelayScaling
+		// ExpectedThreshold is the expected scale-up threshold after the last step
+		ExpectedThreshold string
+		Steps             []step
+	}{
+		{
+			Name:  "No delay",
+			Delay: nil,
+			Steps: []step{
+				{Pods: 10, Expected: "100Mi"},
+				{Step: 10 * time.Second, Pods: 12, Expected: "120Mi"},
+			},
+		},
+		{
+			Name:  "Delay seconds",
+			Delay: &scalingpolicy.DelayScaling{DelaySeconds: 60},
+			Steps: []step{
+				// We don't delay setting the initial value
+				{Pods: 10, Expected: "100Mi"},
+				{Step: 10 * time.Second, Pods: 12},
+				{Step: 30 * time.Second, Pods: 12},
+				// The target has been 120Mi for the delay
+				{Step: 30 * time.Second, Pods: 12, Expected: "120Mi"},
+				// A brief increase is ignored
+				{Step: 10 * time.Second, Pods: 20},
+				{Step: 10 * time.Second, Pods: 12},
+				{Step: 60 * time.Second, Pods: 12},
+			},
+		},
+		{
+			Name:              "Max",
+			Delay:             &scalingpolicy.DelayScaling{Max: 2},
+			ExpectedThreshold: "110Mi",
+			Steps: []step{
+				{Pods: 10, Expected: "100Mi"},
+				// The threshold is at 9 pods (90Mi), so we don't scale up
+				{Step: 10 * time.Second, Pods: 11},
+				// The threshold is at 11 pods (110Mi), so we scale up to the target
+				{Step: 10 * time.Second, Pods: 13, Expected: "130Mi"},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		baseTime := time.Now()
+		clock := clock.NewFakeClock(baseTime)
+		inputs := map[string]float64{}
+		factors := static.NewStaticFactors(clock, inputs)
+
+		policy := &scalingpolicy.ScalingPolicy{
+			Spec: scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input:        "pods",
+										Slope:        resource.MustParse("10Mi"),
+										DelayScaleUp: g.Delay,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		evaluator := NewScalingPolicyEvaluator(clock, policy)
+		actual := &v1.PodSpec{Containers: []v1.Container{{Name: "container1"}}}
+
+		for i, step := range g.Steps {
+			clock.Step(step.Step)
+			inputs["pods"] = step.Pods
+
+			snapshot, err := factors.Snapshot()
+			if err != nil {
+				t.Fatalf("snapshot failed: %v", err)
+			}
+			evaluator.AddObservation(snapshot)
+			changes, err := evaluator.ComputeResources("", actual)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			changed := ""
+			if changes != nil {
+				q := changes.Containers[0].Resources.Requests[v1.ResourceMemory]
+				changed = q.String()
+				actual = changes
+			}
+			if changed != step.Expected {
+				t.Errorf("test failure\nname=%s\nstep=%d\n  actual=%q\nexpected=%q", g.Name, i, changed, step.Expected)
+				break
+			}
+		}
+
+		threshold := ""
+		info := evaluator.Query()
+		if q, found := info.ScaleUpThreshold.Containers[0].Resources.Requests[v1.ResourceMemory]; found {
+			threshold = q.String()
+		}
+		if threshold != g.ExpectedThreshold {
+			t.Errorf("test failure\nname=%s\n  actual threshold=%q\nexpected threshold=%q", g.Name, threshold, g.ExpectedThreshold)
+		}
+	}
+}
+
+func TestQueryReportsClamp(t *testing.T) {
+	grid := []struct {
+		Name     string
+		Pods     float64
+		Expected []http.ClampedInfo
+	}{
+		{
+			Name: "Within bounds",
+			Pods: 7,
+		},
+		{
+			Name: "Clamped by min",
+			Pods: 2,
+			Expected: []http.ClampedInfo{
+				{
+					Container: "container1",
+					Type:      "requests",
+					Resource:  v1.ResourceMemory,
+					Bound:     "min",
+					Computed:  resource.MustParse("120Mi"), // 100Mi + (2 * 10Mi)
+					Value:     resource.MustParse("150Mi"),
+				},
+			},
+		},
+		{
+			Name: "Clamped by max",
+			Pods: 20,
+			Expected: []http.ClampedInfo{
+				{
+					Container: "container1",
+					Type:      "requests",
+					Resource:  v1.ResourceMemory,
+					Bound:     "max",
+					Computed:  resource.MustParse("300Mi"), // 100Mi + (20 * 10Mi)
+					Value:     resource.MustParse("200Mi"),
+				},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		clock := clock.NewFakeClock(time.Now())
+		factors := static.NewStaticFactors(clock, map[string]float64{"pods": g.Pods})
+		snapshot, err := factors.Snapshot()
+		if err != nil {
+			t.Fatalf("snapshot failed: %v", err)
+		}
+
+		policy := &scalingpolicy.ScalingPolicy{
+			Spec: scalingpolicy.ScalingPolicySpec{
+				Containers: []scalingpolicy.ContainerScalingRule{
+					{
+						Name: "container1",
+						Resources: scalingpolicy.ResourceRequirements{
+							Requests: []scalingpolicy.ResourceScalingRule{
+								{
+									Resource: v1.ResourceMemory,
+									Function: scalingpolicy.ResourceScalingFunction{
+										Input: "pods",
+										Base:  resource.MustParse("100Mi"),
+										Slope: resource.MustParse("10Mi"),
+									},
+									Min: resource.MustParse("150Mi"),
+									Max: resource.MustParse("200Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		evaluator := NewScalingPolicyEvaluator(clock, policy)
+		evaluator.AddObservation(snapshot)
+
+		actual := evaluator.Query().Clamped
+		if !equality.Semantic.DeepEqual(actual, g.Expected) {
+			t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, debug.Print(actual), debug.Print(g.Expected))
+		}
+	}
+}
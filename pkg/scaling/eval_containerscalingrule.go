@@ -2,10 +2,13 @@ package scaling
 
 import (
 	"sync"
+	"time"
 
 	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
 	"github.com/justinsb/scaler/pkg/factors"
+	"github.com/justinsb/scaler/pkg/http"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/clock"
 )
 
@@ -14,16 +17,16 @@ type containerScalingRuleEvaluator struct {
 	clock clock.Clock
 	rule  *scalingpolicy.ContainerScalingRule
 
-	limits   map[v1.ResourceName]*resourceScalingRuleEvaluator
-	requests map[v1.ResourceName]*resourceScalingRuleEvaluator
+	limits   map[v1.ResourceName]*resourceRulesEvaluator
+	requests map[v1.ResourceName]*resourceRulesEvaluator
 }
 
 func newContainerScalingRuleEvaluator(rule *scalingpolicy.ContainerScalingRule, clock clock.Clock) *containerScalingRuleEvaluator {
 	e := &containerScalingRuleEvaluator{
 		rule:     rule,
 		clock:    clock,
-		limits:   make(map[v1.ResourceName]*resourceScalingRuleEvaluator),
-		requests: make(map[v1.ResourceName]*resourceScalingRuleEvaluator),
+		limits:   make(map[v1.ResourceName]*resourceRulesEvaluator),
+		requests: make(map[v1.ResourceName]*resourceRulesEvaluator),
 	}
 
 	e.updatePolicy(rule)
@@ -35,29 +38,45 @@ func (e *containerScalingRuleEvaluator) updatePolicy(rule *scalingpolicy.Contain
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
+	e.rule = rule
 	e.updateResourceMap(rule.Resources.Limits, e.limits)
 	e.updateResourceMap(rule.Resources.Requests, e.requests)
 }
 
-func (e *containerScalingRuleEvaluator) updateResourceMap(rules []scalingpolicy.ResourceScalingRule, evaluators map[v1.ResourceName]*resourceScalingRuleEvaluator) {
-	marked := make(map[v1.ResourceName]bool)
+func (e *containerScalingRuleEvaluator) updateResourceMap(rules []scalingpolicy.ResourceScalingRule, evaluators map[v1.ResourceName]*resourceRulesEvaluator) {
+	// We group the rules by resource; multiple rules for the same resource are combined
+	grouped := make(map[v1.ResourceName][]*scalingpolicy.ResourceScalingRule)
 	for i := range rules {
 		r := &rules[i]
-		re := evaluators[r.Resource]
+		grouped[r.Resource] = append(grouped[r.Resource], r)
+	}
+
+	for k, resourceRules := range grouped {
+		re := evaluators[k]
 		if re == nil {
-			re = &resourceScalingRuleEvaluator{clock: e.clock}
-			evaluators[r.Resource] = re
+			re = newResourceRulesEvaluator(e.clock)
+			evaluators[k] = re
 		}
-		re.updatePolicy(r)
-		marked[r.Resource] = true
+		re.updatePolicy(resourceRules)
 	}
 	for k := range evaluators {
-		if !marked[k] {
+		if grouped[k] == nil {
 			delete(evaluators, k)
 		}
 	}
 }
 
+// containerType returns the list of containers in the PodSpec to which the rule applies
+func (e *containerScalingRuleEvaluator) containerType() scalingpolicy.ContainerType {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.rule.ContainerType == "" {
+		return scalingpolicy.ContainerTypeContainers
+	}
+	return e.rule.ContainerType
+}
+
 // ComputeResources computes a list of resource quantities based on the input state and the specified policy
 // It returns a partial PodSpec with the resources we should apply
 func (e *containerScalingRuleEvaluator) computeResources(parentPath string, currentParent *v1.Container) (*v1.Container, error) {
@@ -106,6 +125,30 @@ func (e *containerScalingRuleEvaluator) computeResources(parentPath string, curr
 	return container, nil
 }
 
+// recordApplied is called when we have applied the changes to the container, which previously had the actual resources
+func (e *containerScalingRuleEvaluator) recordApplied(now time.Time, actual *v1.Container, applied *v1.Container) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if actual == nil {
+		actual = &v1.Container{}
+	}
+	recordAppliedResources(now, actual.Resources.Limits, applied.Resources.Limits, e.limits)
+	recordAppliedResources(now, actual.Resources.Requests, applied.Resources.Requests, e.requests)
+}
+
+// recordAppliedResources records the direction of each applied change on the evaluator for the resource
+func recordAppliedResources(now time.Time, actual v1.ResourceList, applied v1.ResourceList, evaluators map[v1.ResourceName]*resourceRulesEvaluator) {
+	for k, q := range applied {
+		re := evaluators[k]
+		if re == nil {
+			continue
+		}
+		current := actual[k]
+		re.recordApplied(now, q.Cmp(current))
+	}
+}
+
 // AddObservation is called whenever we observe input values
 func (e *containerScalingRuleEvaluator) addObservation(inputs factors.Snapshot) {
 	e.mutex.Lock()
@@ -118,3 +161,62 @@ func (e *containerScalingRuleEvaluator) addObservation(inputs factors.Snapshot)
 		re.addObservation(inputs)
 	}
 }
+
+// query returns the latest target, scale-down threshold and scale-up threshold values for the container, for reporting.
+// It also returns the resources where the target is currently limited by min or max.
+func (e *containerScalingRuleEvaluator) query() (*v1.Container, *v1.Container, *v1.Container, []http.ClampedInfo) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	target := &v1.Container{Name: e.rule.Name}
+	scaleDownThreshold := &v1.Container{Name: e.rule.Name}
+	scaleUpThreshold := &v1.Container{Name: e.rule.Name}
+	var clamped []http.ClampedInfo
+
+	for k, re := range e.limits {
+		info, terms := re.query()
+		addResource(&target.Resources.Limits, k, info.target)
+		addResource(&scaleDownThreshold.Resources.Limits, k, info.scaleDownThreshold)
+		addResource(&scaleUpThreshold.Resources.Limits, k, info.scaleUpThreshold)
+		clamped = appendClampedInfo(clamped, e.rule.Name, "limits", k, terms)
+	}
+
+	for k, re := range e.requests {
+		info, terms := re.query()
+		addResource(&target.Resources.Requests, k, info.target)
+		addResource(&scaleDownThreshold.Resources.Requests, k, info.scaleDownThreshold)
+		addResource(&scaleUpThreshold.Resources.Requests, k, info.scaleUpThreshold)
+		clamped = appendClampedInfo(clamped, e.rule.Name, "requests", k, terms)
+	}
+
+	return target, scaleDownThreshold, scaleUpThreshold, clamped
+}
+
+// appendClampedInfo appends the information for each term where the target is limited by a bound
+func appendClampedInfo(clamped []http.ClampedInfo, container string, resourceType string, k v1.ResourceName, terms []*ruleInfo) []http.ClampedInfo {
+	for _, info := range terms {
+		if info.clampedBy == "" {
+			continue
+		}
+		clamped = append(clamped, http.ClampedInfo{
+			Container: container,
+			Type:      resourceType,
+			Resource:  k,
+			Bound:     info.clampedBy,
+			Computed:  *info.unclampedTarget,
+			Value:     *info.target,
+		})
+	}
+	return clamped
+}
+
+// addResource sets the value of the resource in the ResourceList, if the value is not nil
+func addResource(resources *v1.ResourceList, k v1.ResourceName, q *resource.Quantity) {
+	if q == nil {
+		return
+	}
+	if *resources == nil {
+		*resources = make(v1.ResourceList)
+	}
+	(*resources)[k] = *q
+}
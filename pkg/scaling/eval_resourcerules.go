@@ -0,0 +1,220 @@
+package scaling
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/factors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// resourceRulesEvaluator holds the state for evaluation of all the ResourceScalingRules for a single resource
+// Each rule is a term which is evaluated independently, and the values are then combined.
+type resourceRulesEvaluator struct {
+	mutex sync.Mutex
+	clock clock.Clock
+
+	combiner scalingpolicy.ResourceCombiner
+
+	terms []*resourceScalingRuleEvaluator
+
+	// held holds the value each term would have applied if it were the only rule, as of the last applied change.
+	// This lets each term apply its own delays, when there are multiple terms.
+	held []*resource.Quantity
+
+	// proposed holds the value each term computed in the latest computeResources, if it differs from held.
+	// It only replaces held when the change is applied, so a deferred, rate-limited or dry-run change is recomputed
+	// against the applied values, in the same way that a single term computes against the actual value.
+	proposed []*resource.Quantity
+}
+
+func newResourceRulesEvaluator(clock clock.Clock) *resourceRulesEvaluator {
+	return &resourceRulesEvaluator{clock: clock}
+}
+
+// updatePolicy updates for a change in the rules for the resource
+func (e *resourceRulesEvaluator) updatePolicy(rules []*scalingpolicy.ResourceScalingRule) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// The combiner can be declared on any of the rules; validation rejects rules that declare different combiners
+	e.combiner = scalingpolicy.CombineSum
+	for _, rule := range rules {
+		if rule.Combiner != "" {
+			e.combiner = rule.Combiner
+			break
+		}
+	}
+
+	for len(e.terms) < len(rules) {
+		e.terms = append(e.terms, &resourceScalingRuleEvaluator{clock: e.clock})
+		e.held = append(e.held, nil)
+		e.proposed = append(e.proposed, nil)
+	}
+	e.terms = e.terms[:len(rules)]
+	e.held = e.held[:len(rules)]
+	e.proposed = e.proposed[:len(rules)]
+
+	for i, rule := range rules {
+		e.terms[i].updatePolicy(rule)
+	}
+}
+
+// combine combines the values of the terms, using the combiner
+func (e *resourceRulesEvaluator) combine(values []float64) float64 {
+	var v float64
+	for i, x := range values {
+		switch e.combiner {
+		case scalingpolicy.CombineMax:
+			if i == 0 || x > v {
+				v = x
+			}
+		case scalingpolicy.CombineMin:
+			if i == 0 || x < v {
+				v = x
+			}
+		default:
+			v += x
+		}
+	}
+	return v
+}
+
+// computeResources computes the new resource value we should use, or returns nil if no change is needed
+func (e *resourceRulesEvaluator) computeResources(parentPath string, current resource.Quantity) (*resource.Quantity, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.terms) == 0 {
+		return nil, nil
+	}
+
+	// With a single term, the term applies its delays directly against the current value
+	if len(e.terms) == 1 {
+		return e.terms[0].computeResources(parentPath, current)
+	}
+
+	var values []float64
+	for i, term := range e.terms {
+		termPath := fmt.Sprintf("%s[%d]", parentPath, i)
+
+		if !term.hasTarget() {
+			glog.Infof("No data points for %s, won't consider scaling %s", termPath, parentPath)
+			return nil, nil
+		}
+
+		var termCurrent resource.Quantity
+		if e.held[i] != nil {
+			termCurrent = *e.held[i]
+		}
+		r, err := term.computeResources(termPath, termCurrent)
+		if err != nil {
+			return nil, err
+		}
+		e.proposed[i] = r
+
+		v := termCurrent
+		if r != nil {
+			v = *r
+		}
+		values = append(values, float64(v.ScaledValue(internalScale)))
+	}
+
+	v := e.clampCombined(e.combine(values))
+	if v == float64(current.ScaledValue(internalScale)) {
+		return nil, nil
+	}
+	glog.Infof("Will scale to combined value of %d rules for %s", len(e.terms), parentPath)
+	return e.terms[0].toResourceQuantity(v), nil
+}
+
+// clampCombined applies the min & max bounds of every rule to the combined value, so that the bounds hold for
+// the resource and not only for each term (a sum of terms could otherwise exceed the max of every rule).
+func (e *resourceRulesEvaluator) clampCombined(v float64) float64 {
+	for _, term := range e.terms {
+		v, _ = clampValue(term.policy, v)
+	}
+	return v
+}
+
+// recordApplied is called when we have applied the resource, which may be unchanged (direction 0) when other
+// resources of the container changed.  The change applies to the combined value, so we record it on every term,
+// and the values the terms proposed are now held.
+func (e *resourceRulesEvaluator) recordApplied(now time.Time, direction int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i, term := range e.terms {
+		term.recordApplied(now, direction)
+		if e.proposed[i] != nil {
+			e.held[i] = e.proposed[i]
+			e.proposed[i] = nil
+		}
+	}
+}
+
+// addObservation is called whenever we observe input values
+func (e *resourceRulesEvaluator) addObservation(inputs factors.Snapshot) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, term := range e.terms {
+		term.addObservation(inputs)
+	}
+}
+
+// query returns the latest computed values, combined across the terms, for reporting.
+// It also returns the information for each term, so that we can report bounds that are in force.
+func (e *resourceRulesEvaluator) query() (*ruleInfo, []*ruleInfo) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	info := &ruleInfo{}
+	var terms []*ruleInfo
+	for _, term := range e.terms {
+		terms = append(terms, term.query())
+	}
+
+	if len(terms) == 1 {
+		return terms[0], terms
+	}
+
+	var targets []float64
+	var scaleDownThresholds []float64
+	var scaleUpThresholds []float64
+	hasScaleDownThreshold := false
+	hasScaleUpThreshold := false
+	for _, t := range terms {
+		if t.target == nil {
+			return info, terms
+		}
+		targets = append(targets, float64(t.target.ScaledValue(internalScale)))
+		if t.scaleDownThreshold != nil {
+			hasScaleDownThreshold = true
+			scaleDownThresholds = append(scaleDownThresholds, float64(t.scaleDownThreshold.ScaledValue(internalScale)))
+		} else {
+			scaleDownThresholds = append(scaleDownThresholds, float64(t.target.ScaledValue(internalScale)))
+		}
+		if t.scaleUpThreshold != nil {
+			hasScaleUpThreshold = true
+			scaleUpThresholds = append(scaleUpThresholds, float64(t.scaleUpThreshold.ScaledValue(internalScale)))
+		} else {
+			scaleUpThresholds = append(scaleUpThresholds, float64(t.target.ScaledValue(internalScale)))
+		}
+	}
+
+	if len(targets) != 0 {
+		info.target = e.terms[0].toResourceQuantity(e.clampCombined(e.combine(targets)))
+	}
+	if hasScaleDownThreshold {
+		info.scaleDownThreshold = e.terms[0].toResourceQuantity(e.clampCombined(e.combine(scaleDownThresholds)))
+	}
+	if hasScaleUpThreshold {
+		info.scaleUpThreshold = e.terms[0].toResourceQuantity(e.clampCombined(e.combine(scaleUpThresholds)))
+	}
+	return info, terms
+}
@@ -20,14 +20,46 @@ type resourceScalingRuleEvaluator struct {
 	// rule holds a copy of the current rule
 	policy *scalingpolicy.ResourceScalingRule
 
-	// target holds the window of "raw" target values
-	target windowValues
+	// target holds the window of "raw" target values; these are smoothed if the function specifies smoothing
+	target timeSeries
+
+	// targetSmoother computes the smoothed target values
+	targetSmoother smoother
 
 	// scaleDownThresholds holds the scale-down threshold values (computed by adding some padding to the input resource value)
-	scaleDownThresholds windowValues
+	scaleDownThresholds timeSeries
+
+	// scaleDownThresholdSmoother computes the smoothed scale-down threshold values
+	scaleDownThresholdSmoother smoother
+
+	// scaleUpThresholds holds the scale-up threshold values (computed by subtracting some padding from the input resource value)
+	scaleUpThresholds timeSeries
+
+	// scaleUpThresholdSmoother computes the smoothed scale-up threshold values
+	scaleUpThresholdSmoother smoother
 
-	// lastScaleDown is the time of the last scale-down, to prevent rapid repeated scale-down
+	// lastScaleDown and lastScaleUp are the times at which we last applied a change to the resource;
+	// the delays are measured from the last applied change, to prevent rapid repeated changes
 	lastScaleDown time.Time
+	lastScaleUp   time.Time
+
+	// clampedBy is "min" or "max" if the latest target value was limited by that bound
+	clampedBy string
+
+	// unclampedTarget is the latest (rounded) target value before the min & max bounds were applied
+	unclampedTarget float64
+}
+
+// ruleInfo holds the latest values computed by a resourceScalingRuleEvaluator, for reporting
+type ruleInfo struct {
+	target             *resource.Quantity
+	scaleDownThreshold *resource.Quantity
+	scaleUpThreshold   *resource.Quantity
+
+	// clampedBy is "min" or "max" if the target was limited by that bound
+	clampedBy string
+	// unclampedTarget is the target value before the bound was applied
+	unclampedTarget *resource.Quantity
 }
 
 // updatePolicy updates for a change in the resource scaling policy API
@@ -44,15 +76,27 @@ func (e *resourceScalingRuleEvaluator) updatePolicy(policy *scalingpolicy.Resour
 		scaleDownAtWindowRetention = time.Duration(policy.Function.DelayScaleDown.DelaySeconds) * time.Second
 	}
 
+	scaleUpAtWindowRetention := time.Duration(0)
+	if policy.Function.DelayScaleUp != nil {
+		scaleUpAtWindowRetention = time.Duration(policy.Function.DelayScaleUp.DelaySeconds) * time.Second
+	}
+
 	maxRetention := time.Duration(0)
 	if maxRetention < scaleDownAtWindowRetention {
 		maxRetention = scaleDownAtWindowRetention
 	}
+	if maxRetention < scaleUpAtWindowRetention {
+		maxRetention = scaleUpAtWindowRetention
+	}
 
 	// We retain the values for the biggest retention, because e.g. the scale-down-after-delay
 	// uses the delay from the scale-down policy but the max value from the unshifted target values
 	e.target.Reset(e.clock, maxRetention)
 	e.scaleDownThresholds.Reset(e.clock, maxRetention)
+	e.scaleUpThresholds.Reset(e.clock, maxRetention)
+	e.targetSmoother.reset(e.clock, policy.Function.Smoothing)
+	e.scaleDownThresholdSmoother.reset(e.clock, policy.Function.Smoothing)
+	e.scaleUpThresholdSmoother.reset(e.clock, policy.Function.Smoothing)
 
 	e.policy = policy.DeepCopy()
 }
@@ -72,12 +116,8 @@ func (e *resourceScalingRuleEvaluator) computeResources(parentPath string, curre
 
 	currentV := float64(current.ScaledValue(internalScale))
 
-	// TODO: We should have the same delay options for scale up
-
-	// We scale up to the current value, with no delay - i.e. whenever the current value is LTE our value
 	if currentV < latestStats.LatestValue {
-		glog.Infof("Will scale up to target value for %s", parentPath)
-		return e.toResourceQuantity(latestStats.LatestValue), nil
+		return e.computeScaleUp(parentPath, now, currentV, latestStats), nil
 	}
 
 	// We scale down immediately to the current value when we exceed the shiftedValue
@@ -104,8 +144,8 @@ func (e *resourceScalingRuleEvaluator) computeResources(parentPath string, curre
 	// every e.g. 10 minutes.
 	if e.policy.Function.DelayScaleDown != nil && e.policy.Function.DelayScaleDown.DelaySeconds != 0 {
 		delay := time.Second * time.Duration(e.policy.Function.DelayScaleDown.DelaySeconds)
-		// Don't scale more often than delay
-		if now.Sub(e.lastScaleDown) > delay {
+		// Don't scale down within delay of the last applied change
+		if now.Sub(e.lastChange()) > delay {
 			// Ensure we have enough history
 			if now.Sub(e.target.Start) > delay {
 				windowStats := e.target.stats(now, delay)
@@ -130,6 +170,45 @@ func (e *resourceScalingRuleEvaluator) computeResources(parentPath string, curre
 	return nil, nil
 }
 
+// computeScaleUp returns the value we should scale up to, or nil if we should delay scaling up.
+// It is called with the mutex held, when the current value is less than the target value.
+func (e *resourceScalingRuleEvaluator) computeScaleUp(parentPath string, now time.Time, currentV float64, latestStats windowStats) *resource.Quantity {
+	delay := e.policy.Function.DelayScaleUp
+
+	// If there is no DelayScaleUp, we scale up immediately, to the target value.
+	// We also don't delay setting a value where none is set.
+	if delay == nil || (delay.DelaySeconds == 0 && delay.Max == 0) || currentV == 0 {
+		glog.Infof("Will scale up to target value for %s", parentPath)
+		return e.toResourceQuantity(latestStats.LatestValue)
+	}
+
+	// We scale up immediately when the current value is under the threshold, i.e. we are "too far away"
+	if delay.Max != 0 {
+		latestScaleUpStats := e.scaleUpThresholds.stats(now, time.Duration(0))
+		if latestScaleUpStats.HasLatest && currentV < latestScaleUpStats.LatestValue {
+			glog.Infof("Current value broke threshold for scale-up; scaling up %s", parentPath)
+			return e.toResourceQuantity(latestStats.LatestValue)
+		}
+	}
+
+	// We also scale up once the target has been higher than the current value for the whole delay,
+	// to the minimum target value we've observed in that window (i.e. the value which has held for the delay).
+	if delay.DelaySeconds != 0 {
+		delay := time.Second * time.Duration(delay.DelaySeconds)
+		// Ensure we have enough history, and don't scale up within delay of the last applied change
+		if now.Sub(e.target.Start) > delay && now.Sub(e.lastChange()) > delay {
+			windowStats := e.target.stats(now, delay)
+			if windowStats.N != 0 && currentV < windowStats.Min {
+				glog.Infof("Scale-up time-window exceeded, scaling up %s", parentPath)
+				return e.toResourceQuantity(windowStats.Min)
+			}
+		}
+	}
+
+	glog.Infof("Delaying scale-up of %s", parentPath)
+	return nil
+}
+
 // addObservation is called whenever we observe input values
 func (e *resourceScalingRuleEvaluator) addObservation(inputs factors.Snapshot) {
 	e.mutex.Lock()
@@ -140,7 +219,12 @@ func (e *resourceScalingRuleEvaluator) addObservation(inputs factors.Snapshot) {
 		if err != nil {
 			glog.Warningf("error computing shifted value: %v", err)
 		} else {
-			e.target.addObservation(inputs.Timestamp(), v)
+			v = e.targetSmoother.addObservation(inputs.Timestamp(), v)
+			v = roundValue(e.policy, v)
+			clamped, clampedBy := clampValue(e.policy, v)
+			e.target.addObservation(inputs.Timestamp(), clamped)
+			e.clampedBy = clampedBy
+			e.unclampedTarget = v
 		}
 	}
 
@@ -150,10 +234,86 @@ func (e *resourceScalingRuleEvaluator) addObservation(inputs factors.Snapshot) {
 			if err != nil {
 				glog.Warningf("error computing scale-down threshold value: %v", err)
 			} else {
+				v = e.scaleDownThresholdSmoother.addObservation(inputs.Timestamp(), v)
+				v, _ = clampValue(e.policy, roundValue(e.policy, v))
 				e.scaleDownThresholds.addObservation(inputs.Timestamp(), v)
 			}
 		}
 	}
+
+	if e.policy.Function.DelayScaleUp != nil {
+		if e.policy.Function.DelayScaleUp.Max != 0 {
+			v, err := computeValue(&e.policy.Function, inputs, -e.policy.Function.DelayScaleUp.Max)
+			if err != nil {
+				glog.Warningf("error computing scale-up threshold value: %v", err)
+			} else {
+				v = e.scaleUpThresholdSmoother.addObservation(inputs.Timestamp(), v)
+				v, _ = clampValue(e.policy, roundValue(e.policy, v))
+				e.scaleUpThresholds.addObservation(inputs.Timestamp(), v)
+			}
+		}
+	}
+}
+
+// lastChange returns the time at which we last applied a change to the resource, in either direction.
+// It is called with the mutex held.
+func (e *resourceScalingRuleEvaluator) lastChange() time.Time {
+	if e.lastScaleUp.After(e.lastScaleDown) {
+		return e.lastScaleUp
+	}
+	return e.lastScaleDown
+}
+
+// recordApplied is called when we have applied a change to the resource; direction is negative
+// for a scale-down and positive for a scale-up.
+func (e *resourceScalingRuleEvaluator) recordApplied(now time.Time, direction int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if direction < 0 {
+		e.lastScaleDown = now
+	} else if direction > 0 {
+		e.lastScaleUp = now
+	}
+}
+
+// hasTarget returns true if we have computed a target value
+func (e *resourceScalingRuleEvaluator) hasTarget() bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.target.stats(e.clock.Now(), time.Duration(0)).HasLatest
+}
+
+// query returns the latest computed values, for reporting
+func (e *resourceScalingRuleEvaluator) query() *ruleInfo {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := e.clock.Now()
+
+	info := &ruleInfo{}
+
+	latestStats := e.target.stats(now, time.Duration(0))
+	if latestStats.HasLatest {
+		info.target = e.toResourceQuantity(latestStats.LatestValue)
+		if e.clampedBy != "" {
+			info.clampedBy = e.clampedBy
+			info.unclampedTarget = e.toResourceQuantity(e.unclampedTarget)
+		}
+	}
+
+	latestScaleDownStats := e.scaleDownThresholds.stats(now, time.Duration(0))
+	if latestScaleDownStats.HasLatest {
+		info.scaleDownThreshold = e.toResourceQuantity(latestScaleDownStats.LatestValue)
+	}
+
+	latestScaleUpStats := e.scaleUpThresholds.stats(now, time.Duration(0))
+	if latestScaleUpStats.HasLatest {
+		info.scaleUpThreshold = e.toResourceQuantity(latestScaleUpStats.LatestValue)
+	}
+
+	return info
 }
 
 func (e *resourceScalingRuleEvaluator) toResourceQuantity(v float64) *resource.Quantity {
@@ -162,5 +322,8 @@ func (e *resourceScalingRuleEvaluator) toResourceQuantity(v float64) *resource.Q
 	if q.Format == "" {
 		q.Format = e.policy.Function.Slope.Format
 	}
+	if q.Format == "" && e.policy.Rounding != nil {
+		q.Format = e.policy.Rounding.RoundTo.Format
+	}
 	return q
 }
@@ -1,10 +1,12 @@
 package scaling
 
 import (
+	"sort"
 	"sync"
 
 	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
 	"github.com/justinsb/scaler/pkg/factors"
+	"github.com/justinsb/scaler/pkg/http"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 )
@@ -33,6 +35,8 @@ func (e *ScalingPolicyEvaluator) UpdatePolicy(rule *scalingpolicy.ScalingPolicy)
 	e.mutex.Lock()
 	defer e.mutex.Unlock()
 
+	e.rule = rule
+
 	marked := make(map[string]bool)
 	for i := range rule.Spec.Containers {
 		r := &rule.Spec.Containers[i]
@@ -52,33 +56,95 @@ func (e *ScalingPolicyEvaluator) UpdatePolicy(rule *scalingpolicy.ScalingPolicy)
 	}
 }
 
-// ComputeResources computes a list of resource quantities based on the input state and the specified policy
-// It returns a partial PodSpec with the resources we should apply
+// ComputeResources computes a list of resource quantities based on the input state and the specified policy.
+// It returns a partial PodSpec with the containers we should change, holding the computed values merged with the
+// current resources of each container (with requests kept within limits), so that the changes can be applied as one patch.
+// The containers & initContainers are each in the order of the current PodSpec, followed by any not in the PodSpec, by name.
 func (e *ScalingPolicyEvaluator) ComputeResources(parentPath string, currentPod *v1.PodSpec) (*v1.PodSpec, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
 	pod := &v1.PodSpec{}
+	for _, containerType := range scalingpolicy.ContainerTypes {
+		currentContainers := *containerType.ContainersIn(currentPod)
+		changes := containerType.ContainersIn(pod)
 
-	for k, ce := range e.containers {
-		var current *v1.Container
-		for i := range currentPod.Containers {
-			if currentPod.Containers[i].Name == k {
-				current = &currentPod.Containers[i]
+		for _, k := range e.containerNames(containerType, currentContainers) {
+			current := findContainer(currentContainers, k)
+
+			containerPath := parentPath + "[" + k + "]"
+			c, err := e.containers[k].computeResources(containerPath, current)
+			if err != nil {
+				return nil, err
+			}
+			if c == nil {
+				continue
+			}
+			if c = reconcileContainer(containerPath, current, c, e.rule.Spec.RequestLimitPolicy); c != nil {
+				*changes = append(*changes, *c)
 			}
 		}
+	}
+
+	if len(pod.Containers) == 0 && len(pod.InitContainers) == 0 {
+		return nil, nil
+	}
+
+	return pod, nil
+}
 
-		c, err := ce.computeResources(parentPath+"["+k+"]", current)
-		if err != nil {
-			return nil, err
+// containerNames returns the names of the containers with rules of the container type, in the order of the
+// current containers, followed by any containers that are not in the current containers, sorted by name.
+func (e *ScalingPolicyEvaluator) containerNames(containerType scalingpolicy.ContainerType, currentContainers []v1.Container) []string {
+	var names []string
+	found := make(map[string]bool)
+	for i := range currentContainers {
+		name := currentContainers[i].Name
+		ce := e.containers[name]
+		if ce != nil && ce.containerType() == containerType && !found[name] {
+			names = append(names, name)
+			found[name] = true
 		}
-		if c != nil {
-			pod.Containers = append(pod.Containers, *c)
+	}
+	var missing []string
+	for k, ce := range e.containers {
+		if ce.containerType() == containerType && !found[k] {
+			missing = append(missing, k)
 		}
 	}
+	sort.Strings(missing)
+	return append(names, missing...)
+}
 
-	if len(pod.Containers) == 0 {
-		return nil, nil
+// findContainer returns the container with the specified name, or nil if not found
+func findContainer(containers []v1.Container, name string) *v1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
 	}
+	return nil
+}
 
-	return pod, nil
+// RecordApplied is called when we have successfully applied changes (as returned by ComputeResources) to a target,
+// which previously had the actual resources.  The delays are measured from the last applied change.
+func (e *ScalingPolicyEvaluator) RecordApplied(actual *v1.PodSpec, applied *v1.PodSpec) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := e.clock.Now()
+	for _, containerType := range scalingpolicy.ContainerTypes {
+		appliedContainers := *containerType.ContainersIn(applied)
+		for i := range appliedContainers {
+			c := &appliedContainers[i]
+			ce := e.containers[c.Name]
+			if ce == nil || ce.containerType() != containerType {
+				continue
+			}
+
+			ce.recordApplied(now, findContainer(*containerType.ContainersIn(actual), c.Name), c)
+		}
+	}
 }
 
 // AddObservation is called whenever we observe input values
@@ -90,3 +156,35 @@ func (e *ScalingPolicyEvaluator) AddObservation(inputs factors.Snapshot) {
 		ce.addObservation(inputs)
 	}
 }
+
+// Query returns the latest computed values, for reporting e.g. via the /statz endpoint
+func (e *ScalingPolicyEvaluator) Query() *http.Info {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	var names []string
+	for k := range e.containers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	info := &http.Info{
+		LatestTarget:       &v1.PodSpec{},
+		ScaleDownThreshold: &v1.PodSpec{},
+		ScaleUpThreshold:   &v1.PodSpec{},
+	}
+	for _, k := range names {
+		ce := e.containers[k]
+		containerType := ce.containerType()
+		target, scaleDownThreshold, scaleUpThreshold, clamped := ce.query()
+		latestTargets := containerType.ContainersIn(info.LatestTarget)
+		*latestTargets = append(*latestTargets, *target)
+		scaleDownThresholds := containerType.ContainersIn(info.ScaleDownThreshold)
+		*scaleDownThresholds = append(*scaleDownThresholds, *scaleDownThreshold)
+		scaleUpThresholds := containerType.ContainersIn(info.ScaleUpThreshold)
+		*scaleUpThresholds = append(*scaleUpThresholds, *scaleUpThreshold)
+		info.Clamped = append(info.Clamped, clamped...)
+	}
+
+	return info
+}
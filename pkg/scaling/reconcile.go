@@ -0,0 +1,55 @@
+package scaling
+
+import (
+	"github.com/golang/glog"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// reconcileContainer merges the computed values into the current resources of the container, and ensures that
+// no request exceeds its limit, which the apiserver would reject.  It returns the container with the full merged
+// resources, or nil if they are unchanged.
+func reconcileContainer(parentPath string, current *v1.Container, computed *v1.Container, requestLimitPolicy scalingpolicy.RequestLimitPolicy) *v1.Container {
+	merged := &v1.Container{Name: computed.Name}
+	if current != nil {
+		current.Resources.DeepCopyInto(&merged.Resources)
+	}
+	mergeResources(&merged.Resources.Limits, computed.Resources.Limits)
+	mergeResources(&merged.Resources.Requests, computed.Resources.Requests)
+
+	for k, request := range merged.Resources.Requests {
+		limit, found := merged.Resources.Limits[k]
+		if !found || request.Cmp(limit) <= 0 {
+			continue
+		}
+
+		switch requestLimitPolicy {
+		case scalingpolicy.ClampRequests:
+			glog.Infof("request %s for %s.requests.%s exceeds limit; clamping request to %s", request.String(), parentPath, k, limit.String())
+			merged.Resources.Requests[k] = limit
+		default:
+			glog.Infof("request %s for %s.requests.%s exceeds limit %s; raising limit", request.String(), parentPath, k, limit.String())
+			merged.Resources.Limits[k] = request
+		}
+	}
+
+	var currentResources v1.ResourceRequirements
+	if current != nil {
+		currentResources = current.Resources
+	}
+	if equality.Semantic.DeepEqual(merged.Resources, currentResources) {
+		return nil
+	}
+	return merged
+}
+
+// mergeResources sets each of the values in the ResourceList
+func mergeResources(resources *v1.ResourceList, values v1.ResourceList) {
+	for k, q := range values {
+		if *resources == nil {
+			*resources = make(v1.ResourceList)
+		}
+		(*resources)[k] = q
+	}
+}
@@ -0,0 +1,188 @@
+package scaling
+
+import (
+	"testing"
+	"time"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/debug"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestReconcileResources(t *testing.T) {
+	// memoryRule scales memory to 100Mi + 10Mi per node; we observe 20 nodes, so it computes 300Mi
+	memoryRule := scalingpolicy.ResourceScalingRule{
+		Resource: v1.ResourceMemory,
+		Function: scalingpolicy.ResourceScalingFunction{
+			Input: "nodes",
+			Base:  resource.MustParse("100Mi"),
+			Slope: resource.MustParse("10Mi"),
+		},
+	}
+
+	resources := func(limits, requests v1.ResourceList) v1.ResourceRequirements {
+		return v1.ResourceRequirements{Limits: limits, Requests: requests}
+	}
+
+	grid := []struct {
+		Name               string
+		RequestLimitPolicy scalingpolicy.RequestLimitPolicy
+		Rules              []scalingpolicy.ContainerScalingRule
+		Actual             v1.PodSpec
+		Expected           *v1.PodSpec
+	}{
+		{
+			Name: "request above limit raises limit",
+			Rules: []scalingpolicy.ContainerScalingRule{
+				{Name: "app", Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+			},
+			Actual: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("200Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("100Mi"), v1.ResourceCPU: resource.MustParse("100m")},
+				)},
+			}},
+			Expected: &v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi"), v1.ResourceCPU: resource.MustParse("100m")},
+				)},
+			}},
+		},
+		{
+			Name:               "request above limit is clamped",
+			RequestLimitPolicy: scalingpolicy.ClampRequests,
+			Rules: []scalingpolicy.ContainerScalingRule{
+				{Name: "app", Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+			},
+			Actual: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("200Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("100Mi")},
+				)},
+			}},
+			Expected: &v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("200Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("200Mi")},
+				)},
+			}},
+		},
+		{
+			Name:               "request already clamped",
+			RequestLimitPolicy: scalingpolicy.ClampRequests,
+			Rules: []scalingpolicy.ContainerScalingRule{
+				{Name: "app", Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+			},
+			Actual: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("200Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("200Mi")},
+				)},
+			}},
+		},
+		{
+			Name: "limit below request raises limit",
+			Rules: []scalingpolicy.ContainerScalingRule{
+				{Name: "app", Resources: scalingpolicy.ResourceRequirements{Limits: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+			},
+			Actual: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("500Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("400Mi")},
+				)},
+			}},
+			Expected: &v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("400Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("400Mi")},
+				)},
+			}},
+		},
+		{
+			Name:               "limit below request clamps request",
+			RequestLimitPolicy: scalingpolicy.ClampRequests,
+			Rules: []scalingpolicy.ContainerScalingRule{
+				{Name: "app", Resources: scalingpolicy.ResourceRequirements{Limits: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+			},
+			Actual: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("500Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("400Mi")},
+				)},
+			}},
+			Expected: &v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")},
+					v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")},
+				)},
+			}},
+		},
+		{
+			Name: "containers in order of the pod",
+			Rules: []scalingpolicy.ContainerScalingRule{
+				{Name: "missing", Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+				{Name: "sidecar", Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+				{Name: "app", Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+			},
+			Actual: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app"},
+				{Name: "unmanaged"},
+				{Name: "sidecar"},
+			}},
+			Expected: &v1.PodSpec{Containers: []v1.Container{
+				{Name: "app", Resources: resources(nil, v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")})},
+				{Name: "sidecar", Resources: resources(nil, v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")})},
+				{Name: "missing", Resources: resources(nil, v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")})},
+			}},
+		},
+		{
+			Name: "init container",
+			Rules: []scalingpolicy.ContainerScalingRule{
+				{Name: "warm", ContainerType: scalingpolicy.ContainerTypeInitContainers, Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+				{Name: "app", Resources: scalingpolicy.ResourceRequirements{Requests: []scalingpolicy.ResourceScalingRule{memoryRule}}},
+			},
+			Actual: v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "warm"}},
+				Containers:     []v1.Container{{Name: "app"}},
+			},
+			Expected: &v1.PodSpec{
+				InitContainers: []v1.Container{
+					{Name: "warm", Resources: resources(nil, v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")})},
+				},
+				Containers: []v1.Container{
+					{Name: "app", Resources: resources(nil, v1.ResourceList{v1.ResourceMemory: resource.MustParse("300Mi")})},
+				},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		clock := clock.NewFakeClock(time.Now())
+		snapshot, err := static.NewStaticFactors(clock, map[string]float64{"nodes": 20}).Snapshot()
+		if err != nil {
+			t.Fatalf("snapshot failed: %v", err)
+		}
+		policy := &scalingpolicy.ScalingPolicy{
+			Spec: scalingpolicy.ScalingPolicySpec{
+				Containers:         g.Rules,
+				RequestLimitPolicy: g.RequestLimitPolicy,
+			},
+		}
+		evaluator := NewScalingPolicyEvaluator(clock, policy)
+		evaluator.AddObservation(snapshot)
+
+		changes, err := evaluator.ComputeResources("", &g.Actual)
+		if err != nil {
+			t.Errorf("unexpected error from test\nname=%s\nerror=%v", g.Name, err)
+			continue
+		}
+		if !equality.Semantic.DeepEqual(changes, g.Expected) {
+			t.Errorf("test failure\nname=%s\n  actual=%v\nexpected=%v", g.Name, debug.Print(changes), debug.Print(g.Expected))
+		}
+	}
+}
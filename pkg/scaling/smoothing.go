@@ -0,0 +1,77 @@
+package scaling
+
+import (
+	"math"
+	"time"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// smoother computes a smoothed estimate from a series of values, as defined by a Smoothing policy.
+// With no policy, the smoothed value is simply the latest value.
+type smoother struct {
+	policy *scalingpolicy.Smoothing
+
+	// raw holds the values over the percentile window
+	raw timeSeries
+
+	// ewma is the exponentially weighted moving average, as of ewmaTime
+	ewma     float64
+	ewmaTime time.Time
+	hasEWMA  bool
+}
+
+// reset discards any state, and configures the smoother for the policy
+func (s *smoother) reset(clock clock.Clock, policy *scalingpolicy.Smoothing) {
+	s.policy = policy
+
+	retention := time.Duration(0)
+	if policy != nil {
+		retention = time.Duration(policy.WindowSeconds) * time.Second
+	}
+	s.raw.Reset(clock, retention)
+
+	s.ewma = 0
+	s.ewmaTime = time.Time{}
+	s.hasEWMA = false
+}
+
+// addObservation records the value, and returns the smoothed value
+func (s *smoother) addObservation(now time.Time, value float64) float64 {
+	if s.policy == nil {
+		return value
+	}
+
+	if s.policy.Percentile > 0 {
+		s.raw.addObservation(now, value)
+		window := time.Duration(s.policy.WindowSeconds) * time.Second
+		v, ok := s.raw.percentile(now, window, float64(s.policy.Percentile))
+		if !ok {
+			return value
+		}
+		return v
+	}
+
+	if s.policy.HalfLifeSeconds > 0 {
+		if !s.hasEWMA {
+			s.ewma = value
+			s.ewmaTime = now
+			s.hasEWMA = true
+			return s.ewma
+		}
+
+		// The weight of the previous average halves every half-life.
+		// We ignore the elapsed time for out-of-order values, so they carry no weight.
+		elapsed := now.Sub(s.ewmaTime)
+		if elapsed > 0 {
+			halfLife := time.Duration(s.policy.HalfLifeSeconds) * time.Second
+			alpha := 1 - math.Pow(2, -elapsed.Seconds()/halfLife.Seconds())
+			s.ewma += alpha * (value - s.ewma)
+			s.ewmaTime = now
+		}
+		return s.ewma
+	}
+
+	return value
+}
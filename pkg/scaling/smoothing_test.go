@@ -0,0 +1,171 @@
+package scaling
+
+import (
+	"testing"
+	"time"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/debug"
+	"github.com/justinsb/scaler/pkg/factors/static"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestSmoother(t *testing.T) {
+	type step struct {
+		// Offset is the time of the observation, relative to the start
+		Offset   time.Duration
+		Value    float64
+		Expected float64
+	}
+
+	grid := []struct {
+		Name   string
+		Policy *scalingpolicy.Smoothing
+		Steps  []step
+	}{
+		{
+			Name:   "No smoothing",
+			Policy: nil,
+			Steps: []step{
+				{Offset: 0, Value: 100, Expected: 100},
+				{Offset: 30 * time.Second, Value: 50, Expected: 50},
+			},
+		},
+		{
+			Name:   "EWMA",
+			Policy: &scalingpolicy.Smoothing{HalfLifeSeconds: 60},
+			Steps: []step{
+				{Offset: 0, Value: 100, Expected: 100},
+				{Offset: 60 * time.Second, Value: 0, Expected: 50},
+				{Offset: 120 * time.Second, Value: 0, Expected: 25},
+				// Values with no elapsed time, or out of order, carry no weight
+				{Offset: 120 * time.Second, Value: 1000, Expected: 25},
+				{Offset: 90 * time.Second, Value: 1000, Expected: 25},
+				{Offset: 180 * time.Second, Value: 25, Expected: 25},
+			},
+		},
+		{
+			Name:   "Median over window",
+			Policy: &scalingpolicy.Smoothing{Percentile: 50, WindowSeconds: 60},
+			Steps: []step{
+				{Offset: 0, Value: 10, Expected: 10},
+				{Offset: 30 * time.Second, Value: 20, Expected: 10},
+				{Offset: 60 * time.Second, Value: 30, Expected: 20},
+				// The value at 0s is now outside the window
+				{Offset: 90 * time.Second, Value: 40, Expected: 30},
+				{Offset: 120 * time.Second, Value: 0, Expected: 30},
+			},
+		},
+		{
+			Name:   "Percentile takes precedence over half-life",
+			Policy: &scalingpolicy.Smoothing{Percentile: 100, WindowSeconds: 60, HalfLifeSeconds: 60},
+			Steps: []step{
+				{Offset: 0, Value: 10, Expected: 10},
+				{Offset: 30 * time.Second, Value: 5, Expected: 10},
+				{Offset: 120 * time.Second, Value: 5, Expected: 5},
+			},
+		},
+	}
+
+	for _, g := range grid {
+		baseTime := time.Now()
+		clock := clock.NewFakeClock(baseTime)
+
+		s := &smoother{}
+		s.reset(clock, g.Policy)
+		for i, step := range g.Steps {
+			actual := s.addObservation(baseTime.Add(step.Offset), step.Value)
+			if actual != step.Expected {
+				t.Errorf("test failure\nname=%s\npolicy=%s\nstep=%d\n  actual=%v\nexpected=%v", g.Name, debug.Print(g.Policy), i, actual, step.Expected)
+				break
+			}
+		}
+	}
+}
+
+func TestSmoothingIgnoresBriefDip(t *testing.T) {
+	baseTime := time.Now()
+	clock := clock.NewFakeClock(baseTime)
+	inputs := map[string]float64{
+		"nodes": 10,
+	}
+	factors := static.NewStaticFactors(clock, inputs)
+
+	policy := &scalingpolicy.ScalingPolicy{
+		Spec: scalingpolicy.ScalingPolicySpec{
+			Containers: []scalingpolicy.ContainerScalingRule{
+				{
+					Name: "container1",
+					Resources: scalingpolicy.ResourceRequirements{
+						Requests: []scalingpolicy.ResourceScalingRule{
+							{
+								Resource: v1.ResourceCPU,
+								Function: scalingpolicy.ResourceScalingFunction{
+									Input: "nodes",
+									Slope: resource.MustParse("10m"),
+									Smoothing: &scalingpolicy.Smoothing{
+										Percentile:    90,
+										WindowSeconds: 300,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	evaluator := NewScalingPolicyEvaluator(clock, policy)
+	actual := &v1.PodSpec{Containers: []v1.Container{{Name: "container1"}}}
+
+	step := func(expected string) {
+		clock.Step(30 * time.Second)
+		snapshot, err := factors.Snapshot()
+		if err != nil {
+			t.Fatalf("snapshot failed: %v", err)
+		}
+		evaluator.AddObservation(snapshot)
+		changes, err := evaluator.ComputeResources("", actual)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var changed *resource.Quantity
+		if changes != nil {
+			q := changes.Containers[0].Resources.Requests[v1.ResourceCPU]
+			changed = &q
+			actual = changes
+		}
+		if expected == "" {
+			if changed != nil {
+				t.Fatalf("unexpected change at %s: %s", clock.Now().Sub(baseTime), changed.String())
+			}
+			return
+		}
+		if changed == nil || changed.Cmp(resource.MustParse(expected)) != 0 {
+			t.Fatalf("unexpected value at %s: actual=%v expected=%s", clock.Now().Sub(baseTime), changed, expected)
+		}
+	}
+
+	step("100m")
+	for i := 0; i < 9; i++ {
+		step("")
+	}
+
+	// A node is briefly replaced during a rolling update; we don't follow the dip
+	inputs["nodes"] = 9
+	step("")
+	inputs["nodes"] = 10
+	for i := 0; i < 10; i++ {
+		step("")
+	}
+
+	// When the cluster shrinks for longer than the window, we follow it down
+	inputs["nodes"] = 8
+	for i := 0; i < 9; i++ {
+		step("")
+	}
+	step("80m")
+}
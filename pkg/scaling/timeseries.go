@@ -0,0 +1,200 @@
+package scaling
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+// maxTimeSeriesSamples bounds the memory used by a timeSeries; at a 10 second poll interval this is over 11 hours
+const maxTimeSeriesSamples = 4096
+
+// timeSeries holds the values observed over a retention period, ordered by timestamp.
+// It is a ring buffer, so appending a value in timestamp order is O(1), and memory is bounded by maxTimeSeriesSamples.
+type timeSeries struct {
+	// Start is the time at which we started this series
+	Start time.Time
+
+	// Retention determines for how long we will retain values (though we always retain the latest)
+	Retention time.Duration
+
+	// maxSamples is the maximum number of samples we retain; if zero, maxTimeSeriesSamples is used
+	maxSamples int
+
+	// samples is the ring buffer; the oldest sample is at head
+	samples []sample
+	head    int
+	count   int
+}
+
+type sample struct {
+	t     time.Time
+	value float64
+}
+
+// Reset discards all values, and sets the retention
+func (s *timeSeries) Reset(clock clock.Clock, retention time.Duration) {
+	s.Start = clock.Now()
+	s.Retention = retention
+	s.samples = nil
+	s.head = 0
+	s.count = 0
+}
+
+// at returns the i-th oldest sample
+func (s *timeSeries) at(i int) *sample {
+	return &s.samples[(s.head+i)%len(s.samples)]
+}
+
+// latest returns the sample with the latest timestamp
+func (s *timeSeries) latest() (sample, bool) {
+	if s.count == 0 {
+		return sample{}, false
+	}
+	return *s.at(s.count - 1), true
+}
+
+// addObservation records a value.  Values are normally observed in timestamp order, but a slow snapshot
+// can arrive out of order; we insert it in the correct position so that the series remains ordered.
+func (s *timeSeries) addObservation(t time.Time, value float64) {
+	if newest, ok := s.latest(); ok && t.Before(newest.t) {
+		if newest.t.Sub(t) > s.Retention {
+			// Already outside the retention period
+			return
+		}
+		s.push(sample{})
+		// Shift newer samples along, to make room in the correct position
+		i := s.count - 1
+		for ; i > 0 && s.at(i-1).t.After(t); i-- {
+			*s.at(i) = *s.at(i - 1)
+		}
+		*s.at(i) = sample{t: t, value: value}
+	} else {
+		s.push(sample{t: t, value: value})
+	}
+
+	s.expire()
+}
+
+// push appends a sample to the ring buffer, growing it up to the maximum size, and then overwriting the oldest sample
+func (s *timeSeries) push(v sample) {
+	maxSamples := s.maxSamples
+	if maxSamples == 0 {
+		maxSamples = maxTimeSeriesSamples
+	}
+
+	if s.count == len(s.samples) {
+		if len(s.samples) < maxSamples {
+			n := 2 * len(s.samples)
+			if n == 0 {
+				n = 8
+			}
+			if n > maxSamples {
+				n = maxSamples
+			}
+			samples := make([]sample, n)
+			for i := 0; i < s.count; i++ {
+				samples[i] = *s.at(i)
+			}
+			s.samples = samples
+			s.head = 0
+		} else {
+			// Full; drop the oldest
+			s.head = (s.head + 1) % len(s.samples)
+			s.count--
+		}
+	}
+
+	s.count++
+	*s.at(s.count - 1) = v
+}
+
+// expire removes the values that are older than the retention period, relative to the latest value
+func (s *timeSeries) expire() {
+	newest, ok := s.latest()
+	if !ok {
+		return
+	}
+	for s.count > 1 && newest.t.Sub(s.at(0).t) > s.Retention {
+		s.head = (s.head + 1) % len(s.samples)
+		s.count--
+	}
+}
+
+type windowStats struct {
+	N   int
+	Min float64
+	Max float64
+	// Mean is the average of the values in the window
+	Mean float64
+
+	LatestValue     float64
+	HasLatest       bool
+	LatestTimestamp time.Time
+}
+
+// stats computes the statistics of the values in the window ending at now.
+// The latest value is always returned, even if it is outside the window.
+func (s *timeSeries) stats(now time.Time, window time.Duration) windowStats {
+	var stats windowStats
+	stats.Min = math.MaxFloat64
+	stats.Max = -math.MaxFloat64
+
+	if newest, ok := s.latest(); ok {
+		stats.LatestTimestamp = newest.t
+		stats.LatestValue = newest.value
+		stats.HasLatest = true
+	}
+
+	sum := float64(0)
+	s.visit(now, window, func(v float64) {
+		if v < stats.Min {
+			stats.Min = v
+		}
+		if v > stats.Max {
+			stats.Max = v
+		}
+		sum += v
+		stats.N++
+	})
+	if stats.N != 0 {
+		stats.Mean = sum / float64(stats.N)
+	}
+	return stats
+}
+
+// percentile returns the value at the specified percentile (0-100) of the values in the window,
+// using the nearest-rank method.  It returns false if there are no values in the window.
+func (s *timeSeries) percentile(now time.Time, window time.Duration, percentile float64) (float64, bool) {
+	var values []float64
+	s.visit(now, window, func(v float64) {
+		values = append(values, v)
+	})
+	if len(values) == 0 {
+		return 0, false
+	}
+	sort.Float64s(values)
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(values) {
+		rank = len(values)
+	}
+	return values[rank-1], true
+}
+
+// visit calls fn for each value in the window ending at now, newest first
+func (s *timeSeries) visit(now time.Time, window time.Duration, fn func(v float64)) {
+	for i := s.count - 1; i >= 0; i-- {
+		v := s.at(i)
+		if now.Sub(v.t) > window {
+			// Values are ordered, so all the remaining values are also outside the window
+			break
+		}
+		fn(v.value)
+	}
+}
@@ -0,0 +1,228 @@
+package scaling
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestTimeSeries(t *testing.T) {
+	type observation struct {
+		// Offset is the timestamp of the observation, relative to the start
+		Offset time.Duration
+		Value  float64
+	}
+
+	grid := []struct {
+		Name         string
+		Retention    time.Duration
+		MaxSamples   int
+		Observations []observation
+
+		// Now is the time of the query, relative to the start
+		Now    time.Duration
+		Window time.Duration
+
+		ExpectedValues []float64
+		ExpectedStats  windowStats
+	}{
+		{
+			Name:          "Empty",
+			Retention:     time.Minute,
+			Window:        time.Minute,
+			ExpectedStats: windowStats{},
+		},
+		{
+			Name:      "Latest is the newest value",
+			Retention: time.Minute,
+			Observations: []observation{
+				{Offset: 0, Value: 40},
+				{Offset: 10 * time.Second, Value: 10},
+				{Offset: 20 * time.Second, Value: 30},
+				{Offset: 30 * time.Second, Value: 20},
+			},
+			Now:            30 * time.Second,
+			Window:         time.Minute,
+			ExpectedValues: []float64{40, 10, 30, 20},
+			ExpectedStats:  windowStats{N: 4, Min: 10, Max: 40, Mean: 25, LatestValue: 20, HasLatest: true, LatestTimestamp: offset(30 * time.Second)},
+		},
+		{
+			Name:      "Window is a subset",
+			Retention: time.Minute,
+			Observations: []observation{
+				{Offset: 0, Value: 40},
+				{Offset: 10 * time.Second, Value: 10},
+				{Offset: 20 * time.Second, Value: 30},
+				{Offset: 30 * time.Second, Value: 20},
+			},
+			Now:            30 * time.Second,
+			Window:         15 * time.Second,
+			ExpectedValues: []float64{40, 10, 30, 20},
+			ExpectedStats:  windowStats{N: 2, Min: 20, Max: 30, Mean: 25, LatestValue: 20, HasLatest: true, LatestTimestamp: offset(30 * time.Second)},
+		},
+		{
+			Name:      "Values outside the retention are expired",
+			Retention: 15 * time.Second,
+			Observations: []observation{
+				{Offset: 0, Value: 40},
+				{Offset: 10 * time.Second, Value: 10},
+				{Offset: 20 * time.Second, Value: 30},
+				{Offset: 30 * time.Second, Value: 20},
+			},
+			Now:            30 * time.Second,
+			Window:         time.Minute,
+			ExpectedValues: []float64{30, 20},
+			ExpectedStats:  windowStats{N: 2, Min: 20, Max: 30, Mean: 25, LatestValue: 20, HasLatest: true, LatestTimestamp: offset(30 * time.Second)},
+		},
+		{
+			Name:      "Latest is retained outside the window",
+			Retention: 0,
+			Observations: []observation{
+				{Offset: 0, Value: 40},
+				{Offset: 10 * time.Second, Value: 10},
+			},
+			Now:            time.Hour,
+			Window:         time.Minute,
+			ExpectedValues: []float64{10},
+			ExpectedStats:  windowStats{N: 0, Min: 0, Max: 0, LatestValue: 10, HasLatest: true, LatestTimestamp: offset(10 * time.Second)},
+		},
+		{
+			Name:      "Out of order values are inserted in order",
+			Retention: time.Minute,
+			Observations: []observation{
+				{Offset: 0, Value: 40},
+				{Offset: 20 * time.Second, Value: 30},
+				{Offset: 30 * time.Second, Value: 20},
+				// A slow snapshot
+				{Offset: 10 * time.Second, Value: 10},
+				{Offset: 25 * time.Second, Value: 50},
+			},
+			Now:            30 * time.Second,
+			Window:         10 * time.Second,
+			ExpectedValues: []float64{40, 10, 30, 50, 20},
+			ExpectedStats:  windowStats{N: 3, Min: 20, Max: 50, Mean: 100.0 / 3, LatestValue: 20, HasLatest: true, LatestTimestamp: offset(30 * time.Second)},
+		},
+		{
+			Name:      "Out of order values outside the retention are ignored",
+			Retention: 15 * time.Second,
+			Observations: []observation{
+				{Offset: 20 * time.Second, Value: 30},
+				{Offset: 30 * time.Second, Value: 20},
+				{Offset: 10 * time.Second, Value: 10},
+			},
+			Now:            30 * time.Second,
+			Window:         time.Minute,
+			ExpectedValues: []float64{30, 20},
+			ExpectedStats:  windowStats{N: 2, Min: 20, Max: 30, Mean: 25, LatestValue: 20, HasLatest: true, LatestTimestamp: offset(30 * time.Second)},
+		},
+		{
+			Name:       "Memory is bounded",
+			Retention:  time.Hour,
+			MaxSamples: 3,
+			Observations: []observation{
+				{Offset: 0, Value: 1},
+				{Offset: 10 * time.Second, Value: 2},
+				{Offset: 20 * time.Second, Value: 3},
+				{Offset: 30 * time.Second, Value: 4},
+				{Offset: 40 * time.Second, Value: 5},
+				{Offset: 35 * time.Second, Value: 6},
+			},
+			Now:            40 * time.Second,
+			Window:         time.Hour,
+			ExpectedValues: []float64{4, 6, 5},
+			ExpectedStats:  windowStats{N: 3, Min: 4, Max: 6, Mean: 5, LatestValue: 5, HasLatest: true, LatestTimestamp: offset(40 * time.Second)},
+		},
+	}
+
+	for _, g := range grid {
+		clock := clock.NewFakeClock(offset(0))
+		s := &timeSeries{maxSamples: g.MaxSamples}
+		s.Reset(clock, g.Retention)
+		for _, o := range g.Observations {
+			s.addObservation(offset(o.Offset), o.Value)
+		}
+
+		var values []float64
+		for i := 0; i < s.count; i++ {
+			values = append(values, s.at(i).value)
+		}
+		if !reflect.DeepEqual(values, g.ExpectedValues) {
+			t.Errorf("test failure\nname=%s\n  actual values=%v\nexpected values=%v", g.Name, values, g.ExpectedValues)
+		}
+
+		stats := s.stats(offset(g.Now), g.Window)
+		if stats.N == 0 {
+			// Min & max are not meaningful
+			stats.Min = 0
+			stats.Max = 0
+		}
+		if stats != g.ExpectedStats {
+			t.Errorf("test failure\nname=%s\n  actual stats=%+v\nexpected stats=%+v", g.Name, stats, g.ExpectedStats)
+		}
+	}
+}
+
+func TestTimeSeriesPercentile(t *testing.T) {
+	clock := clock.NewFakeClock(offset(0))
+	s := &timeSeries{}
+	s.Reset(clock, time.Minute)
+	for i, v := range []float64{40, 10, 30, 20} {
+		s.addObservation(offset(time.Duration(i)*10*time.Second), v)
+	}
+	now := offset(30 * time.Second)
+
+	grid := []struct {
+		Window     time.Duration
+		Percentile float64
+		Expected   float64
+	}{
+		{Window: time.Minute, Percentile: 0, Expected: 10},
+		{Window: time.Minute, Percentile: 25, Expected: 10},
+		{Window: time.Minute, Percentile: 50, Expected: 20},
+		{Window: time.Minute, Percentile: 90, Expected: 40},
+		{Window: time.Minute, Percentile: 100, Expected: 40},
+		{Window: 15 * time.Second, Percentile: 50, Expected: 20},
+		{Window: 15 * time.Second, Percentile: 100, Expected: 30},
+	}
+	for _, g := range grid {
+		actual, ok := s.percentile(now, g.Window, g.Percentile)
+		if !ok || actual != g.Expected {
+			t.Errorf("test failure\nwindow=%s percentile=%v\n  actual=%v %v\nexpected=%v", g.Window, g.Percentile, actual, ok, g.Expected)
+		}
+	}
+
+	if _, ok := s.percentile(offset(time.Hour), time.Minute, 50); ok {
+		t.Errorf("expected no values in window an hour later")
+	}
+}
+
+func TestTimeSeriesGrowsAndWraps(t *testing.T) {
+	clock := clock.NewFakeClock(offset(0))
+	s := &timeSeries{maxSamples: 20}
+	s.Reset(clock, time.Hour)
+
+	for i := 0; i < 100; i++ {
+		s.addObservation(offset(time.Duration(i)*time.Second), float64(i))
+
+		expectedCount := i + 1
+		if expectedCount > 20 {
+			expectedCount = 20
+		}
+		if s.count != expectedCount || len(s.samples) > 20 {
+			t.Fatalf("unexpected size after %d values: count=%d capacity=%d", i+1, s.count, len(s.samples))
+		}
+		for j := 0; j < s.count; j++ {
+			expected := float64(i + 1 - s.count + j)
+			if s.at(j).value != expected {
+				t.Fatalf("unexpected value %d after %d values: actual=%v expected=%v", j, i+1, s.at(j).value, expected)
+			}
+		}
+	}
+}
+
+// offset returns a fixed time, offset by d, so that expected timestamps can be compared
+func offset(d time.Duration) time.Time {
+	return time.Date(2017, 11, 1, 0, 0, 0, 0, time.UTC).Add(d)
+}
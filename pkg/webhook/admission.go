@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The vendored client library predates admission.k8s.io/v1beta1, so we define the subset of
+// the AdmissionReview types that we use.  The JSON serialization matches the apiserver.
+
+// AdmissionReview is sent by the apiserver to the webhook, and returned with the Response populated
+type AdmissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Request  *AdmissionRequest  `json:"request,omitempty"`
+	Response *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest describes the operation being admitted
+type AdmissionRequest struct {
+	// UID identifies the request; it must be copied into the response
+	UID types.UID `json:"uid"`
+
+	Kind      metav1.GroupVersionKind     `json:"kind"`
+	Resource  metav1.GroupVersionResource `json:"resource"`
+	Namespace string                      `json:"namespace,omitempty"`
+	Name      string                      `json:"name,omitempty"`
+
+	// Operation is CREATE, UPDATE, DELETE or CONNECT
+	Operation string `json:"operation"`
+
+	// Object is the object being admitted; it is not set for DELETE
+	Object runtime.RawExtension `json:"object,omitempty"`
+}
+
+// AdmissionResponse is the result of admission
+type AdmissionResponse struct {
+	UID     types.UID `json:"uid"`
+	Allowed bool      `json:"allowed"`
+
+	// Result holds the reason the request was denied
+	Result *metav1.Status `json:"status,omitempty"`
+}
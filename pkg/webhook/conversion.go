@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// As with AdmissionReview, we define the subset of the apiextensions.k8s.io/v1beta1 ConversionReview types that we use.
+
+// ConversionReview is sent by the apiserver to the webhook, and returned with the Response populated
+type ConversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Request  *ConversionRequest  `json:"request,omitempty"`
+	Response *ConversionResponse `json:"response,omitempty"`
+}
+
+// ConversionRequest holds the objects to convert
+type ConversionRequest struct {
+	// UID identifies the request; it must be copied into the response
+	UID types.UID `json:"uid"`
+
+	// DesiredAPIVersion is the version to which we should convert the objects, e.g. scalingpolicy.kope.io/v1beta1
+	DesiredAPIVersion string `json:"desiredAPIVersion"`
+
+	Objects []runtime.RawExtension `json:"objects"`
+}
+
+// ConversionResponse holds the converted objects, in the same order as the request
+type ConversionResponse struct {
+	UID types.UID `json:"uid"`
+
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+
+	// Result has a status of Success, or Failure with the reason
+	Result metav1.Status `json:"result"`
+}
+
+// Converter is a conversion webhook for ScalingPolicy objects, converting between v1alpha1 & v1beta1
+type Converter struct {
+}
+
+var _ http.Handler = &Converter{}
+
+// NewConverter builds a Converter
+func NewConverter() *Converter {
+	return &Converter{}
+}
+
+func (c *Converter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("error parsing ConversionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "ConversionReview did not include a request", http.StatusBadRequest)
+		return
+	}
+
+	response := &ConversionResponse{UID: review.Request.UID}
+	converted, err := c.convert(review.Request)
+	if err != nil {
+		glog.Warningf("error converting ScalingPolicy objects to %s: %v", review.Request.DesiredAPIVersion, err)
+		response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+	} else {
+		response.ConvertedObjects = converted
+		response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	}
+	review.Response = response
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		glog.Warningf("error writing http response: %v", err)
+	}
+}
+
+// convert converts each of the objects to the desired version; we fail the whole request if any object can't be converted
+func (c *Converter) convert(request *ConversionRequest) ([]runtime.RawExtension, error) {
+	gv, err := schema.ParseGroupVersion(request.DesiredAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid desiredAPIVersion %q: %v", request.DesiredAPIVersion, err)
+	}
+	if !scheme.Recognizes(gv.WithKind("ScalingPolicy")) {
+		return nil, fmt.Errorf("unsupported desiredAPIVersion %q", request.DesiredAPIVersion)
+	}
+
+	var converted []runtime.RawExtension
+	for i := range request.Objects {
+		obj, _, err := codecs.UniversalDeserializer().Decode(request.Objects[i].Raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing object %d: %v", i, err)
+		}
+
+		out, err := scheme.ConvertToVersion(obj, gv)
+		if err != nil {
+			return nil, fmt.Errorf("error converting object %d: %v", i, err)
+		}
+		// The defaults are the values we assume for unset fields, so applying them doesn't change the meaning
+		scheme.Default(out)
+
+		raw, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("error serializing object %d: %v", i, err)
+		}
+		converted = append(converted, runtime.RawExtension{Raw: raw})
+	}
+	return converted, nil
+}
@@ -0,0 +1,113 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const alphaPolicy = `{"apiVersion": "scalingpolicy.kope.io/v1alpha1", "kind": "ScalingPolicy", "metadata": {"name": "policy1", "namespace": "ns1"},
+	"spec": {"scaleTargetRef": {"kind": "Deployment", "name": "deployment1"}, "containers": [{"name": "container1", "resources": {"limits": [
+		{"resource": "cpu", "function": {"input": "cores", "base": "200m", "slope": "1m", "int": 2, "segments": [{"at": 10, "every": 5}], "delayScaleDown": {"delaySeconds": 300}}}]}}]}}`
+
+const betaPolicy = `{"apiVersion": "scalingpolicy.kope.io/v1beta1", "kind": "ScalingPolicy", "metadata": {"name": "policy1", "namespace": "ns1"},
+	"spec": {"scaleTargetRef": {"kind": "Deployment", "name": "deployment1"}, "containers": [{"name": "container1", "resources": {"limits": [
+		{"resource": "memory", "delayScaleDown": {"delaySeconds": 300}, "function": {"input": "memory", "slope": "1Mi", "per": "1Gi", "segments": [{"at": "64Gi", "every": "16Gi"}]}}]}}]}}`
+
+func TestConverter(t *testing.T) {
+	converter := NewConverter()
+
+	grid := []struct {
+		Name              string
+		DesiredAPIVersion string
+		Objects           []string
+		Expected          []string
+		Error             string
+	}{
+		{
+			Name:              "v1alpha1 to v1beta1",
+			DesiredAPIVersion: "scalingpolicy.kope.io/v1beta1",
+			Objects:           []string{alphaPolicy},
+			Expected:          []string{`"apiVersion":"scalingpolicy.kope.io/v1beta1"`, `"combiner":"sum","delayScaleDown":{"delaySeconds":300}`, `"per":"2","segments":[{"at":"10","every":"5"}]`},
+		},
+		{
+			Name:              "v1beta1 to v1alpha1",
+			DesiredAPIVersion: "scalingpolicy.kope.io/v1alpha1",
+			Objects:           []string{betaPolicy},
+			Expected:          []string{`"apiVersion":"scalingpolicy.kope.io/v1alpha1"`, `"int":1073741824,"segments":[{"at":68719476736,"every":17179869184}],"delayScaleDown":{"delaySeconds":300}`},
+		},
+		{
+			Name:              "same version",
+			DesiredAPIVersion: "scalingpolicy.kope.io/v1beta1",
+			Objects:           []string{betaPolicy},
+			Expected:          []string{`"apiVersion":"scalingpolicy.kope.io/v1beta1"`, `"per":"1Gi","segments":[{"at":"64Gi","every":"16Gi"}]`},
+		},
+		{
+			Name:              "not representable",
+			DesiredAPIVersion: "scalingpolicy.kope.io/v1alpha1",
+			Objects:           []string{strings.Replace(betaPolicy, `"16Gi"`, `"500m"`, 1)},
+			Error:             "error converting object 0: every 500m cannot be represented in v1alpha1",
+		},
+		{
+			Name:              "unsupported version",
+			DesiredAPIVersion: "scalingpolicy.kope.io/v2",
+			Objects:           []string{alphaPolicy},
+			Error:             `unsupported desiredAPIVersion "scalingpolicy.kope.io/v2"`,
+		},
+	}
+
+	for _, g := range grid {
+		request := &ConversionRequest{
+			UID:               "uid1",
+			DesiredAPIVersion: g.DesiredAPIVersion,
+		}
+		for _, o := range g.Objects {
+			request.Objects = append(request.Objects, runtime.RawExtension{Raw: []byte(o)})
+		}
+		body, err := json.Marshal(&ConversionReview{Request: request})
+		if err != nil {
+			t.Fatalf("error serializing review: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		converter.ServeHTTP(w, httptest.NewRequest("POST", "/convert", bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Errorf("test failure\nname=%s\nunexpected status %d: %s", g.Name, w.Code, w.Body.String())
+			continue
+		}
+
+		review := &ConversionReview{}
+		if err := json.Unmarshal(w.Body.Bytes(), review); err != nil {
+			t.Fatalf("error parsing response: %v", err)
+		}
+		response := review.Response
+		if response == nil || response.UID != "uid1" {
+			t.Errorf("test failure\nname=%s\nunexpected response %+v", g.Name, response)
+			continue
+		}
+
+		if g.Error != "" {
+			if response.Result.Status != metav1.StatusFailure || !strings.HasPrefix(response.Result.Message, g.Error) {
+				t.Errorf("test failure\nname=%s\n  actual=%+v\nexpected=%s", g.Name, response.Result, g.Error)
+			}
+			continue
+		}
+
+		if response.Result.Status != metav1.StatusSuccess || len(response.ConvertedObjects) != len(g.Objects) {
+			t.Errorf("test failure\nname=%s\nunexpected response %+v", g.Name, response)
+			continue
+		}
+		converted := string(response.ConvertedObjects[0].Raw)
+		for _, expected := range g.Expected {
+			if !strings.Contains(converted, expected) {
+				t.Errorf("test failure\nname=%s\n  actual=%s\nexpected to contain %s", g.Name, converted, expected)
+			}
+		}
+	}
+}
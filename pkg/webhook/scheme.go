@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+// scheme holds all the versions of the ScalingPolicy API, with the conversions & defaults between them
+var scheme = runtime.NewScheme()
+var codecs = serializer.NewCodecFactory(scheme)
+
+func init() {
+	v1alpha1.AddToScheme(scheme)
+	v1beta1.AddToScheme(scheme)
+}
+
+// decodePolicy parses a ScalingPolicy in any version, and converts it to v1alpha1, the version the controller uses.
+// Objects without an apiVersion & kind are assumed to be v1alpha1.
+func decodePolicy(raw []byte) (*v1alpha1.ScalingPolicy, error) {
+	defaultGVK := v1alpha1.SchemeGroupVersion.WithKind("ScalingPolicy")
+	obj, _, err := codecs.UniversalDeserializer().Decode(raw, &defaultGVK, nil)
+	if err != nil {
+		return nil, err
+	}
+	if policy, ok := obj.(*v1alpha1.ScalingPolicy); ok {
+		return policy, nil
+	}
+
+	policy := &v1alpha1.ScalingPolicy{}
+	if err := scheme.Convert(obj, policy, nil); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/justinsb/scaler/cmd/scaler/options"
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/apis/scalingpolicy/validation"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Validator is a validating admission webhook for ScalingPolicy objects
+type Validator struct {
+	// target is used to check that the containers exist in the target; if nil we don't check
+	target target.Interface
+}
+
+var _ http.Handler = &Validator{}
+
+// NewValidator builds a Validator; target may be nil if we should not check the containers of the target
+func NewValidator(target target.Interface) *Validator {
+	return &Validator{target: target}
+}
+
+func (v *Validator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("error parsing AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "AdmissionReview did not include a request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = v.admit(review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		glog.Warningf("error writing http response: %v", err)
+	}
+}
+
+// admit validates the ScalingPolicy in the request
+func (v *Validator) admit(request *AdmissionRequest) *AdmissionResponse {
+	if request.Operation != "CREATE" && request.Operation != "UPDATE" {
+		return &AdmissionResponse{Allowed: true}
+	}
+
+	policy, err := decodePolicy(request.Object.Raw)
+	if err != nil {
+		return deny(errors.NewBadRequest(fmt.Sprintf("error parsing ScalingPolicy: %v", err)))
+	}
+	// The namespace is not set in the object when it is created
+	if policy.Namespace == "" {
+		policy.Namespace = request.Namespace
+	}
+
+	errs := validation.ValidateScalingPolicy(policy)
+	if len(errs) == 0 {
+		errs = v.validateTarget(policy)
+	}
+	if len(errs) != 0 {
+		glog.V(2).Infof("denying ScalingPolicy %s/%s: %v", policy.Namespace, policy.Name, errs.ToAggregate())
+		return deny(errors.NewInvalid(scalingpolicy.Kind("ScalingPolicy"), policy.Name, errs))
+	}
+
+	return &AdmissionResponse{Allowed: true}
+}
+
+// validateTarget checks that the containers named in the policy exist in the target.
+// We allow the policy if we can't read the target, because the target may be created after the policy.
+func (v *Validator) validateTarget(policy *scalingpolicy.ScalingPolicy) field.ErrorList {
+	if v.target == nil {
+		return nil
+	}
+
+	ref := &target.Ref{
+		APIVersion:  policy.Spec.ScaleTargetRef.APIVersion,
+		Kind:        policy.Spec.ScaleTargetRef.Kind,
+		Namespace:   policy.Namespace,
+		Name:        policy.Spec.ScaleTargetRef.Name,
+		PodSpecPath: policy.Spec.PodSpecPath,
+	}
+	podSpec, err := v.target.Read(ref)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			glog.V(2).Infof("target %s not found; not validating containers", ref)
+		} else {
+			glog.Warningf("error reading target %s; not validating containers: %v", ref, err)
+		}
+		return nil
+	}
+	return validation.ValidateTargetContainers(&policy.Spec, podSpec, field.NewPath("spec"))
+}
+
+// deny returns a response denying the request, with the reason from the error
+func deny(err *errors.StatusError) *AdmissionResponse {
+	status := err.Status()
+	return &AdmissionResponse{
+		Allowed: false,
+		Result:  &status,
+	}
+}
+
+// Server serves the webhook over TLS, as required by the apiserver
+type Server struct {
+	server   *http.Server
+	certFile string
+	keyFile  string
+}
+
+// NewServer builds a Server for the validating & conversion webhooks, listening on --listen-webhook
+func NewServer(options *options.AutoScalerConfig, validator *Validator, converter *Converter) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/validate", validator)
+	mux.Handle("/convert", converter)
+
+	return &Server{
+		server: &http.Server{
+			Addr:    options.ListenWebhook,
+			Handler: mux,
+		},
+		certFile: options.WebhookCertFile,
+		keyFile:  options.WebhookKeyFile,
+	}
+}
+
+func (s *Server) Start(stopCh <-chan struct{}) error {
+	go func() {
+		<-stopCh
+		s.server.Close()
+	}()
+
+	glog.Infof("webhook listening on %s", s.server.Addr)
+	err := s.server.ListenAndServeTLS(s.certFile, s.keyFile)
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
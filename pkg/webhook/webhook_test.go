@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	scalingpolicy "github.com/justinsb/scaler/pkg/apis/scalingpolicy/v1alpha1"
+	"github.com/justinsb/scaler/pkg/control/target"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func buildPolicy(containerName string, every int64) *scalingpolicy.ScalingPolicy {
+	policy := &scalingpolicy.ScalingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy1"},
+	}
+	policy.Spec.ScaleTargetRef.Kind = "Deployment"
+	policy.Spec.ScaleTargetRef.Name = "deployment1"
+	policy.Spec.Containers = []scalingpolicy.ContainerScalingRule{
+		{
+			Name: containerName,
+			Resources: scalingpolicy.ResourceRequirements{
+				Limits: []scalingpolicy.ResourceScalingRule{
+					{
+						Resource: "memory",
+						Function: scalingpolicy.ResourceScalingFunction{
+							Input:    "nodes",
+							Slope:    resource.MustParse("1Mi"),
+							Segments: []scalingpolicy.ResourceScalingSegment{{At: 0, Every: every}},
+						},
+					},
+				},
+			},
+		},
+	}
+	return policy
+}
+
+func TestValidator(t *testing.T) {
+	universe := target.NewSimulationTarget()
+	universe.Current = &v1.PodSpec{Containers: []v1.Container{{Name: "container1"}}}
+	validator := NewValidator(universe)
+
+	grid := []struct {
+		Name      string
+		Operation string
+		Policy    *scalingpolicy.ScalingPolicy
+		Raw       string
+		NoTarget  bool
+		Allowed   bool
+		Message   string
+	}{
+		{
+			Name:      "valid",
+			Operation: "CREATE",
+			Policy:    buildPolicy("container1", 1),
+			Allowed:   true,
+		},
+		{
+			Name:      "invalid spec",
+			Operation: "UPDATE",
+			Policy:    buildPolicy("container1", 0),
+			Message:   `ScalingPolicy.scalingpolicy.kope.io "policy1" is invalid: spec.containers[0].resources.limits[0].function.segments[0].every: Invalid value: 0: must be greater than zero`,
+		},
+		{
+			Name:      "container not in target",
+			Operation: "CREATE",
+			Policy:    buildPolicy("container2", 1),
+			Message:   `ScalingPolicy.scalingpolicy.kope.io "policy1" is invalid: spec.containers[0].name: Not found: "container2"`,
+		},
+		{
+			Name:      "target not found",
+			Operation: "CREATE",
+			Policy:    buildPolicy("container2", 1),
+			NoTarget:  true,
+			Allowed:   true,
+		},
+		{
+			Name:      "v1beta1",
+			Operation: "CREATE",
+			Raw:       `{"apiVersion": "scalingpolicy.kope.io/v1beta1", "kind": "ScalingPolicy", "metadata": {"name": "policy1"}, "spec": {"scaleTargetRef": {"kind": "Deployment", "name": "deployment1"}, "containers": [{"name": "container1", "resources": {"limits": [{"resource": "memory", "function": {"input": "memory", "slope": "1Mi", "per": "1Gi", "segments": [{"at": "64Gi", "every": 0}]}}]}}]}}`,
+			Message:   `ScalingPolicy.scalingpolicy.kope.io "policy1" is invalid: spec.containers[0].resources.limits[0].function.segments[0].every: Invalid value: 0: must be greater than zero`,
+		},
+		{
+			Name:      "delete",
+			Operation: "DELETE",
+			Allowed:   true,
+		},
+	}
+
+	for _, g := range grid {
+		universe.Current = &v1.PodSpec{Containers: []v1.Container{{Name: "container1"}}}
+		if g.NoTarget {
+			universe.Current = nil
+		}
+
+		request := &AdmissionRequest{
+			UID:       "uid1",
+			Namespace: "ns1",
+			Operation: g.Operation,
+		}
+		if g.Policy != nil {
+			raw, err := json.Marshal(g.Policy)
+			if err != nil {
+				t.Fatalf("error serializing policy: %v", err)
+			}
+			request.Object = runtime.RawExtension{Raw: raw}
+		}
+		if g.Raw != "" {
+			request.Object = runtime.RawExtension{Raw: []byte(g.Raw)}
+		}
+		body, err := json.Marshal(&AdmissionReview{Request: request})
+		if err != nil {
+			t.Fatalf("error serializing review: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		validator.ServeHTTP(w, httptest.NewRequest("POST", "/validate", bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Errorf("test failure\nname=%s\nunexpected status %d: %s", g.Name, w.Code, w.Body.String())
+			continue
+		}
+
+		review := &AdmissionReview{}
+		if err := json.Unmarshal(w.Body.Bytes(), review); err != nil {
+			t.Fatalf("error parsing response: %v", err)
+		}
+		response := review.Response
+		if response == nil || response.UID != "uid1" || response.Allowed != g.Allowed {
+			t.Errorf("test failure\nname=%s\nunexpected response %+v", g.Name, response)
+			continue
+		}
+		if !g.Allowed {
+			if response.Result == nil || response.Result.Code != http.StatusUnprocessableEntity || !strings.HasPrefix(response.Result.Message, g.Message) {
+				t.Errorf("test failure\nname=%s\n  actual=%+v\nexpected=%s", g.Name, response.Result, g.Message)
+			}
+		}
+	}
+}
+
+func TestValidatorRejectsBadRequests(t *testing.T) {
+	validator := NewValidator(nil)
+
+	w := httptest.NewRecorder()
+	validator.ServeHTTP(w, httptest.NewRequest("POST", "/validate", strings.NewReader("{}")))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected bad request for review without request, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	validator.ServeHTTP(w, httptest.NewRequest("GET", "/validate", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected method not allowed for GET, got %d", w.Code)
+	}
+}
@@ -14,6 +14,7 @@ var graphListTemplate = `
     <meta charset="utf-8">
 </head>
 <body>
+{{if .Leader}}<p>Leader: {{.Leader}}</p>{{end}}
 <ul>
 	{{range .Graphs}}<li><a href="./{{.Key}}">{{ .Key }}</a></li>{{end}}
 </ul>
@@ -22,16 +23,20 @@ var graphListTemplate = `
 `
 
 type graphListData struct {
+	// Leader describes the replica applying policies; it is empty if there is no leader election
+	Leader string
+
 	Graphs []*graph.Metadata
 }
 
-func BuildGraphListPage(graphs []*graph.Metadata) ([]byte, error) {
+func BuildGraphListPage(graphs []*graph.Metadata, leader string) ([]byte, error) {
 	tmpl, err := template.New("graphlist").Parse(graphListTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing graphlist template: %v", err)
 	}
 
 	data := &graphListData{
+		Leader: leader,
 		Graphs: graphs,
 	}
 
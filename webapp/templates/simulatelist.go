@@ -14,6 +14,7 @@ var simulateListTemplate = `
     <meta charset="utf-8">
 </head>
 <body>
+{{if .Leader}}<p>Leader: {{.Leader}}</p>{{end}}
 <ul>
 	{{range .Simulations}}<li><a href="./{{.Key}}">{{ .Key }}</a></li>{{end}}
 </ul>
@@ -22,16 +23,20 @@ var simulateListTemplate = `
 `
 
 type simulateListData struct {
+	// Leader describes the replica applying policies; it is empty if there is no leader election
+	Leader string
+
 	Simulations []*simulate.Metadata
 }
 
-func BuildSimulateListPage(simulations []*simulate.Metadata) ([]byte, error) {
+func BuildSimulateListPage(simulations []*simulate.Metadata, leader string) ([]byte, error) {
 	tmpl, err := template.New("simulatelist").Parse(simulateListTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing simulatelist template: %v", err)
 	}
 
 	data := &simulateListData{
+		Leader: leader,
 		Simulations: simulations,
 	}
 